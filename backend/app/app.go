@@ -16,6 +16,7 @@ type App struct {
 	version        string
 	windowCtrl     *window.Controller
 	fileSaver      *file.Saver
+	jsonFormatter  *file.JsonFileFormatter
 	updater        *updater.Updater
 }
 
@@ -29,6 +30,7 @@ func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 	a.windowCtrl = window.NewController(ctx)
 	a.fileSaver = file.NewSaver(ctx)
+	a.jsonFormatter = file.NewJsonFileFormatter(ctx)
 }
 
 // SetVersion 设置应用版本
@@ -101,6 +103,18 @@ func (a *App) SaveFile(content string, options file.SaveOptions, isBase64 bool)
 	return a.fileSaver.Save(content, options, isBase64)
 }
 
+// ==================== 大文件格式化 ====================
+
+// FormatJsonFile 流式格式化或压缩 JSON 文件，进度通过 json-file:progress 事件上报
+func (a *App) FormatJsonFile(options file.JsonFileOptions) (*file.JsonFileResult, error) {
+	return a.jsonFormatter.Format(options)
+}
+
+// CancelJsonFileFormat 取消正在进行的大文件格式化
+func (a *App) CancelJsonFileFormat() {
+	a.jsonFormatter.Cancel()
+}
+
 // ==================== 更新检查 ====================
 
 // CheckForUpdate 检查更新
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-tools/backend/processor"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// JsonFileProgressEvent 大文件格式化进度事件名
+const JsonFileProgressEvent = "json-file:progress"
+
+// JsonFileOptions 大文件格式化选项
+type JsonFileOptions struct {
+	InputPath  string                      `json:"inputPath"`  // 输入文件路径，为空时弹出选择文件对话框
+	OutputPath string                      `json:"outputPath"` // 输出文件路径，为空时弹出保存文件对话框
+	Format     processor.JsonStreamOptions `json:"format"`     // 缩进与压缩设置
+}
+
+// JsonFileProgress 格式化进度
+type JsonFileProgress struct {
+	Read    int64   `json:"read"`    // 已读取的字节数
+	Total   int64   `json:"total"`   // 输入文件大小
+	Percent float64 `json:"percent"` // 完成百分比，0-100
+}
+
+// JsonFileResult 格式化结果
+type JsonFileResult struct {
+	InputPath  string `json:"inputPath"`
+	OutputPath string `json:"outputPath"`
+	InputSize  int64  `json:"inputSize"`  // 输入文件字节数
+	OutputSize int64  `json:"outputSize"` // 输出文件字节数
+	Duration   int64  `json:"duration"`   // 耗时，毫秒
+}
+
+// JsonFileFormatter 大文件格式化器，逐个标记读写，不将文件整体读入内存
+type JsonFileFormatter struct {
+	ctx    context.Context
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewJsonFileFormatter 创建大文件格式化器
+func NewJsonFileFormatter(ctx context.Context) *JsonFileFormatter {
+	return &JsonFileFormatter{ctx: ctx}
+}
+
+var jsonFilters = []runtime.FileFilter{
+	{DisplayName: "JSON 文件 (*.json)", Pattern: "*.json"},
+	{DisplayName: "所有文件 (*.*)", Pattern: "*.*"},
+}
+
+// Format 格式化或压缩 JSON 文件并写入输出文件，处理过程中通过事件上报进度
+func (f *JsonFileFormatter) Format(options JsonFileOptions) (*JsonFileResult, error) {
+	inputPath := options.InputPath
+	if inputPath == "" {
+		path, err := runtime.OpenFileDialog(f.ctx, runtime.OpenDialogOptions{
+			Title:   "选择 JSON 文件",
+			Filters: jsonFilters,
+		})
+		if err != nil || path == "" {
+			return nil, fmt.Errorf("用户取消选择")
+		}
+		inputPath = path
+	}
+
+	outputPath := options.OutputPath
+	if outputPath == "" {
+		ext := filepath.Ext(inputPath)
+		suffix := ".formatted"
+		if options.Format.Compress {
+			suffix = ".min"
+		}
+		path, err := runtime.SaveFileDialog(f.ctx, runtime.SaveDialogOptions{
+			Title:            "保存格式化结果",
+			DefaultDirectory: filepath.Dir(inputPath),
+			DefaultFilename:  strings.TrimSuffix(filepath.Base(inputPath), ext) + suffix + ext,
+			Filters:          jsonFilters,
+		})
+		if err != nil || path == "" {
+			return nil, fmt.Errorf("用户取消保存")
+		}
+		outputPath = path
+	}
+
+	ctx, err := f.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer f.end()
+
+	start := time.Now()
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer input.Close()
+	info, err := input.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+
+	// 先写入同目录下的临时文件，成功后再替换输出文件，失败或取消时不留下不完整的结果
+	output, err := os.CreateTemp(filepath.Dir(outputPath), ".json-format-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建输出文件失败: %v", err)
+	}
+	tempPath := output.Name()
+	defer os.Remove(tempPath)
+
+	total := info.Size()
+	var lastEmit time.Time
+	progress := func(read int64) {
+		// 限制事件频率，避免大量事件阻塞前端
+		if read < total && time.Since(lastEmit) < 100*time.Millisecond {
+			return
+		}
+		lastEmit = time.Now()
+		runtime.EventsEmit(f.ctx, JsonFileProgressEvent, newJsonFileProgress(read, total))
+	}
+	progress(0)
+
+	err = processor.StreamJson(ctx, input, output, options.Format, progress)
+	if closeErr := output.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("写入失败: %v", closeErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	outInfo, err := os.Stat(tempPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return nil, fmt.Errorf("写入文件失败: %v", err)
+	}
+	input.Close()
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		return nil, fmt.Errorf("写入文件失败: %v", err)
+	}
+
+	return &JsonFileResult{
+		InputPath:  inputPath,
+		OutputPath: outputPath,
+		InputSize:  total,
+		OutputSize: outInfo.Size(),
+		Duration:   time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// Cancel 取消正在进行的格式化
+func (f *JsonFileFormatter) Cancel() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
+
+// begin 标记开始处理，同一时间只允许处理一个文件
+func (f *JsonFileFormatter) begin() (context.Context, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cancel != nil {
+		return nil, fmt.Errorf("已有文件正在处理中")
+	}
+	ctx, cancel := context.WithCancel(f.ctx)
+	f.cancel = cancel
+	return ctx, nil
+}
+
+func (f *JsonFileFormatter) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancel()
+	f.cancel = nil
+}
+
+func newJsonFileProgress(read, total int64) JsonFileProgress {
+	p := JsonFileProgress{Read: read, Total: total, Percent: 100}
+	if total > 0 {
+		p.Percent = float64(read) * 100 / float64(total)
+	}
+	return p
+}
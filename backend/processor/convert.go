@@ -0,0 +1,829 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// 支持互相转换的格式
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// ConvertOptions 格式转换选项
+//
+// 注释只能来自 YAML 源文件：输出 YAML 时全部保留，输出 TOML 时保留键和表上的注释，行内数组与行内表中的注释会丢失；
+// TOML 解析器不提供注释信息，TOML 源文件中的注释在任何目标格式下都会丢失，TOML 转 TOML 也不例外
+type ConvertOptions struct {
+	From          string `json:"from"`          // 源格式：json、yaml、toml
+	To            string `json:"to"`            // 目标格式：json、yaml、toml
+	IndentSize    int    `json:"indentSize"`    // JSON 与 YAML 的缩进空格数，默认 2
+	MultiDocument bool   `json:"multiDocument"` // JSON 转 YAML 时将顶层数组的每个元素输出为一个 YAML 文档
+	ExpandAliases bool   `json:"expandAliases"` // 展开锚点、别名与合并键，只影响 YAML 转 YAML；JSON 和 TOML 没有别名，YAML 转为 JSON、TOML 时总会展开
+}
+
+// ConvertProcessor JSON、YAML、TOML 互相转换的处理器
+//
+// 三种格式都先转换为 yaml.Node 再输出，转换过程中保持键的顺序；
+// YAML 中的注释在输出 YAML 和 TOML 时保留，TOML 解析器不提供注释信息，因此 TOML 源文件中的注释会丢失
+type ConvertProcessor struct{}
+
+// NewConvertProcessor 创建格式转换处理器
+func NewConvertProcessor() *ConvertProcessor {
+	return &ConvertProcessor{}
+}
+
+// Convert 在 JSON、YAML、TOML 之间转换
+// 多文档 YAML 转为 JSON 时输出为数组，转为 TOML 时只支持单个文档
+func (c *ConvertProcessor) Convert(input string, options ConvertOptions) (string, error) {
+	if options.IndentSize <= 0 {
+		options.IndentSize = 2
+	}
+
+	var docs []*yaml.Node
+	var err error
+	switch options.From {
+	case FormatJSON:
+		docs, err = jsonToYAMLNodes(input, options.MultiDocument && options.To == FormatYAML)
+	case FormatYAML:
+		docs, err = parseYAMLDocuments(input)
+	case FormatTOML:
+		docs, err = tomlToYAMLNodes(input)
+	default:
+		return "", fmt.Errorf("不支持的源格式: %s", options.From)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	switch options.To {
+	case FormatJSON:
+		return yamlNodesToJSON(docs, strings.Repeat(" ", options.IndentSize))
+	case FormatYAML:
+		if options.ExpandAliases {
+			for i, doc := range docs {
+				if docs[i], err = expandAliases(doc, 0); err != nil {
+					return "", err
+				}
+			}
+		}
+		return encodeYAML(docs, options.IndentSize)
+	case FormatTOML:
+		if len(docs) != 1 {
+			return "", fmt.Errorf("TOML 只能包含一个文档，输入中有 %d 个 YAML 文档", len(docs))
+		}
+		return yamlNodeToTOML(docs[0])
+	}
+	return "", fmt.Errorf("不支持的目标格式: %s", options.To)
+}
+
+// ==================== YAML ====================
+
+// parseYAMLDocuments 解析 YAML，多文档时返回每个文档
+func parseYAMLDocuments(input string) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(input))
+	var docs []*yaml.Node
+	for {
+		doc := &yaml.Node{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("YAML 格式错误: %v", err)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("YAML 内容为空")
+	}
+	return docs, nil
+}
+
+// encodeYAML 输出 YAML，多个文档之间使用 --- 分隔
+func encodeYAML(docs []*yaml.Node, indent int) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+	for _, doc := range docs {
+		clearMergeTags(doc)
+		if err := encoder.Encode(doc); err != nil {
+			return "", fmt.Errorf("生成 YAML 失败: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("生成 YAML 失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// clearMergeTags 去掉合并键上解析得到的 !!merge 标签，否则输出为 !!merge <<: *a
+func clearMergeTags(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content); i += 2 {
+			if isMergeKey(n.Content[i]) {
+				n.Content[i].Tag = ""
+			}
+		}
+	}
+	for _, child := range n.Content {
+		clearMergeTags(child)
+	}
+}
+
+// maxAliasDepth 展开别名时允许的最大嵌套层数，防止循环引用
+const maxAliasDepth = 100
+
+// expandAliases 返回展开别名与合并键后的节点副本
+func expandAliases(n *yaml.Node, depth int) (*yaml.Node, error) {
+	if depth > maxAliasDepth {
+		return nil, fmt.Errorf("YAML 别名嵌套过深，可能存在循环引用")
+	}
+	if n.Kind == yaml.AliasNode {
+		expanded, err := expandAliases(n.Alias, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		expanded.HeadComment, expanded.LineComment, expanded.FootComment = n.HeadComment, n.LineComment, n.FootComment
+		return expanded, nil
+	}
+
+	c := *n
+	c.Anchor = ""
+	c.Content = nil
+	if n.Kind == yaml.MappingNode {
+		pairs, err := mappingPairs(n, depth)
+		if err != nil {
+			return nil, err
+		}
+		for _, pair := range pairs {
+			key, err := expandAliases(pair[0], depth)
+			if err != nil {
+				return nil, err
+			}
+			value, err := expandAliases(pair[1], depth)
+			if err != nil {
+				return nil, err
+			}
+			c.Content = append(c.Content, key, value)
+		}
+		return &c, nil
+	}
+	for _, child := range n.Content {
+		expanded, err := expandAliases(child, depth)
+		if err != nil {
+			return nil, err
+		}
+		c.Content = append(c.Content, expanded)
+	}
+	return &c, nil
+}
+
+// isMergeKey 判断是否为合并键 <<
+func isMergeKey(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Value == "<<" && (n.Tag == "!!merge" || n.Tag == "")
+}
+
+// mappingPairs 返回映射中的键值对，合并键 << 引用的映射会被展开，已存在的键优先
+func mappingPairs(n *yaml.Node, depth int) ([][2]*yaml.Node, error) {
+	var pairs [][2]*yaml.Node
+	var merged [][2]*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if !isMergeKey(key) {
+			pairs = append(pairs, [2]*yaml.Node{key, value})
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if resolveAlias(value).Kind == yaml.SequenceNode {
+			sources = resolveAlias(value).Content
+		}
+		for _, source := range sources {
+			source = resolveAlias(source)
+			if source.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("第 %d 行: 合并键 << 只能引用映射", key.Line)
+			}
+			if depth > maxAliasDepth {
+				return nil, fmt.Errorf("YAML 别名嵌套过深，可能存在循环引用")
+			}
+			sub, err := mappingPairs(source, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, sub...)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, pair := range pairs {
+		seen[resolveAlias(pair[0]).Value] = true
+	}
+	for _, pair := range merged {
+		if name := resolveAlias(pair[0]).Value; !seen[name] {
+			seen[name] = true
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, nil
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for depth := 0; n.Kind == yaml.AliasNode && depth < maxAliasDepth; depth++ {
+		n = n.Alias
+	}
+	return n
+}
+
+// ==================== JSON ====================
+
+// jsonToYAMLNodes 将 JSON 转换为 YAML 文档节点，splitArray 为 true 时顶层数组的每个元素成为一个文档
+func jsonToYAMLNodes(input string, splitArray bool) ([]*yaml.Node, error) {
+	root, err := parseJSON([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+	if splitArray && root.kind == jsonArray {
+		var docs []*yaml.Node
+		for _, item := range root.items {
+			docs = append(docs, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{jsonToYAMLNode(item)}})
+		}
+		return docs, nil
+	}
+	return []*yaml.Node{{Kind: yaml.DocumentNode, Content: []*yaml.Node{jsonToYAMLNode(root)}}}, nil
+}
+
+func jsonToYAMLNode(n *jsonNode) *yaml.Node {
+	switch n.kind {
+	case jsonObject:
+		m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, f := range n.fields {
+			m.Content = append(m.Content, yamlString(f.name()), jsonToYAMLNode(f.value))
+		}
+		return m
+	case jsonArray:
+		s := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range n.items {
+			s.Content = append(s.Content, jsonToYAMLNode(item))
+		}
+		return s
+	case jsonString:
+		return yamlString(n.value)
+	case jsonNull:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case jsonBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: n.raw}
+	}
+	// 数字保留原始写法，由编码器自行判断类型，超出 int64 的整数也不会被加上类型标签
+	// 超出 float64 范围的数字（如 1e400）会被 YAML 解析为字符串，需要显式标注为浮点数
+	if _, err := strconv.ParseFloat(n.raw, 64); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: n.raw}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: n.raw}
+}
+
+func yamlString(s string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if strings.Contains(strings.TrimRight(s, "\n"), "\n") {
+		n.Style = yaml.LiteralStyle
+	}
+	return n
+}
+
+// yamlNodesToJSON 将 YAML 文档转换为 JSON，多个文档输出为数组
+func yamlNodesToJSON(docs []*yaml.Node, indent string) (string, error) {
+	var root *jsonNode
+	if len(docs) == 1 {
+		n, err := yamlToJSONNode(docs[0], 0)
+		if err != nil {
+			return "", err
+		}
+		root = n
+	} else {
+		root = &jsonNode{kind: jsonArray}
+		for _, doc := range docs {
+			n, err := yamlToJSONNode(doc, 0)
+			if err != nil {
+				return "", err
+			}
+			root.items = append(root.items, n)
+		}
+	}
+	return encodeJSON(root, indent), nil
+}
+
+func yamlToJSONNode(n *yaml.Node, depth int) (*jsonNode, error) {
+	if depth > maxAliasDepth {
+		return nil, fmt.Errorf("YAML 别名嵌套过深，可能存在循环引用")
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return &jsonNode{kind: jsonNull, raw: "null"}, nil
+		}
+		return yamlToJSONNode(n.Content[0], depth)
+	case yaml.AliasNode:
+		return yamlToJSONNode(n.Alias, depth+1)
+	case yaml.MappingNode:
+		pairs, err := mappingPairs(n, depth)
+		if err != nil {
+			return nil, err
+		}
+		obj := &jsonNode{kind: jsonObject}
+		for _, pair := range pairs {
+			key := resolveAlias(pair[0])
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("第 %d 行: JSON 的键只能是字符串，不支持复杂键", key.Line)
+			}
+			value, err := yamlToJSONNode(pair[1], depth)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key.Value, value)
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		arr := &jsonNode{kind: jsonArray}
+		for _, item := range n.Content {
+			value, err := yamlToJSONNode(item, depth)
+			if err != nil {
+				return nil, err
+			}
+			arr.items = append(arr.items, value)
+		}
+		return arr, nil
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		return &jsonNode{kind: jsonNull, raw: "null"}, nil
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %v", n.Line, err)
+		}
+		return &jsonNode{kind: jsonBool, raw: strconv.FormatBool(b)}, nil
+	case "!!int", "!!float":
+		raw, err := yamlNumberToJSON(n)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{kind: jsonNumber, raw: raw}, nil
+	}
+	return newJSONString(n.Value), nil
+}
+
+// yamlNumberToJSON 将 YAML 数字转换为 JSON 数字写法，如 0x1F → 31、.5 → 0.5
+func yamlNumberToJSON(n *yaml.Node) (string, error) {
+	if isJSONNumber(n.Value) {
+		return n.Value, nil
+	}
+	value := strings.ReplaceAll(n.Value, "_", "")
+	if n.ShortTag() == "!!int" {
+		if i, ok := new(big.Int).SetString(strings.TrimPrefix(value, "+"), 0); ok {
+			return i.String(), nil
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimPrefix(value, "+"), 64)
+	if err != nil {
+		var parsed float64
+		if n.Decode(&parsed) != nil {
+			return "", fmt.Errorf("第 %d 行: 无法识别的数字 %s", n.Line, n.Value)
+		}
+		f = parsed
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("第 %d 行: JSON 不支持 %s", n.Line, n.Value)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
+
+// ==================== TOML ====================
+
+// tomlToYAMLNodes 解析 TOML，并按照键在文档中出现的顺序转换为 YAML 节点
+func tomlToYAMLNodes(input string) ([]*yaml.Node, error) {
+	var data map[string]interface{}
+	md, err := toml.Decode(input, &data)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			reason := tomlErrorPrefix.ReplaceAllString(parseErr.Error(), "")
+			return nil, newSyntaxError([]byte(input), parseErr.Position.Start, "", "TOML 格式错误: "+reason)
+		}
+		return nil, fmt.Errorf("TOML 格式错误: %v", err)
+	}
+
+	// 记录每个表中键的出现顺序
+	order := map[string][]string{}
+	seen := map[string]bool{}
+	for _, key := range md.Keys() {
+		full := strings.Join(key, "\x00")
+		if seen[full] {
+			continue
+		}
+		seen[full] = true
+		parent := strings.Join(key[:len(key)-1], "\x00")
+		order[parent] = append(order[parent], key[len(key)-1])
+	}
+
+	c := &tomlConverter{order: order, floats: tomlFloatLiterals(input)}
+	root, err := c.node(data, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return []*yaml.Node{{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}}, nil
+}
+
+// tomlConverter 将 toml.Decode 的结果转换为 YAML 节点
+type tomlConverter struct {
+	order  map[string][]string // 表路径到键的出现顺序
+	floats map[string]string   // 值路径到浮点数的原始写法，见 tomlFloatLiterals
+}
+
+// node 转换一个值，path 为所在表的路径，用于查找键的顺序；valuePath 为包含数组下标的值路径，用于查找浮点数的原始写法
+func (c *tomlConverter) node(v interface{}, path string, valuePath string) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		keys := orderedKeys(v, c.order[path])
+		for _, key := range keys {
+			value, err := c.node(v[key], joinTOMLPath(path, key), joinTOMLPath(valuePath, key))
+			if err != nil {
+				return nil, err
+			}
+			m.Content = append(m.Content, yamlString(key), value)
+		}
+		return m, nil
+	case []map[string]interface{}:
+		s := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, item := range v {
+			value, err := c.node(item, path, joinTOMLPath(valuePath, "["+strconv.Itoa(i)+"]"))
+			if err != nil {
+				return nil, err
+			}
+			s.Content = append(s.Content, value)
+		}
+		return s, nil
+	case []interface{}:
+		s := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, item := range v {
+			value, err := c.node(item, path, joinTOMLPath(valuePath, "["+strconv.Itoa(i)+"]"))
+			if err != nil {
+				return nil, err
+			}
+			s.Content = append(s.Content, value)
+		}
+		return s, nil
+	case string:
+		return yamlString(v), nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	case int64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(v, 10)}, nil
+	case float64:
+		value := formatYAMLFloat(v)
+		// 保留源文本中的写法，如 1.50、3.14159265358979323846，去掉 YAML 不支持的下划线和正号
+		if raw, ok := c.floats[valuePath]; ok && !math.IsInf(v, 0) {
+			value = strings.TrimPrefix(strings.ReplaceAll(raw, "_", ""), "+")
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value}, nil
+	case time.Time:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: formatTOMLTime(v)}, nil
+	}
+	return nil, fmt.Errorf("不支持的 TOML 值类型 %T", v)
+}
+
+// orderedKeys 按文档中出现的顺序返回表中的键，未记录顺序的键（如内联表中的键）按字母顺序追加在后面
+func orderedKeys(m map[string]interface{}, order []string) []string {
+	keys := make([]string, 0, len(m))
+	used := map[string]bool{}
+	for _, key := range order {
+		if _, ok := m[key]; ok && !used[key] {
+			used[key] = true
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range m {
+		if !used[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+func formatYAMLFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	case math.IsNaN(f):
+		return ".nan"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// formatTOMLTime 按照 TOML 中的原始类型（本地日期、本地时间等）格式化时间
+func formatTOMLTime(t time.Time) string {
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+var (
+	// tomlErrorPrefix TOML 解析错误信息中的位置前缀，位置改由 SyntaxError 给出
+	tomlErrorPrefix = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+	// tomlBareKeyPattern 不需要引号的 TOML 键
+	tomlBareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	// tomlFloatPattern 合法的 TOML 浮点数写法
+	tomlFloatPattern = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	// tomlDatetimePattern 可以不加引号输出的 TOML 日期时间
+	tomlDatetimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?$|^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+)
+
+// tomlWriter TOML 输出
+type tomlWriter struct {
+	b strings.Builder
+}
+
+// yamlNodeToTOML 将 YAML 文档输出为 TOML，YAML 中的注释会一并输出
+func yamlNodeToTOML(doc *yaml.Node) (string, error) {
+	root := resolveAlias(doc)
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return "", nil
+		}
+		root = resolveAlias(root.Content[0])
+	}
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("TOML 的顶层必须是表（键值对），不能是数组或单个值")
+	}
+	w := &tomlWriter{}
+	w.comment(doc.HeadComment)
+	if err := w.table(root, nil, 0); err != nil {
+		return "", err
+	}
+	w.comment(doc.FootComment)
+	return strings.TrimLeft(w.b.String(), "\n"), nil
+}
+
+// comment 输出 YAML 注释，注释文本本身带有 # 前缀
+func (w *tomlWriter) comment(text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.b.WriteString(line + "\n")
+		}
+	}
+}
+
+// lineComment 返回行尾注释
+func lineComment(nodes ...*yaml.Node) string {
+	for _, n := range nodes {
+		if n.LineComment != "" {
+			return " " + n.LineComment
+		}
+	}
+	return ""
+}
+
+// tableKind 判断值在 TOML 中的输出方式：子表、表数组或普通键值
+func tableKind(n *yaml.Node) string {
+	n = resolveAlias(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		if len(n.Content) > 0 {
+			return "table"
+		}
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			return "value"
+		}
+		for _, item := range n.Content {
+			if resolveAlias(item).Kind != yaml.MappingNode {
+				return "value"
+			}
+		}
+		return "array"
+	}
+	return "value"
+}
+
+// table 输出一个表：先输出普通键值，再输出子表和表数组
+func (w *tomlWriter) table(n *yaml.Node, path []string, depth int) error {
+	pairs, err := mappingPairs(n, depth)
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		key, value := resolveAlias(pair[0]), pair[1]
+		if tableKind(value) != "value" {
+			continue
+		}
+		if resolveAlias(value).ShortTag() == "!!null" {
+			return fmt.Errorf("TOML 不支持 null 值（键 %s）", strings.Join(append(path, key.Value), "."))
+		}
+		text, err := w.inline(value, append(path, key.Value), depth)
+		if err != nil {
+			return err
+		}
+		w.comment(pair[0].HeadComment)
+		w.b.WriteString(tomlKey(key.Value) + " = " + text + lineComment(pair[0], value) + "\n")
+	}
+
+	for _, pair := range pairs {
+		key, value := resolveAlias(pair[0]), pair[1]
+		child := append(append([]string(nil), path...), key.Value)
+		switch tableKind(value) {
+		case "table":
+			w.b.WriteString("\n")
+			w.comment(pair[0].HeadComment)
+			w.b.WriteString("[" + tomlPath(child) + "]" + lineComment(pair[0]) + "\n")
+			if err := w.table(resolveAlias(value), child, depth+1); err != nil {
+				return err
+			}
+		case "array":
+			w.b.WriteString("\n")
+			w.comment(pair[0].HeadComment)
+			for i, item := range resolveAlias(value).Content {
+				if i > 0 {
+					w.b.WriteString("\n")
+				}
+				w.comment(item.HeadComment)
+				w.b.WriteString("[[" + tomlPath(child) + "]]\n")
+				if err := w.table(resolveAlias(item), child, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inline 返回值的行内写法
+func (w *tomlWriter) inline(n *yaml.Node, path []string, depth int) (string, error) {
+	if depth > maxAliasDepth {
+		return "", fmt.Errorf("YAML 别名嵌套过深，可能存在循环引用")
+	}
+	n = resolveAlias(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		pairs, err := mappingPairs(n, depth)
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, pair := range pairs {
+			key := resolveAlias(pair[0]).Value
+			text, err := w.inline(pair[1], append(path, key), depth+1)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(key)+" = "+text)
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	case yaml.SequenceNode:
+		var parts []string
+		for i, item := range n.Content {
+			text, err := w.inline(item, append(path, strconv.Itoa(i)), depth+1)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, text)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		return "", fmt.Errorf("TOML 不支持 null 值（%s）", strings.Join(path, "."))
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return "", fmt.Errorf("第 %d 行: %v", n.Line, err)
+		}
+		return strconv.FormatBool(b), nil
+	case "!!int":
+		value := strings.TrimPrefix(strings.ReplaceAll(n.Value, "_", ""), "+")
+		i, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return "", fmt.Errorf("第 %d 行: 无法识别的整数 %s", n.Line, n.Value)
+		}
+		if !i.IsInt64() {
+			return "", fmt.Errorf("整数 %s 超出 TOML 支持的范围（%s）", n.Value, strings.Join(path, "."))
+		}
+		return i.String(), nil
+	case "!!float":
+		return tomlFloat(n)
+	case "!!timestamp":
+		if tomlDatetimePattern.MatchString(n.Value) {
+			return n.Value, nil
+		}
+	}
+	return tomlString(n.Value), nil
+}
+
+// tomlFloat 将 YAML 浮点数转换为 TOML 写法
+func tomlFloat(n *yaml.Node) (string, error) {
+	switch strings.ToLower(n.Value) {
+	case ".inf", "+.inf":
+		return "inf", nil
+	case "-.inf":
+		return "-inf", nil
+	case ".nan":
+		return "nan", nil
+	}
+	if tomlFloatPattern.MatchString(n.Value) {
+		if !strings.ContainsAny(n.Value, ".eE") {
+			return n.Value + ".0", nil
+		}
+		return n.Value, nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(n.Value, "_", ""), 64)
+	if err != nil {
+		return "", fmt.Errorf("第 %d 行: 无法识别的数字 %s", n.Line, n.Value)
+	}
+	return formatYAMLFloat(f), nil
+}
+
+// tomlKey 返回键的 TOML 写法，必要时加引号
+func tomlKey(key string) string {
+	if tomlBareKeyPattern.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlPath(path []string) string {
+	parts := make([]string, len(path))
+	for i, key := range path {
+		parts[i] = tomlKey(key)
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString 返回 TOML 基本字符串，包含换行时使用多行字符串
+func tomlString(s string) string {
+	multiline := strings.Contains(s, "\n")
+	var b strings.Builder
+	if multiline {
+		b.WriteString("\"\"\"\n")
+	} else {
+		b.WriteByte('"')
+	}
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' && multiline:
+			b.WriteByte('\n')
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if multiline {
+		b.WriteString("\"\"\"")
+	} else {
+		b.WriteByte('"')
+	}
+	return b.String()
+}
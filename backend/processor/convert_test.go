@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+const convertCommentedYAML = `# head
+name: app # inline
+# before server
+server:
+    # port comment
+    port: 8080 # the port
+    hosts:
+        - a
+        - b
+`
+
+const convertAliasYAML = `base: &b
+    x: 1
+    y: 2
+item:
+    <<: *b
+    y: 3
+ref: *b
+`
+
+func convert(t *testing.T, input, from, to string, expand bool) string {
+	t.Helper()
+	out, err := NewConvertProcessor().Convert(input, ConvertOptions{From: from, To: to, IndentSize: 4, ExpandAliases: expand})
+	if err != nil {
+		t.Fatalf("%s -> %s: %v", from, to, err)
+	}
+	return out
+}
+
+func TestConvertCommentsRoundTrip(t *testing.T) {
+	// YAML 转 YAML 保留全部注释
+	if got := convert(t, convertCommentedYAML, FormatYAML, FormatYAML, false); got != convertCommentedYAML {
+		t.Errorf("YAML round trip changed the document:\n%s", got)
+	}
+
+	// YAML 转 TOML 保留键和表上的注释
+	toml := convert(t, convertCommentedYAML, FormatYAML, FormatTOML, false)
+	want := `# head
+name = "app" # inline
+
+# before server
+[server]
+# port comment
+port = 8080 # the port
+hosts = ["a", "b"]
+`
+	if toml != want {
+		t.Errorf("YAML -> TOML:\ngot:\n%s\nwant:\n%s", toml, want)
+	}
+
+	// TOML 源文件中的注释在任何目标格式下都会丢失，数据保持不变
+	for _, to := range []string{FormatYAML, FormatTOML, FormatJSON} {
+		if got := convert(t, toml, FormatTOML, to, false); strings.Contains(got, "#") {
+			t.Errorf("TOML -> %s kept comments:\n%s", to, got)
+		}
+	}
+	back := convert(t, toml, FormatTOML, FormatYAML, false)
+	if got, want := convert(t, back, FormatYAML, FormatJSON, false), convert(t, convertCommentedYAML, FormatYAML, FormatJSON, false); got != want {
+		t.Errorf("YAML -> TOML -> YAML changed the data:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConvertAliasesRoundTrip(t *testing.T) {
+	// 不展开时锚点、别名和合并键原样保留
+	if got := convert(t, convertAliasYAML, FormatYAML, FormatYAML, false); got != convertAliasYAML {
+		t.Errorf("YAML round trip changed the aliases:\n%s", got)
+	}
+
+	expanded := convert(t, convertAliasYAML, FormatYAML, FormatYAML, true)
+	want := `base:
+    x: 1
+    y: 2
+item:
+    y: 3
+    x: 1
+ref:
+    x: 1
+    y: 2
+`
+	if expanded != want {
+		t.Errorf("expanded aliases:\ngot:\n%s\nwant:\n%s", expanded, want)
+	}
+
+	// 展开前后以及转为 JSON、TOML 再转回后的数据相同
+	data := convert(t, convertAliasYAML, FormatYAML, FormatJSON, false)
+	for _, tt := range []struct {
+		name  string
+		input string
+		from  string
+	}{
+		{"expanded YAML", expanded, FormatYAML},
+		{"JSON", data, FormatJSON},
+		{"TOML", convert(t, convertAliasYAML, FormatYAML, FormatTOML, false), FormatTOML},
+	} {
+		if got := convert(t, tt.input, tt.from, FormatJSON, false); got != data {
+			t.Errorf("%s changed the data:\ngot:\n%s\nwant:\n%s", tt.name, got, data)
+		}
+	}
+
+	// JSON 和 TOML 没有别名，ExpandAliases 不影响结果
+	for _, from := range []string{FormatJSON, FormatTOML} {
+		input := data
+		if from == FormatTOML {
+			input = convert(t, data, FormatJSON, FormatTOML, false)
+		}
+		if convert(t, input, from, FormatYAML, true) != convert(t, input, from, FormatYAML, false) {
+			t.Errorf("ExpandAliases changed %s -> YAML", from)
+		}
+	}
+}
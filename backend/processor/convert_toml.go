@@ -0,0 +1,239 @@
+package processor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// tomlFloatLiterals 扫描 TOML 源文本，返回每个浮点数的原始写法，键为值的路径
+// 路径由键和数组下标（如 [0]）组成，以 \x00 分隔；TOML 解析器只提供 float64，原始写法用于保留精度和小数位数
+// 只在 toml.Decode 成功后调用，遇到无法识别的内容时停止扫描，未记录的浮点数按 float64 输出
+func tomlFloatLiterals(input string) map[string]string {
+	s := &tomlScanner{src: input, floats: map[string]string{}, arrayTables: map[string]int{}}
+	s.document()
+	return s.floats
+}
+
+type tomlScanner struct {
+	src         string
+	pos         int
+	floats      map[string]string
+	arrayTables map[string]int // 表数组的路径（不含下标）到已出现的元素个数
+}
+
+func joinTOMLPath(prefix, segment string) string {
+	if prefix == "" {
+		return segment
+	}
+	return prefix + "\x00" + segment
+}
+
+func (s *tomlScanner) peek() byte {
+	if s.pos < len(s.src) {
+		return s.src[s.pos]
+	}
+	return 0
+}
+
+// skip 跳过空白和注释，newlines 为 true 时同时跳过换行
+func (s *tomlScanner) skip(newlines bool) {
+	for s.pos < len(s.src) {
+		switch c := s.src[s.pos]; {
+		case c == ' ' || c == '\t' || (newlines && (c == '\n' || c == '\r')):
+			s.pos++
+		case c == '#':
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *tomlScanner) document() {
+	table := ""
+	for {
+		s.skip(true)
+		if s.pos >= len(s.src) {
+			return
+		}
+		if s.peek() != '[' {
+			if !s.keyValue(table) {
+				return
+			}
+			continue
+		}
+		isArray := strings.HasPrefix(s.src[s.pos:], "[[")
+		if isArray {
+			s.pos += 2
+		} else {
+			s.pos++
+		}
+		keys, ok := s.key()
+		if !ok {
+			return
+		}
+		// 表头中的前缀如果是表数组，指向其最后一个元素
+		table = ""
+		plain := ""
+		for i, key := range keys {
+			table = joinTOMLPath(table, key)
+			plain = joinTOMLPath(plain, key)
+			if n := s.arrayTables[plain]; n > 0 && (i < len(keys)-1 || !isArray) {
+				table = joinTOMLPath(table, "["+strconv.Itoa(n-1)+"]")
+			}
+		}
+		if isArray {
+			table = joinTOMLPath(table, "["+strconv.Itoa(s.arrayTables[plain])+"]")
+			s.arrayTables[plain]++
+		}
+		s.skip(false)
+		if isArray {
+			s.pos += 2
+		} else {
+			s.pos++
+		}
+	}
+}
+
+// key 读取可能带点的键
+func (s *tomlScanner) key() ([]string, bool) {
+	var keys []string
+	for {
+		s.skip(false)
+		var key string
+		switch c := s.peek(); {
+		case c == '"':
+			start := s.pos
+			s.pos = quotedEnd(s.src, s.pos)
+			unquoted, err := strconv.Unquote(s.src[start:s.pos])
+			if err != nil {
+				return nil, false
+			}
+			key = unquoted
+		case c == '\'':
+			end := strings.IndexByte(s.src[s.pos+1:], '\'')
+			if end < 0 {
+				return nil, false
+			}
+			key = s.src[s.pos+1 : s.pos+1+end]
+			s.pos += end + 2
+		default:
+			start := s.pos
+			for s.pos < len(s.src) && isTOMLBareKeyChar(s.src[s.pos]) {
+				s.pos++
+			}
+			if s.pos == start {
+				return nil, false
+			}
+			key = s.src[start:s.pos]
+		}
+		keys = append(keys, key)
+		s.skip(false)
+		if s.peek() != '.' {
+			return keys, true
+		}
+		s.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// keyValue 读取 key = value
+func (s *tomlScanner) keyValue(table string) bool {
+	keys, ok := s.key()
+	if !ok || s.peek() != '=' {
+		return false
+	}
+	s.pos++
+	path := table
+	for _, key := range keys {
+		path = joinTOMLPath(path, key)
+	}
+	s.skip(false)
+	return s.value(path)
+}
+
+// value 读取一个值，记录其中浮点数的原始写法
+func (s *tomlScanner) value(path string) bool {
+	switch c := s.peek(); {
+	case c == '"' || c == '\'':
+		quote := s.src[s.pos : s.pos+1]
+		if strings.HasPrefix(s.src[s.pos:], strings.Repeat(quote, 3)) {
+			return s.multilineString(quote)
+		}
+		if c == '"' {
+			s.pos = quotedEnd(s.src, s.pos)
+		} else {
+			end := strings.IndexByte(s.src[s.pos+1:], '\'')
+			if end < 0 {
+				return false
+			}
+			s.pos += end + 2
+		}
+		return true
+	case c == '[':
+		s.pos++
+		for i := 0; ; i++ {
+			s.skip(true)
+			if s.peek() == ']' {
+				s.pos++
+				return true
+			}
+			if !s.value(joinTOMLPath(path, "["+strconv.Itoa(i)+"]")) {
+				return false
+			}
+			s.skip(true)
+			if s.peek() == ',' {
+				s.pos++
+			}
+		}
+	case c == '{':
+		s.pos++
+		for {
+			s.skip(false)
+			if s.peek() == '}' {
+				s.pos++
+				return true
+			}
+			if !s.keyValue(path) {
+				return false
+			}
+			s.skip(false)
+			if s.peek() == ',' {
+				s.pos++
+			}
+		}
+	}
+	start := s.pos
+	for s.pos < len(s.src) && !strings.ContainsRune(",]}#\r\n", rune(s.src[s.pos])) {
+		s.pos++
+	}
+	if raw := strings.TrimSpace(s.src[start:s.pos]); tomlFloatPattern.MatchString(raw) {
+		s.floats[path] = raw
+	}
+	return s.pos > start
+}
+
+// multilineString 跳过由三个引号包围的多行字符串，结束处允许多出一到两个引号
+func (s *tomlScanner) multilineString(quote string) bool {
+	delimiter := strings.Repeat(quote, 3)
+	for i := s.pos + 3; i < len(s.src); i++ {
+		if quote == `"` && s.src[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s.src[i:], delimiter) {
+			end := i + 3
+			for end < len(s.src) && end < i+5 && s.src[end] == quote[0] {
+				end++
+			}
+			s.pos = end
+			return true
+		}
+	}
+	return false
+}
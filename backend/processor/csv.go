@@ -0,0 +1,404 @@
+package processor
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 数组在表格中的展开方式
+const (
+	CSVArrayJoin  = "join"  // 标量数组用分隔符连接为一个单元格，包含对象的数组输出为 JSON 文本
+	CSVArrayJSON  = "json"  // 数组输出为 JSON 文本
+	CSVArrayIndex = "index" // 每个元素单独成列，列名带下标，如 tags.0、tags.1
+	CSVArrayRows  = "rows"  // 每个元素单独成行，其余列的值重复
+)
+
+// CSVOptions JSON 与 CSV/TSV 互相转换的选项
+type CSVOptions struct {
+	Delimiter      string `json:"delimiter"`      // 字段分隔符，默认逗号，tab 或 \t 表示 TSV
+	PathSeparator  string `json:"pathSeparator"`  // 嵌套对象展开后列名中的路径分隔符，默认 .
+	ArrayMode      string `json:"arrayMode"`      // 数组展开方式：join（默认）、json、index、rows
+	ArraySeparator string `json:"arraySeparator"` // join 方式下连接数组元素的分隔符，默认 ;
+	WithBOM        bool   `json:"withBom"`        // 输出 UTF-8 BOM，便于 Excel 正确识别编码
+	NoHeader       bool   `json:"noHeader"`       // CSV 没有表头行，列名使用 column1、column2……
+	KeepStrings    bool   `json:"keepStrings"`    // CSV 转 JSON 时不识别数字和布尔值，全部保留为字符串
+	EmptyAsNull    bool   `json:"emptyAsNull"`    // CSV 转 JSON 时空单元格转换为 null
+	Unflatten      bool   `json:"unflatten"`      // CSV 转 JSON 时按路径分隔符将列名还原为嵌套对象
+}
+
+func (o CSVOptions) delimiter() (rune, error) {
+	switch o.Delimiter {
+	case "":
+		return ',', nil
+	case "tab", "\\t", "\t":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(o.Delimiter)
+	if size != len(o.Delimiter) || r == '"' || r == '\n' || r == '\r' {
+		return 0, fmt.Errorf("无效的分隔符 %q，分隔符必须是单个字符且不能是引号或换行", o.Delimiter)
+	}
+	return r, nil
+}
+
+func (o CSVOptions) pathSeparator() string {
+	if o.PathSeparator == "" {
+		return "."
+	}
+	return o.PathSeparator
+}
+
+// CSVProcessor JSON 与 CSV/TSV 表格互相转换的处理器
+type CSVProcessor struct{}
+
+// NewCSVProcessor 创建表格转换处理器
+func NewCSVProcessor() *CSVProcessor {
+	return &CSVProcessor{}
+}
+
+// csvCell 展开后的单元格
+type csvCell struct {
+	column string
+	value  string
+}
+
+// csvRow 展开后的一行，按列出现的顺序排列
+type csvRow []csvCell
+
+// JsonToCsv 将 JSON 对象数组转换为 CSV/TSV，嵌套对象按路径展开为多列，表头为所有行的列的并集
+func (c *CSVProcessor) JsonToCsv(jsonStr string, options CSVOptions) (string, error) {
+	comma, err := options.delimiter()
+	if err != nil {
+		return "", err
+	}
+	if options.ArrayMode == "" {
+		options.ArrayMode = CSVArrayJoin
+	}
+	switch options.ArrayMode {
+	case CSVArrayJoin, CSVArrayJSON, CSVArrayIndex, CSVArrayRows:
+	default:
+		return "", fmt.Errorf("不支持的数组展开方式: %s", options.ArrayMode)
+	}
+	if options.ArraySeparator == "" {
+		options.ArraySeparator = ";"
+	}
+
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return "", err
+	}
+	records := []*jsonNode{root}
+	if root.kind == jsonArray {
+		records = root.items
+	}
+
+	f := &csvFlattener{options: options}
+	var rows []csvRow
+	for i, record := range records {
+		if record.kind != jsonObject {
+			// 非对象元素放在 value 列中
+			record = &jsonNode{kind: jsonObject, fields: []*jsonField{{key: newJSONString("value"), value: record}}}
+		}
+		flattened := f.flatten(record, "")
+		// 嵌套路径展开后可能与本身含有路径分隔符的键同名，此时其中一个值会丢失
+		for _, row := range flattened {
+			seen := make(map[string]bool, len(row))
+			for _, cell := range row {
+				if seen[cell.column] {
+					return "", fmt.Errorf("第 %d 条记录展开后出现重复的列 %s：嵌套路径与含有路径分隔符 %q 的键冲突，请更换路径分隔符", i+1, cell.column, options.pathSeparator())
+				}
+				seen[cell.column] = true
+			}
+		}
+		rows = append(rows, flattened...)
+	}
+
+	// 表头取所有行中列的并集，按首次出现的顺序排列
+	var header []string
+	index := map[string]int{}
+	for _, row := range rows {
+		for _, cell := range row {
+			if _, ok := index[cell.column]; !ok {
+				index[cell.column] = len(header)
+				header = append(header, cell.column)
+			}
+		}
+	}
+
+	var b strings.Builder
+	if options.WithBOM {
+		b.WriteString("\uFEFF")
+	}
+	w := csv.NewWriter(&b)
+	w.Comma = comma
+	if err := w.Write(header); err != nil {
+		return "", fmt.Errorf("生成 CSV 失败: %v", err)
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for _, cell := range row {
+			record[index[cell.column]] = cell.value
+		}
+		if err := w.Write(record); err != nil {
+			return "", fmt.Errorf("生成 CSV 失败: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("生成 CSV 失败: %v", err)
+	}
+	return b.String(), nil
+}
+
+// csvFlattener 将嵌套的 JSON 展开为表格行
+type csvFlattener struct {
+	options CSVOptions
+}
+
+func (f *csvFlattener) join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + f.options.pathSeparator() + key
+}
+
+// flatten 展开一个值，rows 方式下数组会产生多行，因此返回多行
+func (f *csvFlattener) flatten(n *jsonNode, path string) []csvRow {
+	switch n.kind {
+	case jsonObject:
+		// 空对象不产生任何列
+		rows := []csvRow{nil}
+		for _, key := range n.keys() {
+			rows = crossRows(rows, f.flatten(n.field(key), f.join(path, key)))
+		}
+		return rows
+	case jsonArray:
+		return f.flattenArray(n, path)
+	case jsonString:
+		return []csvRow{{{column: path, value: n.value}}}
+	case jsonNull:
+		return []csvRow{{{column: path, value: ""}}}
+	}
+	return []csvRow{{{column: path, value: n.raw}}}
+}
+
+func (f *csvFlattener) flattenArray(n *jsonNode, path string) []csvRow {
+	switch f.options.ArrayMode {
+	case CSVArrayIndex:
+		rows := []csvRow{nil}
+		for i, item := range n.items {
+			rows = crossRows(rows, f.flatten(item, f.join(path, strconv.Itoa(i))))
+		}
+		return rows
+	case CSVArrayRows:
+		if len(n.items) == 0 {
+			// 空数组不产生列，也不会使所在的行消失
+			return []csvRow{nil}
+		}
+		var rows []csvRow
+		for _, item := range n.items {
+			rows = append(rows, f.flatten(item, path)...)
+		}
+		return rows
+	case CSVArrayJoin:
+		values := make([]string, 0, len(n.items))
+		for _, item := range n.items {
+			switch item.kind {
+			case jsonObject, jsonArray:
+				return []csvRow{{{column: path, value: encodeJSON(n, "")}}}
+			case jsonString:
+				values = append(values, item.value)
+			case jsonNull:
+				values = append(values, "")
+			default:
+				values = append(values, item.raw)
+			}
+		}
+		return []csvRow{{{column: path, value: strings.Join(values, f.options.ArraySeparator)}}}
+	}
+	return []csvRow{{{column: path, value: encodeJSON(n, "")}}}
+}
+
+// crossRows 计算两组行的笛卡尔积
+func crossRows(left, right []csvRow) []csvRow {
+	result := make([]csvRow, 0, len(left)*len(right))
+	for _, l := range left {
+		for _, r := range right {
+			row := make(csvRow, 0, len(l)+len(r))
+			result = append(result, append(append(row, l...), r...))
+		}
+	}
+	return result
+}
+
+// CsvToJson 将 CSV/TSV 转换为 JSON 对象数组，自动识别数字、布尔值和 null
+// 列名重复，或按路径还原时一列既是值又是另一列的上级对象时返回错误
+func (c *CSVProcessor) CsvToJson(csvStr string, options CSVOptions) (string, error) {
+	comma, err := options.delimiter()
+	if err != nil {
+		return "", err
+	}
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(csvStr, "\uFEFF")))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return "", fmt.Errorf("CSV 格式错误: 第 %d 行第 %d 列: %v", parseErr.Line, parseErr.Column, parseErr.Err)
+			}
+			return "", fmt.Errorf("CSV 格式错误: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	var header []string
+	if !options.NoHeader && len(records) > 0 {
+		header, records = records[0], records[1:]
+	}
+	width := 0
+	for _, record := range records {
+		width = max(width, len(record))
+	}
+	columns := make([]string, width)
+	for i := range columns {
+		columns[i] = "column" + strconv.Itoa(i+1)
+		if i < len(header) && header[i] != "" {
+			columns[i] = header[i]
+		}
+	}
+	if err := checkCSVColumns(columns, options); err != nil {
+		return "", err
+	}
+
+	result := &jsonNode{kind: jsonArray}
+	for _, record := range records {
+		obj := &jsonNode{kind: jsonObject}
+		for i, cell := range record {
+			value := csvValue(cell, options)
+			if options.Unflatten {
+				setPath(obj, strings.Split(columns[i], options.pathSeparator()), value)
+			} else {
+				obj.set(columns[i], value)
+			}
+		}
+		if options.Unflatten {
+			obj = restoreArrays(obj)
+		}
+		result.items = append(result.items, obj)
+	}
+	return encodeJSON(result, "  "), nil
+}
+
+// checkCSVColumns 检查列名是否会产生重复的键：列名相同，或还原嵌套对象时一列的路径是另一列路径的前缀
+func checkCSVColumns(columns []string, options CSVOptions) error {
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		if k, ok := index[column]; ok {
+			return fmt.Errorf("第 %d 列和第 %d 列的列名都是 %s，转换后会产生重复的键", k+1, i+1, column)
+		}
+		index[column] = i
+	}
+	if !options.Unflatten {
+		return nil
+	}
+	sep := options.pathSeparator()
+	for _, column := range columns {
+		path := strings.Split(column, sep)
+		for n := 1; n < len(path); n++ {
+			prefix := strings.Join(path[:n], sep)
+			if _, ok := index[prefix]; ok {
+				return fmt.Errorf("列 %s 与列 %s 冲突：%s 不能既是值又是嵌套对象", prefix, column, prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// csvValue 识别单元格的类型
+func csvValue(cell string, options CSVOptions) *jsonNode {
+	if cell == "" && options.EmptyAsNull {
+		return &jsonNode{kind: jsonNull, raw: "null"}
+	}
+	if options.KeepStrings {
+		return newJSONString(cell)
+	}
+	switch trimmed := strings.TrimSpace(cell); {
+	case trimmed == "null":
+		return &jsonNode{kind: jsonNull, raw: "null"}
+	case strings.EqualFold(trimmed, "true"):
+		return &jsonNode{kind: jsonBool, raw: "true"}
+	case strings.EqualFold(trimmed, "false"):
+		return &jsonNode{kind: jsonBool, raw: "false"}
+	case isJSONNumber(trimmed):
+		// 数字保留原始写法，避免大整数丢失精度
+		return &jsonNode{kind: jsonNumber, raw: trimmed}
+	case strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{"):
+		// 以 JSON 文本形式导出的数组和对象原样还原
+		if n, err := parseJSON([]byte(trimmed)); err == nil {
+			return n
+		}
+	}
+	return newJSONString(cell)
+}
+
+// setPath 按路径在对象中设置值，路径上的中间对象不存在时自动创建
+func setPath(obj *jsonNode, path []string, value *jsonNode) {
+	for i, key := range path {
+		if i == len(path)-1 {
+			obj.set(key, value)
+			return
+		}
+		child := obj.field(key)
+		if child == nil || child.kind != jsonObject {
+			child = &jsonNode{kind: jsonObject}
+			obj.set(key, child)
+		}
+		obj = child
+	}
+}
+
+// restoreArrays 将键为 0、1、2…… 的对象还原为数组
+func restoreArrays(n *jsonNode) *jsonNode {
+	if n.kind != jsonObject {
+		return n
+	}
+	for _, f := range n.fields {
+		f.value = restoreArrays(f.value)
+	}
+	keys := n.keys()
+	if len(keys) == 0 {
+		return n
+	}
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || strconv.Itoa(i) != key {
+			return n
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for i, index := range indexes {
+		if i != index {
+			return n
+		}
+	}
+	arr := &jsonNode{kind: jsonArray}
+	for i := range indexes {
+		arr.items = append(arr.items, n.field(strconv.Itoa(i)))
+	}
+	return arr
+}
@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJsonToCsv(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options CSVOptions
+		want    string
+		err     string // 非空时期望错误信息包含的内容
+	}{
+		{"nested objects", `[{"id":1,"user":{"name":"a","tags":["x","y"]}},{"id":2,"extra":null}]`, CSVOptions{},
+			"id,user.name,user.tags,extra\n1,a,x;y,\n2,,,\n", ""},
+		{"tsv with path separator", `[{"a":{"b":1}}]`, CSVOptions{Delimiter: "tab", PathSeparator: "/"}, "a/b\n1\n", ""},
+		{"array index", `[{"t":[1,2]}]`, CSVOptions{ArrayMode: CSVArrayIndex}, "t.0,t.1\n1,2\n", ""},
+		{"array rows", `[{"id":1,"t":[1,2]}]`, CSVOptions{ArrayMode: CSVArrayRows}, "id,t\n1,1\n1,2\n", ""},
+		{"array json", `[{"t":[1,"a"]}]`, CSVOptions{ArrayMode: CSVArrayJSON}, "t\n\"[1,\"\"a\"\"]\"\n", ""},
+		{"scalar records", `[1,"a"]`, CSVOptions{}, "value\n1\na\n", ""},
+		{"nested path collides with key", `[{"a":{"b":1},"a.b":2}]`, CSVOptions{}, "", "a.b"},
+		{"index path collides with key", `[{"t":[1],"t.0":2}]`, CSVOptions{ArrayMode: CSVArrayIndex}, "", "t.0"},
+		{"other separator avoids the collision", `[{"a":{"b":1},"a.b":2}]`, CSVOptions{PathSeparator: "/"}, "a/b,a.b\n1,2\n", ""},
+		{"bad array mode", `[]`, CSVOptions{ArrayMode: "x"}, "", "不支持的数组展开方式"},
+	}
+	c := NewCSVProcessor()
+	for _, tt := range tests {
+		got, err := c.JsonToCsv(tt.input, tt.options)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got %q, %v; want an error mentioning %q", tt.name, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCsvToJson(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options CSVOptions
+		want    string
+		err     string // 非空时期望错误信息包含的内容
+	}{
+		{"types", "a,b,c,d\n1,true,null,x\n", CSVOptions{}, `[{"a":1,"b":true,"c":null,"d":"x"}]`, ""},
+		{"keep strings", "a\n1\n", CSVOptions{KeepStrings: true}, `[{"a":"1"}]`, ""},
+		{"empty as null", "a,b\n,1\n", CSVOptions{EmptyAsNull: true}, `[{"a":null,"b":1}]`, ""},
+		{"no header", "1,2\n", CSVOptions{NoHeader: true}, `[{"column1":1,"column2":2}]`, ""},
+		{"extra cells", "a\n1,2\n", CSVOptions{}, `[{"a":1,"column2":2}]`, ""},
+		{"unflatten", "a.b,a.c,t.0,t.1\n1,2,x,y\n", CSVOptions{Unflatten: true}, `[{"a":{"b":1,"c":2},"t":["x","y"]}]`, ""},
+		{"duplicate columns", "x,x\n1,2\n", CSVOptions{}, "", "第 1 列和第 2 列"},
+		{"duplicate columns unflatten", "x,x\n1,2\n", CSVOptions{Unflatten: true}, "", "第 1 列和第 2 列"},
+		{"value and object", "a,a.b\n1,2\n", CSVOptions{Unflatten: true}, "", "列 a 与列 a.b 冲突"},
+		{"value and object reversed", "a.b.c,a.b\n1,2\n", CSVOptions{Unflatten: true}, "", "列 a.b 与列 a.b.c 冲突"},
+		{"conflict ignored without unflatten", "a,a.b\n1,2\n", CSVOptions{}, `[{"a":1,"a.b":2}]`, ""},
+		{"header collides with default name", "column2\n1,2\n", CSVOptions{}, "", "column2"},
+	}
+	c := NewCSVProcessor()
+	j := NewJsonProcessor()
+	for _, tt := range tests {
+		got, err := c.CsvToJson(tt.input, tt.options)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got %q, %v; want an error mentioning %q", tt.name, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if compressed, _ := j.CompressJson(got, false); compressed != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, compressed, tt.want)
+		}
+	}
+}
+
+func TestCsvRoundTrip(t *testing.T) {
+	input := `[{"id":1,"user":{"name":"a","age":30},"tags":["x","y"]},{"id":2,"user":{"name":"b","age":null},"tags":[]}]`
+	c := NewCSVProcessor()
+	csvText, err := c.JsonToCsv(input, CSVOptions{ArrayMode: CSVArrayIndex})
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := c.CsvToJson(csvText, CSVOptions{Unflatten: true, EmptyAsNull: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, _ := NewJsonProcessor().CompressJson(back, false)
+	// 第二行的空数组没有自己的列，按第一行的 tags.0、tags.1 列还原为 null
+	want := `[{"id":1,"user":{"name":"a","age":30},"tags":["x","y"]},{"id":2,"user":{"name":"b","age":null},"tags":[null,null]}]`
+	if compressed != want {
+		t.Errorf("got %s, want %s", compressed, want)
+	}
+}
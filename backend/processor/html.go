@@ -0,0 +1,594 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLFormatOptions HTML 格式化与压缩选项
+type HTMLFormatOptions struct {
+	IndentSize     int  `json:"indentSize"`     // 缩进空格数，默认 2，压缩时忽略
+	UseTabs        bool `json:"useTabs"`        // 使用制表符缩进，压缩时忽略
+	MinifyCSS      bool `json:"minifyCss"`      // 压缩 <style> 内容和 style 属性中的空白与注释
+	MinifyJS       bool `json:"minifyJs"`       // 压缩 <script> 内容中的空白与注释，保留换行
+	RemoveComments bool `json:"removeComments"` // 删除注释，条件注释保留
+}
+
+// HTMLProcessor 处理 HTML 与 SVG 的格式化和压缩
+type HTMLProcessor struct{}
+
+func NewHTMLProcessor() *HTMLProcessor {
+	return &HTMLProcessor{}
+}
+
+// FormatHTML 格式化 HTML5 或 SVG，块级元素单独成行，行内内容合并空白后保持在同一行
+// <pre>、<textarea> 的内容原样保留，<script>、<style> 的内容只调整缩进
+func (h *HTMLProcessor) FormatHTML(input string, options HTMLFormatOptions) (string, error) {
+	doc, err := parseHTML(input)
+	if err != nil {
+		return "", err
+	}
+	indent := JsonFormatOptions{IndentSize: options.IndentSize, UseTabs: options.UseTabs}.indent()
+	w := &htmlWriter{src: input, indent: indent, options: options}
+	w.blockChildren(doc.children, 0)
+	return w.b.String(), nil
+}
+
+// MinifyHTML 压缩 HTML5 或 SVG，去掉块级元素之间的空白，行内的连续空白合并为一个空格
+func (h *HTMLProcessor) MinifyHTML(input string, options HTMLFormatOptions) (string, error) {
+	doc, err := parseHTML(input)
+	if err != nil {
+		return "", err
+	}
+	w := &htmlWriter{src: input, options: options}
+	w.blockChildren(doc.children, 0)
+	return w.b.String(), nil
+}
+
+type htmlKind int
+
+const (
+	htmlDocument htmlKind = iota
+	htmlElement
+	htmlText
+	htmlComment
+	htmlRaw // DOCTYPE、处理指令、CDATA、多余的结束标签等原样输出的内容
+)
+
+type htmlAttr struct {
+	name     string
+	value    string // 源文本中的值，不解码实体
+	quote    byte   // 引号，没有引号时为 0
+	hasValue bool
+}
+
+// htmlNode HTML 语法树的节点，只记录源文本中出现的标签，不补全省略的标签
+type htmlNode struct {
+	kind        htmlKind
+	name        string // 源文本中的标签名，保留大小写
+	attrs       []htmlAttr
+	selfClosing bool
+	endTag      string // 源文本中的结束标签名，结束标签被省略时为空
+	raw         string // 文本、注释和原样输出内容的源文本
+	svg         bool   // 位于 <svg> 内
+	block       int8   // isBlock 的缓存，0 为未计算，1 为是，-1 为否
+	children    []*htmlNode
+	parent      *htmlNode
+
+	// 元素内容在输入中的范围，用于原样输出 <pre> 等元素的内容
+	contentStart int
+	contentEnd   int
+}
+
+func (n *htmlNode) lower() string {
+	return strings.ToLower(n.name)
+}
+
+// htmlVoidElements 没有内容也没有结束标签的元素
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"keygen": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlBlockElements 单独成行的元素，它们前后的空白不影响渲染
+var htmlBlockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "base": true, "blockquote": true, "body": true,
+	"caption": true, "col": true, "colgroup": true, "dd": true, "details": true, "dialog": true, "div": true,
+	"dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "head": true, "header": true,
+	"hgroup": true, "hr": true, "html": true, "legend": true, "li": true, "link": true, "main": true, "math": true,
+	"menu": true, "meta": true, "nav": true, "noscript": true, "ol": true, "p": true, "pre": true,
+	"script": true, "section": true, "style": true, "summary": true, "svg": true, "table": true, "tbody": true,
+	"td": true, "template": true, "tfoot": true, "th": true, "thead": true, "title": true, "tr": true, "ul": true,
+}
+
+// svgInlineElements <svg> 内的文本类元素，其余元素都单独成行
+var svgInlineElements = map[string]bool{"a": true, "textpath": true, "tspan": true}
+
+// htmlImpliedEnd 开始标签会隐式结束的元素，只检查当前打开的元素
+var htmlImpliedEnd = map[string][]string{
+	"li":       {"li"},
+	"dt":       {"dt", "dd"},
+	"dd":       {"dt", "dd"},
+	"td":       {"td", "th"},
+	"th":       {"td", "th"},
+	"tr":       {"td", "th", "tr"},
+	"thead":    {"td", "th", "tr", "tbody", "thead"},
+	"tbody":    {"td", "th", "tr", "tbody", "thead"},
+	"tfoot":    {"td", "th", "tr", "tbody", "thead"},
+	"option":   {"option"},
+	"optgroup": {"option", "optgroup"},
+}
+
+// htmlClosesParagraph 会隐式结束 <p> 的开始标签
+var htmlClosesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true, "div": true,
+	"dl": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hgroup": true,
+	"hr": true, "main": true, "menu": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "ul": true,
+}
+
+// parseHTML 将 HTML 按标签切分并建立语法树，容忍省略的结束标签和多余的结束标签
+func parseHTML(input string) (*htmlNode, error) {
+	doc := &htmlNode{kind: htmlDocument}
+	parent := doc
+	z := html.NewTokenizer(strings.NewReader(input))
+	offset := 0
+	// open 按小写标签名记录尚未结束的元素数量，没有同名元素时结束标签无需向上查找
+	open := map[string]int{}
+
+	// closeTo 结束 parent 到 el 之间的所有元素，end 为结束位置
+	closeTo := func(el *htmlNode, end int) {
+		for n := parent; ; n = n.parent {
+			n.contentEnd = end
+			open[n.lower()]--
+			if n == el {
+				break
+			}
+		}
+		parent = el.parent
+	}
+	add := func(n *htmlNode) {
+		n.parent = parent
+		parent.children = append(parent.children, n)
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("HTML 解析失败: %w", z.Err())
+		}
+		raw := string(z.Raw())
+		start := offset
+		offset += len(raw)
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			el := parseHTMLTag(raw)
+			lower := el.lower()
+			for _, name := range htmlImpliedEnd[lower] {
+				if parent.kind == htmlElement && parent.lower() == name {
+					closeTo(parent, start)
+				}
+			}
+			if htmlClosesParagraph[lower] && parent.kind == htmlElement && parent.lower() == "p" && !parent.svg {
+				closeTo(parent, start)
+			}
+			el.svg = lower == "svg" || (parent.svg && parent.lower() != "foreignobject")
+			el.selfClosing = tt == html.SelfClosingTagToken
+			el.contentStart, el.contentEnd = offset, offset
+			add(el)
+			if !el.selfClosing && !(htmlVoidElements[lower] && !el.svg) {
+				parent = el
+				open[lower]++
+			}
+			z.AllowCDATA(parent.svg || parent.lower() == "math")
+		case html.EndTagToken:
+			name := parseHTMLTag(raw).name
+			var match *htmlNode
+			if open[strings.ToLower(name)] > 0 {
+				for n := parent; n.kind == htmlElement; n = n.parent {
+					if strings.EqualFold(n.name, name) {
+						match = n
+						break
+					}
+				}
+			}
+			if match == nil {
+				add(&htmlNode{kind: htmlRaw, raw: "</" + name + ">"})
+				continue
+			}
+			closeTo(match, start)
+			match.endTag = name
+			z.AllowCDATA(parent.svg || parent.lower() == "math")
+		case html.TextToken:
+			kind := htmlText
+			if strings.HasPrefix(raw, "<![CDATA[") {
+				kind = htmlRaw
+			}
+			add(&htmlNode{kind: kind, raw: raw})
+		case html.CommentToken:
+			kind := htmlComment
+			if !strings.HasPrefix(raw, "<!--") {
+				kind = htmlRaw
+			}
+			add(&htmlNode{kind: kind, raw: raw})
+		case html.DoctypeToken:
+			add(&htmlNode{kind: htmlRaw, raw: raw})
+		}
+	}
+	for parent != doc {
+		parent.contentEnd = offset
+		parent = parent.parent
+	}
+	return doc, nil
+}
+
+// parseHTMLTag 从标签的源文本中读取标签名和属性，保留大小写、引号和未解码的属性值
+func parseHTMLTag(raw string) *htmlNode {
+	el := &htmlNode{kind: htmlElement}
+	i := strings.IndexByte(raw, '<') + 1
+	if strings.HasPrefix(raw[i:], "/") {
+		i++
+	}
+	isEnd := func(c byte) bool { return isHTMLSpace(c) || c == '/' || c == '>' }
+	start := i
+	for i < len(raw) && !isEnd(raw[i]) {
+		i++
+	}
+	el.name = raw[start:i]
+	for i < len(raw) {
+		if c := raw[i]; isHTMLSpace(c) || c == '/' {
+			i++
+			continue
+		}
+		if raw[i] == '>' {
+			break
+		}
+		start = i
+		for i++; i < len(raw) && !isEnd(raw[i]) && raw[i] != '='; i++ {
+		}
+		attr := htmlAttr{name: raw[start:i]}
+		j := i
+		for j < len(raw) && isHTMLSpace(raw[j]) {
+			j++
+		}
+		if j < len(raw) && raw[j] == '=' {
+			for j++; j < len(raw) && isHTMLSpace(raw[j]); j++ {
+			}
+			attr.hasValue = true
+			if j < len(raw) && (raw[j] == '"' || raw[j] == '\'') {
+				attr.quote = raw[j]
+				end := strings.IndexByte(raw[j+1:], raw[j])
+				if end < 0 {
+					end = len(raw) - j - 1
+				}
+				attr.value = raw[j+1 : j+1+end]
+				i = min(j+end+2, len(raw))
+			} else {
+				start = j
+				for j < len(raw) && !isHTMLSpace(raw[j]) && raw[j] != '>' {
+					j++
+				}
+				attr.value = raw[start:j]
+				i = j
+			}
+		}
+		el.attrs = append(el.attrs, attr)
+	}
+	return el
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isBlock 节点是否单独成行：块级元素、包含块级元素的行内元素，以及 <svg> 内除文本类以外的元素
+func (n *htmlNode) isBlock() bool {
+	if n.block == 0 {
+		n.block = -1
+		if n.computeBlock() {
+			n.block = 1
+		}
+	}
+	return n.block > 0
+}
+
+func (n *htmlNode) computeBlock() bool {
+	if n.kind == htmlRaw && strings.HasPrefix(n.raw, "<!") && !strings.HasPrefix(n.raw, "<![CDATA[") {
+		return true
+	}
+	if n.kind != htmlElement {
+		return false
+	}
+	lower := n.lower()
+	if htmlBlockElements[lower] || (n.svg && !svgInlineElements[lower]) {
+		return true
+	}
+	if lower == "textarea" {
+		return false
+	}
+	for _, child := range n.children {
+		if child.isBlock() {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlWriter 输出 HTML 语法树，indent 为空时输出压缩格式
+type htmlWriter struct {
+	b       strings.Builder
+	src     string
+	indent  string
+	options HTMLFormatOptions
+
+	// 行内内容的输出状态
+	pendingSpace bool // 有尚未输出的空白，遇到后续内容时输出为一个空格
+	lineStart    int  // 当前行内内容在 b 中的起始位置
+}
+
+func (w *htmlWriter) newline(depth int) {
+	if w.indent == "" || w.b.Len() == 0 {
+		return
+	}
+	w.b.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.b.WriteString(w.indent)
+	}
+}
+
+// blockChildren 输出块级上下文中的子节点，块级节点单独成行，相邻的行内节点合并为一行
+func (w *htmlWriter) blockChildren(children []*htmlNode, depth int) {
+	for i := 0; i < len(children); {
+		if children[i].isBlock() {
+			w.newline(depth)
+			w.block(children[i], depth)
+			i++
+			continue
+		}
+		j := i
+		for j < len(children) && !children[j].isBlock() {
+			j++
+		}
+		w.inlineRun(children[i:j], depth)
+		i = j
+	}
+}
+
+// inlineRun 输出一行行内内容，首尾空白被去掉，只有空白时不输出
+func (w *htmlWriter) inlineRun(nodes []*htmlNode, depth int) {
+	empty := true
+	for _, n := range nodes {
+		switch n.kind {
+		case htmlText:
+			empty = empty && strings.TrimLeft(n.raw, " \t\n\r\f") == ""
+		case htmlComment:
+			empty = empty && !w.comment(n)
+		default:
+			empty = false
+		}
+	}
+	if empty {
+		return
+	}
+	w.newline(depth)
+	w.lineStart, w.pendingSpace = w.b.Len(), false
+	for _, n := range nodes {
+		w.inline(n)
+	}
+	w.pendingSpace = false
+}
+
+// write 在行内内容中输出 s，先输出之前保留的空白
+func (w *htmlWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.pendingSpace && w.b.Len() > w.lineStart {
+		w.b.WriteByte(' ')
+	}
+	w.pendingSpace = false
+	w.b.WriteString(s)
+}
+
+// text 输出文本，连续的空白合并为一个空格
+func (w *htmlWriter) text(raw string) {
+	for i := 0; i < len(raw); {
+		if isHTMLSpace(raw[i]) {
+			w.pendingSpace = true
+			i++
+			continue
+		}
+		j := i
+		for j < len(raw) && !isHTMLSpace(raw[j]) {
+			j++
+		}
+		w.write(raw[i:j])
+		i = j
+	}
+}
+
+func (w *htmlWriter) comment(n *htmlNode) bool {
+	if w.options.RemoveComments && !strings.HasPrefix(n.raw, "<!--[if") && !strings.HasPrefix(n.raw, "<!--<![endif]") {
+		return false
+	}
+	return true
+}
+
+// inline 输出行内节点
+func (w *htmlWriter) inline(n *htmlNode) {
+	switch n.kind {
+	case htmlText:
+		w.text(n.raw)
+	case htmlComment:
+		if w.comment(n) {
+			w.write(n.raw)
+		}
+	case htmlRaw:
+		w.write(n.raw)
+	case htmlElement:
+		w.write(w.startTag(n))
+		if n.selfClosing {
+			return
+		}
+		if n.lower() == "textarea" {
+			w.b.WriteString(w.src[n.contentStart:n.contentEnd])
+		} else {
+			for _, child := range n.children {
+				w.inline(child)
+			}
+		}
+		if n.endTag != "" {
+			w.write("</" + n.endTag + ">")
+		}
+	}
+}
+
+// block 输出单独成行的节点
+func (w *htmlWriter) block(n *htmlNode, depth int) {
+	if n.kind != htmlElement {
+		w.b.WriteString(n.raw)
+		return
+	}
+	w.b.WriteString(w.startTag(n))
+	if n.selfClosing || (htmlVoidElements[n.lower()] && !n.svg) {
+		return
+	}
+	content := w.src[n.contentStart:n.contentEnd]
+	switch lower := n.lower(); {
+	case lower == "pre":
+		w.b.WriteString(content)
+	case lower == "script" || lower == "style":
+		w.rawText(n, content, depth)
+	default:
+		hasBlock := false
+		for _, child := range n.children {
+			hasBlock = hasBlock || child.isBlock()
+		}
+		if !hasBlock {
+			w.lineStart, w.pendingSpace = w.b.Len(), false
+			for _, child := range n.children {
+				w.inline(child)
+			}
+			w.pendingSpace = false
+			break
+		}
+		w.blockChildren(n.children, depth+1)
+		if n.endTag != "" {
+			w.newline(depth)
+		}
+	}
+	if n.endTag != "" {
+		w.b.WriteString("</" + n.endTag + ">")
+	}
+}
+
+// rawText 输出 <script>、<style> 的内容
+// 只有按选项压缩后的内容才会按元素层级重新缩进，其他内容原样输出，避免改变字符串续行和模板字符串中的空白
+func (w *htmlWriter) rawText(n *htmlNode, content string, depth int) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	isScript := n.lower() == "script"
+	switch {
+	case isScript && w.options.MinifyJS && isJavaScript(n):
+		content = minifyJS(content)
+	case !isScript && w.options.MinifyCSS:
+		content = minifyCSS(content)
+	default:
+		w.b.WriteString(content)
+		return
+	}
+	// 压缩结果中的反斜杠换行和模板字符串说明有跨行的字符串，行首空白属于字符串内容
+	if w.indent == "" || strings.Contains(content, "`") || strings.Contains(content, "\\\n") || strings.Contains(content, "\\\r") {
+		w.b.WriteString(content)
+		return
+	}
+	for _, line := range reindentLines(content) {
+		if line == "" {
+			w.b.WriteByte('\n')
+			continue
+		}
+		w.newline(depth + 1)
+		w.b.WriteString(line)
+	}
+	w.newline(depth)
+}
+
+// reindentLines 按行拆分内容，去掉首尾的空行和所有行的公共缩进
+func reindentLines(content string) []string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if common < 0 || n < common {
+			common = n
+		}
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = strings.TrimRight(line[common:], " \t")
+		}
+	}
+	return lines
+}
+
+// isJavaScript 根据 type 属性判断 <script> 的内容是否为 JavaScript
+func isJavaScript(n *htmlNode) bool {
+	for _, a := range n.attrs {
+		if strings.EqualFold(a.name, "type") {
+			t := strings.ToLower(strings.TrimSpace(a.value))
+			return t == "" || t == "module" || strings.Contains(t, "javascript") || strings.Contains(t, "ecmascript")
+		}
+	}
+	return true
+}
+
+// startTag 输出开始标签，属性之间的空白统一为一个空格
+func (w *htmlWriter) startTag(n *htmlNode) string {
+	var b strings.Builder
+	b.WriteString("<" + n.name)
+	for _, a := range n.attrs {
+		b.WriteString(" " + a.name)
+		if !a.hasValue {
+			continue
+		}
+		value := a.value
+		if w.options.MinifyCSS && strings.EqualFold(a.name, "style") {
+			value = minifyCSS(value)
+		}
+		if a.quote == 0 {
+			b.WriteString("=" + value)
+		} else {
+			b.WriteString("=" + string(a.quote) + value + string(a.quote))
+		}
+	}
+	if n.selfClosing {
+		// 未加引号的属性值后必须有空白，否则 / 会成为属性值的一部分
+		if w.indent != "" || (len(n.attrs) > 0 && n.attrs[len(n.attrs)-1].hasValue && n.attrs[len(n.attrs)-1].quote == 0) {
+			b.WriteByte(' ')
+		}
+		b.WriteByte('/')
+	}
+	b.WriteByte('>')
+	return b.String()
+}
@@ -0,0 +1,256 @@
+package processor
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// minifyCSS 删除 CSS 中的注释和多余空白，字符串和 url() 原样保留
+// 只去掉 { } ; , > 两侧的空白，选择器中的后代空格和 calc() 中运算符两侧的空格保持不变
+func minifyCSS(css string) string {
+	out := make([]byte, 0, len(css))
+	pendingSpace := false
+	tight := func(c byte) bool { return strings.IndexByte("{};,>", c) >= 0 }
+	emit := func(s string) {
+		if pendingSpace && len(out) > 0 && !tight(out[len(out)-1]) && !tight(s[0]) {
+			out = append(out, ' ')
+		}
+		pendingSpace = false
+		if s[0] == '}' && len(out) > 0 && out[len(out)-1] == ';' {
+			out = out[:len(out)-1]
+		}
+		out = append(out, s...)
+	}
+	for i := 0; i < len(css); {
+		c := css[i]
+		switch {
+		case isHTMLSpace(c):
+			pendingSpace = true
+			i++
+		case strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				return string(out)
+			}
+			pendingSpace = true
+			i += end + 4
+		case c == '"' || c == '\'':
+			end := quotedEnd(css, i)
+			emit(css[i:end])
+			i = end
+		case strings.HasPrefix(strings.ToLower(css[i:min(i+4, len(css))]), "url("):
+			end := strings.IndexByte(css[i:], ')')
+			if end < 0 {
+				end = len(css) - i - 1
+			}
+			emit(css[i : i+end+1])
+			i += end + 1
+		default:
+			emit(css[i : i+1])
+			i++
+		}
+	}
+	return string(out)
+}
+
+// quotedEnd 返回从 start 处的引号开始的字符串之后的位置，字符串没有结束时返回行尾或文本结尾
+func quotedEnd(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// jsRegexKeywords 之后的 / 是正则表达式而不是除号的关键字
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true, "delete": true,
+	"void": true, "throw": true, "case": true, "do": true, "else": true, "yield": true, "await": true,
+}
+
+func isJSIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// minifyJS 删除 JavaScript 中的注释、行首行尾的空白和空行，行内连续空白在必要时保留一个空格
+// 换行全部保留，不依赖自动分号插入规则；字符串、模板字符串和正则表达式原样保留
+// 遇到未结束的字符串、正则表达式或不匹配的括号时说明词法判断出错，返回原始内容
+func minifyJS(js string) string {
+	out := make([]byte, 0, len(js))
+	pendingSpace, pendingNewline := false, false
+	// parens 记录每个未闭合的 ( 是否为 if、while、for 等语句的条件部分，afterHead 表示最后输出的 ) 闭合了这样的条件
+	var parens []bool
+	afterHead := false
+	emit := func(s string) {
+		switch {
+		case pendingNewline && len(out) > 0:
+			out = append(out, '\n')
+		case pendingSpace && len(out) > 0 && jsNeedsSpace(out[len(out)-1], s[0]):
+			out = append(out, ' ')
+		}
+		pendingSpace, pendingNewline = false, false
+		out = append(out, s...)
+	}
+	for i := 0; i < len(js); {
+		c := js[i]
+		switch {
+		case c == '\n' || c == '\r':
+			pendingNewline = true
+			i++
+		case isHTMLSpace(c) || c == '\v':
+			pendingSpace = true
+			i++
+		case strings.HasPrefix(js[i:], "//"):
+			end := strings.IndexByte(js[i:], '\n')
+			if end < 0 {
+				return string(out)
+			}
+			i += end
+		case strings.HasPrefix(js[i:], "/*"):
+			end := strings.Index(js[i+2:], "*/")
+			if end < 0 {
+				return string(out)
+			}
+			if strings.ContainsAny(js[i:i+end+2], "\r\n") {
+				pendingNewline = true
+			} else {
+				pendingSpace = true
+			}
+			i += end + 4
+		case c == '"' || c == '\'' || c == '`':
+			end := quotedEnd(js, i)
+			if end-1 == i || js[end-1] != c {
+				return js
+			}
+			emit(js[i:end])
+			i = end
+		case c == '/' && jsRegexAllowed(out, afterHead):
+			end := regexEnd(js, i)
+			if end-1 == i || js[end-1] != '/' {
+				return js
+			}
+			emit(js[i:end])
+			i = end
+		case c == '(':
+			parens = append(parens, jsStatementHead(out))
+			emit("(")
+			i++
+		case c == ')':
+			if len(parens) == 0 {
+				return js
+			}
+			afterHead = parens[len(parens)-1]
+			parens = parens[:len(parens)-1]
+			emit(")")
+			i++
+		case c >= utf8.RuneSelf:
+			_, size := utf8.DecodeRuneInString(js[i:])
+			r := js[i : i+size]
+			if r == "\u2028" || r == "\u2029" {
+				pendingNewline = true
+			} else if r == "\u00a0" || r == "\ufeff" {
+				pendingSpace = true
+			} else {
+				emit(r)
+			}
+			i += size
+		default:
+			emit(js[i : i+1])
+			i++
+		}
+	}
+	if len(parens) > 0 {
+		return js
+	}
+	return string(out)
+}
+
+// jsNeedsSpace 两个字符之间的空白是否必须保留
+func jsNeedsSpace(a, b byte) bool {
+	switch {
+	case isJSIdentChar(a) && isJSIdentChar(b):
+		return true
+	case (a == '+' || a == '-') && a == b:
+		return true
+	case a == '/' && (b == '/' || b == '*'):
+		return true
+	case a >= '0' && a <= '9' && b == '.':
+		return true
+	}
+	return false
+}
+
+// jsRegexAllowed 根据已输出的内容判断当前位置的 / 是否开始一个正则表达式
+// afterHead 表示结尾的 ) 闭合了 if、while、for 的条件，之后是语句的开始而不是除号
+func jsRegexAllowed(out []byte, afterHead bool) bool {
+	if len(out) == 0 {
+		return true
+	}
+	last := out[len(out)-1]
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", last) >= 0 {
+		return true
+	}
+	if last == ')' {
+		return afterHead
+	}
+	if !isJSIdentChar(last) {
+		return false
+	}
+	return jsRegexKeywords[jsLastWord(out)]
+}
+
+// jsStatementHeads 之后的括号是语句条件的关键字
+var jsStatementHeads = map[string]bool{"if": true, "while": true, "for": true, "with": true}
+
+// jsStatementHead 判断已输出内容末尾的关键字之后的 ( 是否开始语句的条件
+func jsStatementHead(out []byte) bool {
+	if len(out) == 0 || !isJSIdentChar(out[len(out)-1]) {
+		return false
+	}
+	word := jsLastWord(out)
+	// obj.if(...) 是方法调用
+	if start := len(out) - len(word); start > 0 && out[start-1] == '.' {
+		return false
+	}
+	return jsStatementHeads[word]
+}
+
+// jsLastWord 返回已输出内容末尾的标识符
+func jsLastWord(out []byte) string {
+	start := len(out)
+	for start > 0 && isJSIdentChar(out[start-1]) {
+		start--
+	}
+	return string(out[start:])
+}
+
+// regexEnd 返回从 start 处开始的正则表达式字面量之后的位置，不包括标志
+func regexEnd(s string, start int) int {
+	inClass := false
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				return i + 1
+			}
+		case '\n':
+			return i
+		}
+	}
+	return len(s)
+}
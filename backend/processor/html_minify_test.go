@@ -0,0 +1,53 @@
+package processor
+
+import "testing"
+
+func TestMinifyJS(t *testing.T) {
+	tests := []struct {
+		name string
+		js   string
+		want string
+	}{
+		{"comments and blank lines", "  var a = 1; // one\n\n  /* two */ var b = 2;\n", "var a=1;\nvar b=2;"},
+		{"identifiers keep one space", "return   typeof  x", "return typeof x"},
+		{"increment operators stay apart", "a + +b; c - -d", "a+ +b;c- -d"},
+		{"strings untouched", `x = "a  // b" + 'c  /* d */'`, `x="a  // b"+'c  /* d */'`},
+		{"division", "a = b / c / d", "a=b/c/d"},
+		{"division after call", "x = f(a) / 2 / y", "x=f(a)/2/y"},
+		{"regex after assignment", "r = /a  b/g", "r=/a  b/g"},
+		{"regex after return", "return /'/.test(s)", "return/'/.test(s)"},
+		{"regex after if head", "if (a) /x'/.test('a  b')", "if(a)/x'/.test('a  b')"},
+		{"regex after while head", "while (f(x)) /y/.exec(s)", "while(f(x))/y/.exec(s)"},
+		{"regex after for head", "for (;;) /z/.test(s)", "for(;;)/z/.test(s)"},
+		{"division after method named if", "a = o.if(b) / 2", "a=o.if(b)/2"},
+		{"regex with class containing slash", "s.replace(/[/]  /g, '')", "s.replace(/[/]  /g,'')"},
+		{"template literal", "x = `a  ${b}\n  c`", "x=`a  ${b}\n  c`"},
+		{"unterminated string returns input", "x = 'a  b\ny  =  1", "x = 'a  b\ny  =  1"},
+		{"unterminated regex returns input", "x = /a  b\ny  =  1", "x = /a  b\ny  =  1"},
+		{"unbalanced parens return input", "f(a  ,\n b", "f(a  ,\n b"},
+	}
+	for _, tt := range tests {
+		if got := minifyJS(tt.js); got != tt.want {
+			t.Errorf("%s: minifyJS(%q) = %q, want %q", tt.name, tt.js, got, tt.want)
+		}
+	}
+}
+
+func TestMinifyCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		css  string
+		want string
+	}{
+		{"rules", "a  >  b , c {\n  color : red ;\n  margin: 0 auto;\n}\n", "a>b,c{color : red;margin: 0 auto}"},
+		{"comments", "/* x */ a { /* y */ b: c }", "a{b: c}"},
+		{"descendant selector", "ul   li a{x:y}", "ul li a{x:y}"},
+		{"strings and urls", `a{content:"  {  ";background:url( a b.png )}`, `a{content:"  {  ";background:url( a b.png )}`},
+		{"calc spaces kept", "a{width:calc(100% - 10px)}", "a{width:calc(100% - 10px)}"},
+	}
+	for _, tt := range tests {
+		if got := minifyCSS(tt.css); got != tt.want {
+			t.Errorf("%s: minifyCSS(%q) = %q, want %q", tt.name, tt.css, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatHTMLScriptVerbatim(t *testing.T) {
+	script := "\n  var s = \"line one \\\n    still the same string\";\n    if (a) /x'/.test('a  b')\n"
+	input := "<html><body><div><script>" + script + "</script></div></body></html>"
+	got, err := NewHTMLProcessor().FormatHTML(input, HTMLFormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "<script>"+script+"</script>") {
+		t.Errorf("script body was changed:\n%s", got)
+	}
+}
+
+func TestFormatHTMLStyleVerbatim(t *testing.T) {
+	style := "\n      a { content: \"x\\\n   y\" }\n"
+	got, err := NewHTMLProcessor().FormatHTML("<div><style>"+style+"</style></div>", HTMLFormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "<style>"+style+"</style>") {
+		t.Errorf("style body was changed:\n%s", got)
+	}
+}
+
+func TestFormatHTMLMinifiedScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			name:   "reindented",
+			script: "\n    var a = 1;   // x\n    if (a) /x'/.test('a  b')\n",
+			want:   "<div>\n  <script>\n    var a=1;\n    if(a)/x'/.test('a  b')\n  </script>\n</div>",
+		},
+		{
+			name:   "line continuation kept",
+			script: "var s = \"a \\\n  b\";",
+			want:   "<div>\n  <script>var s=\"a \\\n  b\";</script>\n</div>",
+		},
+	}
+	for _, tt := range tests {
+		got, err := NewHTMLProcessor().FormatHTML("<div><script>"+tt.script+"</script></div>", HTMLFormatOptions{MinifyJS: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMinifyHTMLScript(t *testing.T) {
+	got, err := NewHTMLProcessor().MinifyHTML("<p>x</p>\n<script>\n  if (a) /x'/.test('a  b')\n</script>", HTMLFormatOptions{MinifyJS: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "<script>if(a)/x'/.test('a  b')</script>") {
+		t.Errorf("got %s", got)
+	}
+}
+
+// 大量不匹配任何已打开元素的结束标签不应逐个向上遍历整条元素链
+func TestMinifyHTMLUnmatchedEndTags(t *testing.T) {
+	input := strings.Repeat("<div>", 30000) + strings.Repeat("</x>", 30000)
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewHTMLProcessor().MinifyHTML(input, HTMLFormatOptions{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("MinifyHTML took too long on unmatched end tags")
+	}
+}
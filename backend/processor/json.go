@@ -1,11 +1,11 @@
 package processor
 
 import (
-	"bytes"
-	"container/list"
-	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // JsonProcessor 处理 JSON 相关的功能
@@ -17,109 +17,90 @@ func NewJsonProcessor() *JsonProcessor {
 	return &JsonProcessor{}
 }
 
-// 自定义的 JSON 解码器，用于保持对象键的顺序
+// 自定义的 JSON 解码器，用于保持对象键的顺序（包括嵌套对象）
 type OrderedJSONDecoder struct {
-	order *list.List
-	data  map[string]interface{}
+	root *jsonNode
 }
 
 func NewOrderedJSONDecoder() *OrderedJSONDecoder {
-	return &OrderedJSONDecoder{
-		order: list.New(),
-		data:  make(map[string]interface{}),
-	}
+	return &OrderedJSONDecoder{}
 }
 
 func (d *OrderedJSONDecoder) Decode(data []byte) error {
-	// 先用标准解码器解析数据
-	if err := json.Unmarshal(data, &d.data); err != nil {
-		return err
-	}
-
-	// 使用原始数据来获取键的顺序
-	dec := json.NewDecoder(bytes.NewReader(data))
-
-	// 跳过开始的对象标记
-	if _, err := dec.Token(); err != nil {
+	root, err := parseJSON(data)
+	if err != nil {
 		return err
 	}
-
-	// 读取所有键
-	for dec.More() {
-		t, err := dec.Token()
-		if err != nil {
-			return err
-		}
-
-		if key, ok := t.(string); ok {
-			d.order.PushBack(key)
-			// 跳过值
-			if _, err := dec.Token(); err != nil {
-				return err
-			}
-		}
+	if root.kind != jsonObject {
+		return fmt.Errorf("JSON 顶层必须是对象")
 	}
-
+	d.root = root
 	return nil
 }
 
 func (d *OrderedJSONDecoder) MarshalJSON() ([]byte, error) {
-	buf := bytes.NewBuffer(nil)
-	buf.WriteByte('{')
-
-	first := true
-	for e := d.order.Front(); e != nil; e = e.Next() {
-		key := e.Value.(string)
-		value := d.data[key]
+	if d.root == nil {
+		return []byte("{}"), nil
+	}
+	return []byte(encodeJSON(d.root, "")), nil
+}
 
-		if !first {
-			buf.WriteByte(',')
+// decodeUnicode 将 JSON 字符串字面量中的 \uXXXX 转义解码为对应字符，UTF-16 代理对组合为一个字符
+// 解码后会改变字面量含义的字符（引号、反斜杠和控制字符）以及孤立的代理项保留转义形式
+func decodeUnicode(literal string) string {
+	if !strings.Contains(literal, `\u`) {
+		return literal
+	}
+	var b strings.Builder
+	b.Grow(len(literal))
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' {
+			b.WriteByte(literal[i])
+			continue
 		}
-		first = false
-
-		// 写入键
-		keyJSON, err := json.Marshal(key)
-		if err != nil {
-			return nil, err
+		// 其他转义序列连同反斜杠原样保留，\\u 中的 u 不会被当作转义的开头
+		if literal[i+1] != 'u' {
+			b.WriteString(literal[i : i+2])
+			i++
+			continue
 		}
-		buf.Write(keyJSON)
-		buf.WriteByte(':')
-
-		// 写入值
-		valueJSON, err := json.Marshal(value)
-		if err != nil {
-			return nil, err
+		r := hexRune(literal[i+2 : i+6])
+		size := 6
+		if utf16.IsSurrogate(r) {
+			size = 0
+			if i+12 <= len(literal) && literal[i+6] == '\\' && literal[i+7] == 'u' {
+				if pair := utf16.DecodeRune(r, hexRune(literal[i+8:i+12])); pair != utf8.RuneError {
+					r, size = pair, 12
+				}
+			}
 		}
-		buf.Write(valueJSON)
+		if size == 0 || r < 0x20 || r == '"' || r == '\\' {
+			// 孤立的代理项后面的转义交给下一轮处理
+			b.WriteString(literal[i : i+6])
+			i += 5
+			continue
+		}
+		b.WriteRune(r)
+		i += size - 1
 	}
-
-	buf.WriteByte('}')
-	return buf.Bytes(), nil
+	return b.String()
 }
 
-// decodeUnicode 解码 Unicode 转义序列
-func decodeUnicode(s string) string {
-	var result strings.Builder
-	chars := []rune(s)
-	for i := 0; i < len(chars); i++ {
-		// 检查是否是非转义的 Unicode 序列（前面不是反斜杠）
-		if i+5 <= len(chars) && chars[i] == '\\' && chars[i+1] == 'u' &&
-			(i == 0 || chars[i-1] != '\\') {
-			// 尝试解析 4 位十六进制数
-			hex := string(chars[i+2 : i+6])
-			// 验证是否是有效的十六进制字符
-			if isValidHex(hex) {
-				num, err := parseInt(hex, 16, 32)
-				if err == nil {
-					result.WriteRune(rune(num))
-					i += 5 // 跳过已处理的字符
-					continue
-				}
-			}
+// decodeUnicodeNode 解码语法树中所有字符串（包括对象的键）的 Unicode 转义，数字等其他值不受影响
+func decodeUnicodeNode(n *jsonNode) {
+	switch n.kind {
+	case jsonString:
+		n.raw = decodeUnicode(n.raw)
+	case jsonArray:
+		for _, item := range n.items {
+			decodeUnicodeNode(item)
+		}
+	case jsonObject:
+		for _, f := range n.fields {
+			f.key.raw = decodeUnicode(f.key.raw)
+			decodeUnicodeNode(f.value)
 		}
-		result.WriteRune(chars[i])
 	}
-	return result.String()
 }
 
 // isValidHex 检查是否是有效的十六进制字符串
@@ -137,61 +118,54 @@ func parseInt(s string, base int, bitSize int) (int64, error) {
 	return strconv.ParseInt(s, base, bitSize)
 }
 
-// FormatJson 格式化 JSON 字符串
-func (j *JsonProcessor) FormatJson(jsonStr string, autoDecodeUnicode bool) (string, error) {
-	// 使用 RawMessage 来保持原始 JSON 的顺序
-	var raw json.RawMessage
-	if err := json.Unmarshal([]byte(jsonStr), &raw); err != nil {
-		return "", err
+// parseJSONInput 解析输入的 JSON，需要时解码其中的 Unicode 转义序列
+func parseJSONInput(jsonStr string, autoDecodeUnicode bool) (*jsonNode, error) {
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果需要解码 Unicode
+	// 只解码字符串字面量中的转义，错误位置与编辑器中的内容一致
 	if autoDecodeUnicode {
-		jsonStr = decodeUnicode(string(raw))
-		if err := json.Unmarshal([]byte(jsonStr), &raw); err != nil {
-			return "", err
-		}
+		decodeUnicodeNode(root)
 	}
+	return root, nil
+}
 
-	// 使用 bytes.Buffer 来格式化
-	var out bytes.Buffer
-	enc := json.NewEncoder(&out)
-	enc.SetIndent("", "  ")
-	enc.SetEscapeHTML(false) // 不转义 HTML 字符
-	if err := enc.Encode(raw); err != nil {
+// FormatJson 格式化 JSON 字符串
+func (j *JsonProcessor) FormatJson(jsonStr string, autoDecodeUnicode bool) (string, error) {
+	// 语法树保持原始 JSON 的键顺序与数字字面量
+	root, err := parseJSONInput(jsonStr, autoDecodeUnicode)
+	if err != nil {
 		return "", err
 	}
-
-	// 去掉编码器自动添加的换行符
-	result := out.String()
-	return strings.TrimSuffix(result, "\n"), nil
+	return encodeJSON(root, "  "), nil
 }
 
 // CompressJson 压缩 JSON 字符串
 func (j *JsonProcessor) CompressJson(jsonStr string, autoDecodeUnicode bool) (string, error) {
-	// 使用 RawMessage 来保持原始 JSON 的顺序
-	var raw json.RawMessage
-	if err := json.Unmarshal([]byte(jsonStr), &raw); err != nil {
+	root, err := parseJSONInput(jsonStr, autoDecodeUnicode)
+	if err != nil {
 		return "", err
 	}
+	return encodeJSON(root, ""), nil
+}
 
-	// 如果需要解码 Unicode
-	if autoDecodeUnicode {
-		jsonStr = decodeUnicode(string(raw))
-		if err := json.Unmarshal([]byte(jsonStr), &raw); err != nil {
-			return "", err
-		}
+// FormatJsonWithOptions 按选项格式化 JSON 字符串，可配置缩进、键排序和末尾换行
+func (j *JsonProcessor) FormatJsonWithOptions(jsonStr string, options JsonFormatOptions) (string, error) {
+	w, err := newJSONWriter(options.indent(), options.SortKeys, options.SortRecursive)
+	if err != nil {
+		return "", err
 	}
 
-	// 使用 bytes.Buffer 来压缩
-	var out bytes.Buffer
-	enc := json.NewEncoder(&out)
-	enc.SetEscapeHTML(false) // 不转义 HTML 字符
-	if err := enc.Encode(raw); err != nil {
+	root, err := parseJSONInput(jsonStr, options.AutoDecodeUnicode)
+	if err != nil {
 		return "", err
 	}
 
-	// 去掉编码器自动添加的换行符
-	result := out.String()
-	return strings.TrimSuffix(result, "\n"), nil
+	w.write(root, 0)
+	if options.TrailingNewline {
+		w.b.WriteByte('\n')
+	}
+	return w.b.String(), nil
 }
@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// 规范化 JSON 摘要算法
+const (
+	JsonHashNone   = ""
+	JsonHashSHA256 = "sha256"
+	JsonHashSHA512 = "sha512"
+)
+
+// JsonCanonicalOptions 规范化 JSON 选项
+type JsonCanonicalOptions struct {
+	Hash string `json:"hash"` // 摘要算法：空（不计算）、sha256、sha512
+}
+
+// JsonCanonicalResult 规范化 JSON 结果
+type JsonCanonicalResult struct {
+	Canonical    string `json:"canonical"`    // RFC 8785 规范化后的 JSON
+	Digest       string `json:"digest"`       // 规范化结果 UTF-8 字节的摘要，十六进制小写
+	DigestBase64 string `json:"digestBase64"` // 同一摘要的 Base64 编码
+}
+
+// CanonicalizeJson 按 RFC 8785（JCS）输出规范化 JSON：键按 UTF-16 码元排序，数字按 ECMAScript 规则序列化，
+// 结果逐字节确定，可用于签名；需要时计算规范化结果的 SHA-256/SHA-512 摘要；字符串包含孤立的代理项时返回错误
+func (j *JsonProcessor) CanonicalizeJson(jsonStr string, options JsonCanonicalOptions) (*JsonCanonicalResult, error) {
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if err := writeCanonical(&b, root, ""); err != nil {
+		return nil, err
+	}
+	result := &JsonCanonicalResult{Canonical: b.String()}
+
+	var sum []byte
+	switch strings.ToLower(options.Hash) {
+	case JsonHashNone:
+		return result, nil
+	case JsonHashSHA256:
+		digest := sha256.Sum256([]byte(result.Canonical))
+		sum = digest[:]
+	case JsonHashSHA512:
+		digest := sha512.Sum512([]byte(result.Canonical))
+		sum = digest[:]
+	default:
+		return nil, fmt.Errorf("不支持的摘要算法: %s", options.Hash)
+	}
+	result.Digest = hex.EncodeToString(sum)
+	result.DigestBase64 = base64.StdEncoding.EncodeToString(sum)
+	return result, nil
+}
+
+// writeCanonical 输出节点的规范化形式，path 为节点的 JSON Pointer，用于错误信息
+func writeCanonical(b *strings.Builder, n *jsonNode, path string) error {
+	switch n.kind {
+	case jsonObject:
+		fields := make([]*jsonField, len(n.fields))
+		copy(fields, n.fields)
+		units := make(map[*jsonField][]uint16, len(fields))
+		for _, f := range fields {
+			if escape := loneSurrogate(f.key.raw); escape != "" {
+				return fmt.Errorf("%s 中的键 %s 包含孤立的 UTF-16 代理项 %s，无法规范化", displayPointer(path), abbreviate(f.key.raw), escape)
+			}
+			units[f] = utf16.Encode([]rune(f.name()))
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return compareUTF16(units[fields[i]], units[fields[j]]) < 0
+		})
+		b.WriteByte('{')
+		for i, f := range fields {
+			if i > 0 {
+				if fields[i-1].name() == f.name() {
+					return fmt.Errorf("%s 中存在重复的键 %q，无法规范化", displayPointer(path), f.name())
+				}
+				b.WriteByte(',')
+			}
+			b.WriteString(quoteJSONString(f.name()))
+			b.WriteByte(':')
+			if err := writeCanonical(b, f.value, path+"/"+escapeJSONPointer(f.name())); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case jsonArray:
+		b.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeCanonical(b, item, path+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case jsonString:
+		if escape := loneSurrogate(n.raw); escape != "" {
+			return fmt.Errorf("%s: 字符串包含孤立的 UTF-16 代理项 %s，无法规范化", displayPointer(path), escape)
+		}
+		b.WriteString(quoteJSONString(n.value))
+	case jsonNumber:
+		s, err := canonicalNumber(n.raw)
+		if err != nil {
+			return fmt.Errorf("%s: %v", displayPointer(path), err)
+		}
+		b.WriteString(s)
+	default:
+		b.WriteString(n.raw)
+	}
+	return nil
+}
+
+// loneSurrogate 返回字符串字面量中第一个没有组成代理对的 \uXXXX 转义，没有时返回空字符串
+// 解析时孤立的代理项会被替换为 U+FFFD，规范化结果将无法还原原始字符串
+func loneSurrogate(literal string) string {
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' {
+			continue
+		}
+		if literal[i+1] != 'u' {
+			i++
+			continue
+		}
+		r := hexRune(literal[i+2 : i+6])
+		if !utf16.IsSurrogate(r) {
+			i += 5
+			continue
+		}
+		if r < 0xdc00 && i+12 <= len(literal) && literal[i+6] == '\\' && literal[i+7] == 'u' {
+			if low := hexRune(literal[i+8 : i+12]); low >= 0xdc00 && low <= 0xdfff {
+				i += 11
+				continue
+			}
+		}
+		return literal[i : i+6]
+	}
+	return ""
+}
+
+// compareUTF16 按 UTF-16 码元比较两个键
+func compareUTF16(a, b []uint16) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+	return len(a) - len(b)
+}
+
+// canonicalNumber 按 ECMAScript Number.prototype.toString 的规则序列化数字
+func canonicalNumber(raw string) (string, error) {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return "", fmt.Errorf("数字 %s 超出 IEEE 754 双精度范围", abbreviate(raw))
+	}
+	if f == 0 {
+		// -0 也输出为 0
+		return "0", nil
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	// 指数形式：尾数使用最短表示，指数不补零，如 1e+21、1.5e-7
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	sign := exp[:1]
+	exp = strings.TrimLeft(exp[1:], "0")
+	return mantissa + "e" + sign + exp, nil
+}
@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// RFC 8785 附录 B 的数字序列化示例，输入为 IEEE 754 双精度数的十六进制位模式
+// NaN 和 Infinity 无法用 JSON 表示，未包含在内
+var jcsNumberSamples = []struct {
+	bits string
+	want string
+}{
+	{"0000000000000000", "0"},
+	{"8000000000000000", "0"},
+	{"0000000000000001", "5e-324"},
+	{"8000000000000001", "-5e-324"},
+	{"7fefffffffffffff", "1.7976931348623157e+308"},
+	{"ffefffffffffffff", "-1.7976931348623157e+308"},
+	{"4340000000000000", "9007199254740992"},
+	{"c340000000000000", "-9007199254740992"},
+	{"4430000000000000", "295147905179352830000"},
+	{"44b52d02c7e14af5", "9.999999999999997e+22"},
+	{"44b52d02c7e14af6", "1e+23"},
+	{"44b52d02c7e14af7", "1.0000000000000001e+23"},
+	{"444b1ae4d6e2ef4e", "999999999999999700000"},
+	{"444b1ae4d6e2ef4f", "999999999999999900000"},
+	{"444b1ae4d6e2ef50", "1e+21"},
+	{"3eb0c6f7a0b5ed8c", "9.999999999999997e-7"},
+	{"3eb0c6f7a0b5ed8d", "0.000001"},
+	{"41b3de4355555553", "333333333.3333332"},
+	{"41b3de4355555554", "333333333.33333325"},
+	{"41b3de4355555555", "333333333.3333333"},
+	{"41b3de4355555556", "333333333.3333334"},
+	{"41b3de4355555557", "333333333.33333343"},
+	{"becbf647612f3696", "-0.0000033333333333333333"},
+	{"43143ff3c1cb0959", "1424953923781206.2"},
+}
+
+func TestCanonicalizeJsonNumbers(t *testing.T) {
+	j := NewJsonProcessor()
+	for _, tt := range jcsNumberSamples {
+		bits, err := strconv.ParseUint(tt.bits, 16, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := math.Float64frombits(bits)
+		// 同一个数的几种不同写法都应得到相同的规范化结果
+		for _, input := range []string{
+			strconv.FormatFloat(f, 'g', -1, 64),
+			strconv.FormatFloat(f, 'e', 20, 64),
+			strconv.FormatFloat(f, 'E', -1, 64),
+		} {
+			result, err := j.CanonicalizeJson("["+input+"]", JsonCanonicalOptions{})
+			if err != nil {
+				t.Errorf("%s (%s): %v", tt.bits, input, err)
+				continue
+			}
+			if want := "[" + tt.want + "]"; result.Canonical != want {
+				t.Errorf("%s (%s): got %s, want %s", tt.bits, input, result.Canonical, want)
+			}
+		}
+	}
+	if _, err := j.CanonicalizeJson("1e400", JsonCanonicalOptions{}); err == nil {
+		t.Error("expected an error for a number outside the double range")
+	}
+}
+
+func TestCanonicalizeJsonStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		err   string // 非空时期望错误信息包含的内容
+	}{
+		{"rfc 8785 string sample", `"\u20ac\u0024\u000F\u000aA'\u0042\u0022\u005c\\\"\/"`, `"€$\u000f\nA'B\"\\\\\"/"`, ""},
+		{"surrogate pair", `"😀"`, `"😀"`, ""},
+		{"key order by utf-16", `{"\ufb33":2,"\ud83d\ude00":1}`, "{\"\U0001F600\":1,\"\uFB33\":2}", ""},
+		{"escaped backslash before u", `"\\ud800"`, `"\\ud800"`, ""},
+		{"lone high surrogate", `"a\ud800b"`, "", `\ud800`},
+		{"lone high surrogate at end", `["\uD83D"]`, "", `/0`},
+		{"high surrogate before bmp escape", `"\ud83dA"`, "", `\ud83d`},
+		{"lone low surrogate", `"\udc00"`, "", `\udc00`},
+		{"lone surrogate in key", `{"k\udfff":1}`, "", `\udfff`},
+		{"nested path", `{"a":[1,"\ud800"]}`, "", `/a/1`},
+	}
+	j := NewJsonProcessor()
+	for _, tt := range tests {
+		result, err := j.CanonicalizeJson(tt.input, JsonCanonicalOptions{})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got error %v, want one mentioning %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if result.Canonical != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, result.Canonical, tt.want)
+		}
+	}
+}
@@ -0,0 +1,566 @@
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JsonDiffOptions 结构化对比选项
+type JsonDiffOptions struct {
+	IgnoreArrayOrder bool   `json:"ignoreArrayOrder"` // 忽略数组元素顺序
+	ArrayKey         string `json:"arrayKey"`         // 对象数组按此字段匹配元素（如 id），匹配时忽略顺序
+}
+
+// JsonChange 单条差异
+type JsonChange struct {
+	Op       string `json:"op"`                 // add、remove、replace
+	Path     string `json:"path"`               // JSON Pointer 路径
+	OldValue string `json:"oldValue,omitempty"` // 原值（压缩 JSON）
+	NewValue string `json:"newValue,omitempty"` // 新值（压缩 JSON）
+	Message  string `json:"message"`            // 可读的差异描述
+}
+
+// JsonDiffResult 结构化对比结果
+type JsonDiffResult struct {
+	Equal   bool         `json:"equal"`   // 两个文档语义相同
+	Patch   string       `json:"patch"`   // RFC 6902 JSON Patch，可将左侧文档转换为右侧文档
+	Changes []JsonChange `json:"changes"` // 可读的差异列表
+}
+
+// DiffJson 语义化对比两个 JSON 文档，忽略键顺序与格式差异
+func (j *JsonProcessor) DiffJson(left string, right string, options JsonDiffOptions) (*JsonDiffResult, error) {
+	leftRoot, err := parseJSON([]byte(left))
+	if err != nil {
+		return nil, fmt.Errorf("左侧 JSON 无效: %w", err)
+	}
+	rightRoot, err := parseJSON([]byte(right))
+	if err != nil {
+		return nil, fmt.Errorf("右侧 JSON 无效: %w", err)
+	}
+
+	d := &jsonDiffer{options: options}
+	d.diff(leftRoot, rightRoot, "")
+
+	patch := &jsonNode{kind: jsonArray, items: []*jsonNode{}}
+	for _, op := range d.ops {
+		patch.items = append(patch.items, op)
+	}
+	if d.changes == nil {
+		d.changes = []JsonChange{}
+	}
+	return &JsonDiffResult{
+		Equal:   len(d.changes) == 0,
+		Patch:   encodeJSON(patch, "  "),
+		Changes: d.changes,
+	}, nil
+}
+
+// ApplyJsonPatch 将 RFC 6902 JSON Patch 应用到文档，返回格式化后的结果
+func (j *JsonProcessor) ApplyJsonPatch(jsonStr string, patch string) (string, error) {
+	doc, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return "", fmt.Errorf("JSON 文档无效: %w", err)
+	}
+	ops, err := parseJSON([]byte(patch))
+	if err != nil {
+		return "", fmt.Errorf("JSON Patch 无效: %w", err)
+	}
+	if ops.kind != jsonArray {
+		return "", fmt.Errorf("JSON Patch 必须是操作数组")
+	}
+
+	for i, op := range ops.items {
+		doc, err = applyPatchOperation(doc, op)
+		if err != nil {
+			return "", fmt.Errorf("第 %d 个补丁操作失败: %w", i+1, err)
+		}
+	}
+	return encodeJSON(doc, "  "), nil
+}
+
+// ==================== 差异计算 ====================
+
+type jsonDiffer struct {
+	options JsonDiffOptions
+	ops     []*jsonNode
+	changes []JsonChange
+}
+
+func (d *jsonDiffer) add(path string, value *jsonNode) {
+	d.ops = append(d.ops, patchOp("add", path, value))
+	d.changes = append(d.changes, JsonChange{
+		Op:       "add",
+		Path:     path,
+		NewValue: encodeJSON(value, ""),
+		Message:  fmt.Sprintf("新增 %s", displayPointer(path)),
+	})
+}
+
+func (d *jsonDiffer) remove(path string, old *jsonNode) {
+	d.ops = append(d.ops, patchOp("remove", path, nil))
+	d.changes = append(d.changes, JsonChange{
+		Op:       "remove",
+		Path:     path,
+		OldValue: encodeJSON(old, ""),
+		Message:  fmt.Sprintf("删除 %s", displayPointer(path)),
+	})
+}
+
+func (d *jsonDiffer) replace(path string, old, value *jsonNode) {
+	d.ops = append(d.ops, patchOp("replace", path, value))
+	oldText, newText := encodeJSON(old, ""), encodeJSON(value, "")
+	d.changes = append(d.changes, JsonChange{
+		Op:       "replace",
+		Path:     path,
+		OldValue: oldText,
+		NewValue: newText,
+		Message:  fmt.Sprintf("修改 %s: %s → %s", displayPointer(path), abbreviate(oldText), abbreviate(newText)),
+	})
+}
+
+// diff 递归比较两个节点，生成将 a 转换为 b 的操作
+func (d *jsonDiffer) diff(a, b *jsonNode, path string) {
+	switch {
+	case a.kind != b.kind:
+		d.replace(path, a, b)
+	case a.kind == jsonObject:
+		d.diffObject(a, b, path)
+	case a.kind == jsonArray:
+		if d.unordered(a, b) {
+			d.diffUnordered(a, b, path)
+		} else {
+			d.diffOrdered(a, b, path)
+		}
+	case !jsonEqual(a, b):
+		d.replace(path, a, b)
+	}
+}
+
+func (d *jsonDiffer) diffObject(a, b *jsonNode, path string) {
+	for _, key := range a.keys() {
+		if b.field(key) == nil {
+			d.remove(path+"/"+escapeJSONPointer(key), a.field(key))
+		}
+	}
+	for _, key := range a.keys() {
+		if other := b.field(key); other != nil {
+			d.diff(a.field(key), other, path+"/"+escapeJSONPointer(key))
+		}
+	}
+	for _, key := range b.keys() {
+		if a.field(key) == nil {
+			d.add(path+"/"+escapeJSONPointer(key), b.field(key))
+		}
+	}
+}
+
+// diffOrdered 基于最长公共子序列对比有序数组，未匹配的元素按位置配对后递归比较
+func (d *jsonDiffer) diffOrdered(a, b *jsonNode, path string) {
+	pairs := lcsPairs(a.items, b.items)
+	pairs = append(pairs, [2]int{len(a.items), len(b.items)})
+
+	idx, i, k := 0, 0, 0
+	for _, pair := range pairs {
+		// 处理两个公共元素之间的差异区间
+		for i < pair[0] && k < pair[1] {
+			d.diff(a.items[i], b.items[k], path+"/"+strconv.Itoa(idx))
+			i, k, idx = i+1, k+1, idx+1
+		}
+		for ; i < pair[0]; i++ {
+			d.remove(path+"/"+strconv.Itoa(idx), a.items[i])
+		}
+		for ; k < pair[1]; k++ {
+			d.add(path+"/"+strconv.Itoa(idx), b.items[k])
+			idx++
+		}
+		// 跳过公共元素
+		if pair[0] < len(a.items) {
+			i, k, idx = i+1, k+1, idx+1
+		}
+	}
+}
+
+// lcsPairs 返回两个数组最长公共子序列中相互匹配的下标对
+func lcsPairs(a, b []*jsonNode) [][2]int {
+	return lcsIndexPairs(len(a), len(b), func(i, k int) bool {
+		return jsonEqual(a[i], b[k])
+	})
+}
+
+// lcsIndexPairs 返回两个序列最长公共子序列中相互匹配的下标对
+func lcsIndexPairs(n, m int, equal func(i, k int) bool) [][2]int {
+	// 序列过长时只匹配公共前缀与后缀，避免二次方内存占用
+	if n*m > 4_000_000 {
+		var pairs [][2]int
+		prefix := 0
+		for prefix < n && prefix < m && equal(prefix, prefix) {
+			pairs = append(pairs, [2]int{prefix, prefix})
+			prefix++
+		}
+		var suffix [][2]int
+		for i, k := n-1, m-1; i >= prefix && k >= prefix && equal(i, k); i, k = i-1, k-1 {
+			suffix = append([][2]int{{i, k}}, suffix...)
+		}
+		return append(pairs, suffix...)
+	}
+
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for k := m - 1; k >= 0; k-- {
+			if equal(i, k) {
+				table[i][k] = table[i+1][k+1] + 1
+			} else {
+				table[i][k] = max(table[i+1][k], table[i][k+1])
+			}
+		}
+	}
+	var pairs [][2]int
+	for i, k := 0, 0; i < n && k < m; {
+		switch {
+		case equal(i, k):
+			pairs = append(pairs, [2]int{i, k})
+			i, k = i+1, k+1
+		case table[i+1][k] >= table[i][k+1]:
+			i++
+		default:
+			k++
+		}
+	}
+	return pairs
+}
+
+// unordered 判断数组是否按无序集合对比
+func (d *jsonDiffer) unordered(a, b *jsonNode) bool {
+	if d.options.IgnoreArrayOrder {
+		return true
+	}
+	return d.options.ArrayKey != "" && d.keyed(a) && d.keyed(b)
+}
+
+// keyed 判断数组元素是否都是包含匹配字段的对象
+func (d *jsonDiffer) keyed(n *jsonNode) bool {
+	if d.options.ArrayKey == "" {
+		return false
+	}
+	for _, item := range n.items {
+		if item.kind != jsonObject || item.field(d.options.ArrayKey) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// diffUnordered 忽略顺序对比数组：先按键或内容匹配元素，再删除多余元素并追加缺失元素
+func (d *jsonDiffer) diffUnordered(a, b *jsonNode, path string) {
+	byKey := d.keyed(a) && d.keyed(b)
+	matched := make([]int, len(a.items))
+	used := make([]bool, len(b.items))
+	for i, item := range a.items {
+		matched[i] = -1
+		for k, other := range b.items {
+			if used[k] {
+				continue
+			}
+			var same bool
+			if byKey {
+				same = jsonEqual(item.field(d.options.ArrayKey), other.field(d.options.ArrayKey))
+			} else {
+				same = jsonEqual(item, other)
+			}
+			if same {
+				matched[i] = k
+				used[k] = true
+				break
+			}
+		}
+	}
+
+	for i, k := range matched {
+		if k >= 0 {
+			d.diff(a.items[i], b.items[k], path+"/"+strconv.Itoa(i))
+		}
+	}
+	for i := len(a.items) - 1; i >= 0; i-- {
+		if matched[i] < 0 {
+			d.remove(path+"/"+strconv.Itoa(i), a.items[i])
+		}
+	}
+	for k, item := range b.items {
+		if !used[k] {
+			d.add(path+"/-", item)
+		}
+	}
+}
+
+// patchOp 构造单个 JSON Patch 操作
+func patchOp(op, path string, value *jsonNode) *jsonNode {
+	n := &jsonNode{kind: jsonObject, fields: []*jsonField{
+		{key: newJSONString("op"), value: newJSONString(op)},
+		{key: newJSONString("path"), value: newJSONString(path)},
+	}}
+	if value != nil {
+		n.fields = append(n.fields, &jsonField{key: newJSONString("value"), value: value})
+	}
+	return n
+}
+
+// displayPointer 返回用于展示的路径，根节点显示为 /
+func displayPointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// abbreviate 截断过长的值用于展示
+func abbreviate(s string) string {
+	const limit = 80
+	if r := []rune(s); len(r) > limit {
+		return string(r[:limit]) + "…"
+	}
+	return s
+}
+
+// ==================== 补丁应用 ====================
+
+func applyPatchOperation(doc, op *jsonNode) (*jsonNode, error) {
+	if op.kind != jsonObject {
+		return nil, fmt.Errorf("补丁操作必须是对象")
+	}
+	name, err := patchString(op, "op")
+	if err != nil {
+		return nil, err
+	}
+	path, err := patchString(op, "path")
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := parseJSONPointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "add", "replace", "test":
+		value := op.field("value")
+		if value == nil {
+			return nil, fmt.Errorf("%s 操作缺少 value", name)
+		}
+		switch name {
+		case "add":
+			return pointerAdd(doc, tokens, cloneJSON(value))
+		case "replace":
+			return pointerReplace(doc, tokens, cloneJSON(value))
+		default:
+			current, err := pointerGet(doc, tokens)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, fmt.Errorf("test 失败: %s 的值不等于 %s", displayPointer(path), abbreviate(encodeJSON(value, "")))
+			}
+			return doc, nil
+		}
+	case "remove":
+		return pointerRemove(doc, tokens)
+	case "move", "copy":
+		from, err := patchString(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		fromTokens, err := parseJSONPointer(from)
+		if err != nil {
+			return nil, err
+		}
+		value, err := pointerGet(doc, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		if name == "copy" {
+			return pointerAdd(doc, tokens, cloneJSON(value))
+		}
+		if from == path {
+			return doc, nil
+		}
+		if strings.HasPrefix(path, from+"/") {
+			return nil, fmt.Errorf("不能将 %s 移动到其子路径 %s", from, path)
+		}
+		if doc, err = pointerRemove(doc, fromTokens); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, tokens, value)
+	default:
+		return nil, fmt.Errorf("不支持的操作 %q", name)
+	}
+}
+
+func patchString(op *jsonNode, name string) (string, error) {
+	v := op.field(name)
+	if v == nil || v.kind != jsonString {
+		return "", fmt.Errorf("补丁操作缺少字符串字段 %s", name)
+	}
+	return v.value, nil
+}
+
+// parseJSONPointer 解析 RFC 6901 JSON Pointer
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("无效的 JSON Pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		t = strings.ReplaceAll(t, "~1", "/")
+		tokens[i] = strings.ReplaceAll(t, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex 解析数组下标，allowEnd 为 true 时允许等于数组长度或使用 -
+func arrayIndex(n *jsonNode, token string, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return len(n.items), nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, fmt.Errorf("无效的数组下标 %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	limit := len(n.items)
+	if allowEnd {
+		limit++
+	}
+	if err != nil || i >= limit {
+		return 0, fmt.Errorf("数组下标 %s 越界", token)
+	}
+	return i, nil
+}
+
+func pointerGet(doc *jsonNode, tokens []string) (*jsonNode, error) {
+	current := doc
+	for _, t := range tokens {
+		switch current.kind {
+		case jsonObject:
+			next := current.field(t)
+			if next == nil {
+				return nil, fmt.Errorf("路径中不存在键 %q", t)
+			}
+			current = next
+		case jsonArray:
+			i, err := arrayIndex(current, t, false)
+			if err != nil {
+				return nil, err
+			}
+			current = current.items[i]
+		default:
+			return nil, fmt.Errorf("无法在非容器值中查找 %q", t)
+		}
+	}
+	return current, nil
+}
+
+func pointerAdd(doc *jsonNode, tokens []string, value *jsonNode) (*jsonNode, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := pointerGet(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch parent.kind {
+	case jsonObject:
+		for i := len(parent.fields) - 1; i >= 0; i-- {
+			if parent.fields[i].name() == last {
+				parent.fields[i].value = value
+				return doc, nil
+			}
+		}
+		parent.fields = append(parent.fields, &jsonField{key: newJSONString(last), value: value})
+	case jsonArray:
+		i, err := arrayIndex(parent, last, true)
+		if err != nil {
+			return nil, err
+		}
+		parent.items = append(parent.items, nil)
+		copy(parent.items[i+1:], parent.items[i:])
+		parent.items[i] = value
+	default:
+		return nil, fmt.Errorf("无法向非容器值添加 %q", last)
+	}
+	return doc, nil
+}
+
+// pointerReplace 替换已存在的值，保持其在对象或数组中的位置
+func pointerReplace(doc *jsonNode, tokens []string, value *jsonNode) (*jsonNode, error) {
+	if _, err := pointerGet(doc, tokens); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, _ := pointerGet(doc, tokens[:len(tokens)-1])
+	last := tokens[len(tokens)-1]
+	if parent.kind == jsonArray {
+		i, _ := arrayIndex(parent, last, false)
+		parent.items[i] = value
+		return doc, nil
+	}
+	return pointerAdd(doc, tokens, value)
+}
+
+func pointerRemove(doc *jsonNode, tokens []string) (*jsonNode, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("不能删除根节点")
+	}
+	parent, err := pointerGet(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch parent.kind {
+	case jsonObject:
+		if parent.field(last) == nil {
+			return nil, fmt.Errorf("路径中不存在键 %q", last)
+		}
+		fields := parent.fields[:0]
+		for _, f := range parent.fields {
+			if f.name() != last {
+				fields = append(fields, f)
+			}
+		}
+		parent.fields = fields
+	case jsonArray:
+		i, err := arrayIndex(parent, last, false)
+		if err != nil {
+			return nil, err
+		}
+		parent.items = append(parent.items[:i], parent.items[i+1:]...)
+	default:
+		return nil, fmt.Errorf("无法从非容器值删除 %q", last)
+	}
+	return doc, nil
+}
+
+// cloneJSON 深拷贝节点
+func cloneJSON(n *jsonNode) *jsonNode {
+	c := *n
+	if n.items != nil {
+		c.items = make([]*jsonNode, len(n.items))
+		for i, item := range n.items {
+			c.items[i] = cloneJSON(item)
+		}
+	}
+	if n.fields != nil {
+		c.fields = make([]*jsonField, len(n.fields))
+		for i, f := range n.fields {
+			c.fields[i] = &jsonField{key: f.key, value: cloneJSON(f.value)}
+		}
+	}
+	return &c
+}
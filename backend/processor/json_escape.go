@@ -0,0 +1,135 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EscapeJson 将文本转义为 JSON 字符串字面量（带引号），compress 为 true 时先压缩 JSON 再转义
+func (j *JsonProcessor) EscapeJson(text string, compress bool) (string, error) {
+	if compress {
+		root, err := parseJSON([]byte(text))
+		if err != nil {
+			return "", err
+		}
+		text = encodeJSON(root, "")
+	}
+	return quoteJSONString(text), nil
+}
+
+// UnescapeJson 还原被转义为字符串的 JSON，支持带引号的字符串字面量和不带引号的转义文本，
+// 多次转义的内容会逐层还原；结果是合法的 JSON 时格式化输出，否则原样返回文本
+func (j *JsonProcessor) UnescapeJson(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("内容为空")
+	}
+
+	n, err := parseJSON([]byte(text))
+	if err != nil || n.kind != jsonString {
+		// 不带引号的转义文本，如 {\"a\":1}
+		if n, err = parseJSON([]byte(`"` + text + `"`)); err != nil {
+			return "", fmt.Errorf("不是有效的转义字符串: %v", err)
+		}
+	}
+	for n.kind == jsonString {
+		inner, err := parseJSON([]byte(n.value))
+		if err != nil {
+			return n.value, nil
+		}
+		n = inner
+	}
+	return encodeJSON(n, "  "), nil
+}
+
+// NestedJsonResult 展开嵌套 JSON 的结果
+type NestedJsonResult struct {
+	Result string   `json:"result"` // 展开后格式化的 JSON
+	Paths  []string `json:"paths"`  // 被展开的字符串字段的 JSON Pointer，外层在前，可用于还原
+}
+
+// ExpandNestedJson 递归查找内容为 JSON 对象或数组的字符串字段（如消息队列信封中的 payload），就地展开为 JSON 值
+func (j *JsonProcessor) ExpandNestedJson(jsonStr string) (*NestedJsonResult, error) {
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	expandNested(root, "", &paths)
+	return &NestedJsonResult{Result: encodeJSON(root, "  "), Paths: paths}, nil
+}
+
+// expandNested 展开节点中的嵌套 JSON 字符串，展开后的内容继续递归处理
+func expandNested(n *jsonNode, path string, paths *[]string) {
+	switch n.kind {
+	case jsonString:
+		if inner := nestedJSON(n.value); inner != nil {
+			*n = *inner
+			*paths = append(*paths, path)
+			expandNested(n, path, paths)
+		}
+	case jsonArray:
+		for i, item := range n.items {
+			expandNested(item, path+"/"+strconv.Itoa(i), paths)
+		}
+	case jsonObject:
+		for _, f := range n.fields {
+			expandNested(f.value, path+"/"+escapeJSONPointer(f.name()), paths)
+		}
+	}
+}
+
+// nestedJSON 字符串内容是 JSON 对象或数组时返回解析结果，多次转义的字符串逐层解析
+func nestedJSON(s string) *jsonNode {
+	for {
+		trimmed := strings.TrimSpace(s)
+		if trimmed == "" || !strings.ContainsRune(`{["`, rune(trimmed[0])) {
+			return nil
+		}
+		n, err := parseJSON([]byte(trimmed))
+		if err != nil {
+			return nil
+		}
+		if n.kind != jsonString {
+			return n
+		}
+		s = n.value
+	}
+}
+
+// StringifyNestedJson 将指定路径上的值压缩后转换回 JSON 字符串，是 ExpandNestedJson 的逆操作
+// paths 为 JSON Pointer 列表，通常使用展开时返回的 Paths；内层路径先于外层处理
+func (j *JsonProcessor) StringifyNestedJson(jsonStr string, paths []string) (string, error) {
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("请指定需要转换为字符串的路径")
+	}
+
+	tokens := make([][]string, len(paths))
+	for i, path := range paths {
+		if tokens[i], err = parseJSONPointer(path); err != nil {
+			return "", err
+		}
+	}
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(tokens[order[a]]) > len(tokens[order[b]])
+	})
+
+	for _, i := range order {
+		n, err := pointerGet(root, tokens[i])
+		if err != nil {
+			return "", fmt.Errorf("路径 %s 无效: %v", displayPointer(paths[i]), err)
+		}
+		*n = *newJSONString(encodeJSON(n, ""))
+	}
+	return encodeJSON(root, "  "), nil
+}
@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 键排序方式
+const (
+	JsonSortNone    = "none"    // 保持原始顺序
+	JsonSortAlpha   = "alpha"   // 按字典序排序
+	JsonSortNatural = "natural" // 自然排序，数字部分按数值比较
+)
+
+// JsonFormatOptions JSON 格式化选项
+type JsonFormatOptions struct {
+	IndentSize        int    `json:"indentSize"`        // 每级缩进的空格数，默认 2
+	UseTabs           bool   `json:"useTabs"`           // 使用制表符缩进
+	SortKeys          string `json:"sortKeys"`          // 键排序方式：none、alpha、natural
+	SortRecursive     bool   `json:"sortRecursive"`     // 是否对嵌套对象递归排序，否则只排序最外层
+	TrailingNewline   bool   `json:"trailingNewline"`   // 输出末尾追加换行符
+	AutoDecodeUnicode bool   `json:"autoDecodeUnicode"` // 自动解码 Unicode 转义序列
+}
+
+// indent 返回每级缩进字符串
+func (o JsonFormatOptions) indent() string {
+	if o.UseTabs {
+		return "\t"
+	}
+	size := o.IndentSize
+	if size <= 0 {
+		size = 2
+	}
+	return strings.Repeat(" ", size)
+}
+
+// jsonWriter 将语法树输出为 JSON 文本
+type jsonWriter struct {
+	b             strings.Builder
+	indent        string // 为空时输出压缩格式
+	sortKeys      string
+	sortRecursive bool
+}
+
+func newJSONWriter(indent, sortKeys string, sortRecursive bool) (*jsonWriter, error) {
+	switch sortKeys {
+	case "", JsonSortNone, JsonSortAlpha, JsonSortNatural:
+	default:
+		return nil, fmt.Errorf("不支持的键排序方式: %s", sortKeys)
+	}
+	return &jsonWriter{indent: indent, sortKeys: sortKeys, sortRecursive: sortRecursive}, nil
+}
+
+// encodeJSON 使用给定缩进输出节点，保持原始键顺序
+func encodeJSON(n *jsonNode, indent string) string {
+	w := &jsonWriter{indent: indent}
+	w.write(n, 0)
+	return w.b.String()
+}
+
+func (w *jsonWriter) newline(depth int) {
+	if w.indent == "" {
+		return
+	}
+	w.b.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.b.WriteString(w.indent)
+	}
+}
+
+func (w *jsonWriter) write(n *jsonNode, depth int) {
+	switch n.kind {
+	case jsonArray:
+		if len(n.items) == 0 {
+			w.b.WriteString("[]")
+			return
+		}
+		w.b.WriteByte('[')
+		for i, item := range n.items {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			w.newline(depth + 1)
+			w.write(item, depth+1)
+		}
+		w.newline(depth)
+		w.b.WriteByte(']')
+	case jsonObject:
+		if len(n.fields) == 0 {
+			w.b.WriteString("{}")
+			return
+		}
+		w.b.WriteByte('{')
+		for i, f := range w.orderedFields(n, depth) {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			w.newline(depth + 1)
+			w.b.WriteString(f.key.raw)
+			w.b.WriteByte(':')
+			if w.indent != "" {
+				w.b.WriteByte(' ')
+			}
+			w.write(f.value, depth+1)
+		}
+		w.newline(depth)
+		w.b.WriteByte('}')
+	default:
+		w.b.WriteString(n.raw)
+	}
+}
+
+// orderedFields 根据排序选项返回对象成员
+func (w *jsonWriter) orderedFields(n *jsonNode, depth int) []*jsonField {
+	if w.sortKeys == "" || w.sortKeys == JsonSortNone || (depth > 0 && !w.sortRecursive) {
+		return n.fields
+	}
+	fields := make([]*jsonField, len(n.fields))
+	copy(fields, n.fields)
+	less := func(a, b string) bool { return a < b }
+	if w.sortKeys == JsonSortNatural {
+		less = naturalLess
+	}
+	sort.SliceStable(fields, func(i, k int) bool {
+		return less(fields[i].name(), fields[k].name())
+	})
+	return fields
+}
+
+// naturalLess 自然排序比较，连续数字按数值大小比较，例如 item2 < item10
+func naturalLess(a, b string) bool {
+	i, k := 0, 0
+	for i < len(a) && k < len(b) {
+		ca, cb := a[i], b[k]
+		if isDigit(ca) && isDigit(cb) {
+			si := i
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			sk := k
+			for k < len(b) && isDigit(b[k]) {
+				k++
+			}
+			na := strings.TrimLeft(a[si:i], "0")
+			nb := strings.TrimLeft(b[sk:k], "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			// 数值相同时前导零较少的排在前面
+			if i-si != k-sk {
+				return i-si < k-sk
+			}
+			continue
+		}
+		la, lb := toLowerASCII(ca), toLowerASCII(cb)
+		if la != lb {
+			return la < lb
+		}
+		if ca != cb {
+			return ca < cb
+		}
+		i++
+		k++
+	}
+	return len(a)-i < len(b)-k
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func toLowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
@@ -0,0 +1,187 @@
+package processor
+
+import (
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoStructOptions 生成 Go 结构体的选项
+type GoStructOptions struct {
+	TypeName       string `json:"typeName"`       // 顶层类型名，默认 AutoGenerated
+	PackageName    string `json:"packageName"`    // 包名，非空时输出 package 声明
+	Flatten        bool   `json:"flatten"`        // 嵌套对象生成独立的具名类型，否则内联定义
+	AllOmitempty   bool   `json:"allOmitempty"`   // 所有字段都添加 omitempty，否则只给部分样本中缺失的字段添加
+	Gofmt          bool   `json:"gofmt"`          // 使用 gofmt 格式化输出
+	ArrayAsSamples bool   `json:"arrayAsSamples"` // 只有一个样本且为数组时，将数组元素视为多个样本
+}
+
+// goInitialisms Go 命名中需要整体大写的缩写词
+var goInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "GUID": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true, "QPS": true,
+	"RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true, "URI": true, "URL": true,
+	"UTF8": true, "VM": true, "XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// JsonToGo 根据一个或多个 JSON 样本生成 Go 结构体定义
+func (j *JsonProcessor) JsonToGo(samples []string, options GoStructOptions) (string, error) {
+	nodes, err := parseSamples(samples, options.ArrayAsSamples)
+	if err != nil {
+		return "", err
+	}
+	if options.TypeName == "" {
+		options.TypeName = "AutoGenerated"
+	}
+	m := buildTypeModel(inferShape(nodes), pascalCase(options.TypeName))
+	g := &goGenerator{options: options}
+
+	var defs []string
+	root := goIdentifier(m.rootName)
+	if m.rootIsObject() {
+		defs = append(defs, fmt.Sprintf("type %s %s\n", root, g.structBody(m.root.object, 0)))
+	} else {
+		defs = append(defs, fmt.Sprintf("type %s %s\n", root, g.typeOf(m.root, 0)))
+	}
+	if options.Flatten {
+		for _, o := range m.namedObjects() {
+			if o.name != m.rootName {
+				defs = append(defs, fmt.Sprintf("type %s %s\n", goIdentifier(o.name), g.structBody(o, 0)))
+			}
+		}
+	}
+
+	var b strings.Builder
+	if options.PackageName != "" {
+		fmt.Fprintf(&b, "package %s\n\n", options.PackageName)
+		if g.usesJSON {
+			b.WriteString("import \"encoding/json\"\n\n")
+		}
+	}
+	b.WriteString(strings.Join(defs, "\n"))
+	return finishGoSource(b.String(), options.PackageName == "", options.Gofmt)
+}
+
+// finishGoSource 使用 go/parser 校验生成的代码，并按需执行 gofmt
+// 没有 package 声明的代码会临时补上声明再处理
+func finishGoSource(src string, noPackage, gofmt bool) (string, error) {
+	const header = "package main\n\n"
+	if noPackage {
+		src = header + src
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "", src, parser.AllErrors); err != nil {
+		return "", fmt.Errorf("生成的 Go 代码无效: %v", err)
+	}
+	if gofmt {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return "", fmt.Errorf("gofmt 格式化失败: %v", err)
+		}
+		src = string(formatted)
+	}
+	if noPackage {
+		src = strings.TrimPrefix(src, header)
+	}
+	return src, nil
+}
+
+// goGenerator 根据类型模型生成 Go 代码
+type goGenerator struct {
+	options  GoStructOptions
+	usesJSON bool // 是否用到了 json.Number
+}
+
+// typeOf 返回类型对应的 Go 类型表达式
+func (g *goGenerator) typeOf(r *typeRef, depth int) string {
+	var t string
+	switch r.kind {
+	case typeAny:
+		return "any"
+	case typeBool:
+		t = "bool"
+	case typeInt:
+		t = "int"
+	case typeLong:
+		t = "int64"
+	case typeBigInt:
+		// 超出 int64 的整数使用 json.Number 保留原始精度
+		g.usesJSON = true
+		t = "json.Number"
+	case typeFloat:
+		t = "float64"
+	case typeString:
+		t = "string"
+	case typeArray:
+		return "[]" + g.typeOf(r.elem, depth)
+	case typeObject:
+		if g.options.Flatten {
+			t = goIdentifier(r.object.name)
+		} else {
+			t = g.structBody(r.object, depth)
+		}
+	}
+	// 可能为 null 的值使用指针
+	if r.nullable {
+		return "*" + t
+	}
+	return t
+}
+
+// structBody 生成结构体定义
+func (g *goGenerator) structBody(o *objectType, depth int) string {
+	if len(o.fields) == 0 {
+		return "struct{}"
+	}
+	indent := strings.Repeat("\t", depth+1)
+	used := map[string]bool{}
+
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for _, f := range o.fields {
+		name := uniqueName(used, goIdentifier(f.key))
+		value := f.key
+		if g.options.AllOmitempty || f.optional {
+			value += ",omitempty"
+		}
+		tag := "json:" + strconv.Quote(value)
+		fmt.Fprintf(&b, "%s%s %s %s\n", indent, name, g.typeOf(f.typ, depth+1), goTagLiteral(tag))
+	}
+	b.WriteString(strings.Repeat("\t", depth) + "}")
+	return b.String()
+}
+
+// goTagLiteral 生成结构体标签字面量，无法使用反引号时退回为双引号字符串
+func goTagLiteral(tag string) string {
+	if strings.ContainsAny(tag, "`\n\r") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// goIdentifier 将 JSON 键转换为导出的 Go 标识符，识别常见缩写词
+func goIdentifier(key string) string {
+	var b strings.Builder
+	for _, word := range splitWords(key) {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(capitalize(word))
+	}
+	name := b.String()
+	switch {
+	case name == "":
+		return "Field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "Num" + name
+	case !unicode.IsUpper([]rune(name)[0]):
+		// 汉字等没有大小写的字符开头时补上前缀，保证字段可导出
+		return "X" + name
+	}
+	return name
+}
@@ -0,0 +1,313 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTrackedValues 每个字段最多记录的不同字符串取值数量
+const maxTrackedValues = 32
+
+// jsonShape 由多个样本合并得到的值结构，用于推断 schema 与类型定义
+type jsonShape struct {
+	total    int // 观察到的值数量，含 null
+	nulls    int
+	booleans int
+	integers int
+	wideInts int // 超出 int32 范围的整数
+	hugeInts int // 超出 int64 范围的整数
+	floats   int
+	strings  int
+	arrays   int
+	objects  int
+
+	values   []string       // 不同的字符串取值，按首次出现的顺序
+	overflow bool           // 字符串取值超过 maxTrackedValues
+	formats  map[string]int // 字符串格式 → 匹配次数
+
+	items  *jsonShape    // 数组元素合并后的结构，没有见过非空数组时为 nil
+	fields []*shapeField // 对象字段，按首次出现的顺序
+}
+
+// shapeField 对象字段及其出现次数
+type shapeField struct {
+	name  string
+	shape *jsonShape
+	count int
+}
+
+// inferShape 合并多个样本的结构
+func inferShape(samples []*jsonNode) *jsonShape {
+	s := &jsonShape{}
+	for _, sample := range samples {
+		s.add(sample)
+	}
+	return s
+}
+
+// add 将一个值合并到结构中
+func (s *jsonShape) add(n *jsonNode) {
+	s.total++
+	switch n.kind {
+	case jsonNull:
+		s.nulls++
+	case jsonBool:
+		s.booleans++
+	case jsonNumber:
+		if strings.ContainsAny(n.raw, ".eE") {
+			s.floats++
+		} else {
+			s.integers++
+			if v, err := strconv.ParseInt(n.raw, 10, 64); err != nil {
+				s.hugeInts++
+			} else if v > math.MaxInt32 || v < math.MinInt32 {
+				s.wideInts++
+			}
+		}
+	case jsonString:
+		s.strings++
+		s.addString(n.value)
+	case jsonArray:
+		s.arrays++
+		for _, item := range n.items {
+			if s.items == nil {
+				s.items = &jsonShape{}
+			}
+			s.items.add(item)
+		}
+	case jsonObject:
+		s.objects++
+		for _, key := range n.keys() {
+			f := s.field(key)
+			if f == nil {
+				f = &shapeField{name: key, shape: &jsonShape{}}
+				s.fields = append(s.fields, f)
+			}
+			f.count++
+			f.shape.add(n.field(key))
+		}
+	}
+}
+
+func (s *jsonShape) addString(v string) {
+	if format := detectStringFormat(v); format != "" {
+		if s.formats == nil {
+			s.formats = map[string]int{}
+		}
+		s.formats[format]++
+	}
+	if s.overflow {
+		return
+	}
+	for _, existing := range s.values {
+		if existing == v {
+			return
+		}
+	}
+	if len(s.values) >= maxTrackedValues {
+		s.overflow = true
+		return
+	}
+	s.values = append(s.values, v)
+}
+
+func (s *jsonShape) field(name string) *shapeField {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// nullable 是否既出现过 null 又出现过其他类型的值
+func (s *jsonShape) nullable() bool {
+	return s.nulls > 0 && s.nulls < s.total
+}
+
+// optional 字段是否在部分对象中缺失
+func (f *shapeField) optional(parent *jsonShape) bool {
+	return f.count < parent.objects
+}
+
+// format 所有字符串都匹配的格式，没有时返回空字符串
+func (s *jsonShape) format() string {
+	for format, count := range s.formats {
+		if count == s.strings {
+			return format
+		}
+	}
+	return ""
+}
+
+// enum 字符串取值较少且有重复时视为枚举，返回全部取值
+func (s *jsonShape) enum(maxValues int) []string {
+	if maxValues <= 0 || s.overflow || s.strings < 2 || len(s.values) > maxValues ||
+		len(s.values) >= s.strings || s.format() != "" {
+		return nil
+	}
+	return s.values
+}
+
+// detectStringFormat 识别常见的字符串格式
+func detectStringFormat(v string) string {
+	switch {
+	case uuidPattern.MatchString(v):
+		return "uuid"
+	case strings.Contains(v, "@"):
+		if addr, err := mail.ParseAddress(v); err == nil && addr.Address == v {
+			return "email"
+		}
+	case len(v) >= 20 && v[4] == '-' && (v[10] == 'T' || v[10] == 't'):
+		if _, err := time.Parse(time.RFC3339Nano, strings.ToUpper(v)); err == nil {
+			return "date-time"
+		}
+	}
+	return ""
+}
+
+// JsonSchemaInferOptions 推断 JSON Schema 的选项
+type JsonSchemaInferOptions struct {
+	Draft          string `json:"draft"`          // "2020-12"（默认）或 "draft-07"
+	Title          string `json:"title"`          // schema 标题，为空时不输出
+	EnumMaxValues  int    `json:"enumMaxValues"`  // 不同取值不超过该数量的字符串字段生成 enum，0 使用默认值 5，负数不生成
+	ArrayAsSamples bool   `json:"arrayAsSamples"` // 只有一个样本且为数组时，将数组元素视为多个样本
+}
+
+// schemaDraftURIs 各版本 JSON Schema 的 $schema 地址
+var schemaDraftURIs = map[string]string{
+	"2020-12":  "https://json-schema.org/draft/2020-12/schema",
+	"draft-07": "http://json-schema.org/draft-07/schema#",
+}
+
+// InferJsonSchema 根据一个或多个样本推断 JSON Schema，返回格式化后的 schema 文本
+func (j *JsonProcessor) InferJsonSchema(samples []string, options JsonSchemaInferOptions) (string, error) {
+	if options.Draft == "" {
+		options.Draft = "2020-12"
+	}
+	uri, ok := schemaDraftURIs[options.Draft]
+	if !ok {
+		return "", fmt.Errorf("不支持的 JSON Schema 版本: %s", options.Draft)
+	}
+	if options.EnumMaxValues == 0 {
+		options.EnumMaxValues = 5
+	}
+
+	nodes, err := parseSamples(samples, options.ArrayAsSamples)
+	if err != nil {
+		return "", err
+	}
+
+	schema := &jsonNode{kind: jsonObject}
+	schema.set("$schema", newJSONString(uri))
+	if options.Title != "" {
+		schema.set("title", newJSONString(options.Title))
+	}
+	body := shapeSchema(inferShape(nodes), options.EnumMaxValues)
+	schema.fields = append(schema.fields, body.fields...)
+	return encodeJSON(schema, "  "), nil
+}
+
+// parseSamples 解析样本列表，忽略空白样本
+func parseSamples(samples []string, arrayAsSamples bool) ([]*jsonNode, error) {
+	var nodes []*jsonNode
+	for i, sample := range samples {
+		if strings.TrimSpace(sample) == "" {
+			continue
+		}
+		n, err := parseJSON([]byte(sample))
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个样本不是有效的 JSON: %w", i+1, err)
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("至少需要一个 JSON 样本")
+	}
+	if arrayAsSamples && len(nodes) == 1 && nodes[0].kind == jsonArray {
+		if len(nodes[0].items) == 0 {
+			return nil, fmt.Errorf("样本数组为空")
+		}
+		return nodes[0].items, nil
+	}
+	return nodes, nil
+}
+
+// shapeSchema 将合并后的结构转换为 schema
+func shapeSchema(s *jsonShape, enumMaxValues int) *jsonNode {
+	schema := &jsonNode{kind: jsonObject}
+	integers, floats := s.integers, s.floats
+	if floats > 0 {
+		// 整数与小数混合时统一为 number
+		integers, floats = 0, floats+integers
+	}
+	var types []string
+	for _, t := range []struct {
+		name  string
+		count int
+	}{
+		{"object", s.objects},
+		{"array", s.arrays},
+		{"string", s.strings},
+		{"integer", integers},
+		{"number", floats},
+		{"boolean", s.booleans},
+		{"null", s.nulls},
+	} {
+		if t.count > 0 {
+			types = append(types, t.name)
+		}
+	}
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema.set("type", newJSONString(types[0]))
+	default:
+		list := &jsonNode{kind: jsonArray}
+		for _, t := range types {
+			list.items = append(list.items, newJSONString(t))
+		}
+		schema.set("type", list)
+	}
+
+	if s.strings > 0 {
+		if format := s.format(); format != "" {
+			schema.set("format", newJSONString(format))
+		} else if values := s.enum(enumMaxValues); values != nil && s.strings+s.nulls == s.total {
+			// enum 会限制所有取值，只在字段仅包含字符串（及 null）时生成
+			list := &jsonNode{kind: jsonArray}
+			for _, v := range values {
+				list.items = append(list.items, newJSONString(v))
+			}
+			if s.nulls > 0 {
+				list.items = append(list.items, &jsonNode{kind: jsonNull, raw: "null"})
+			}
+			schema.set("enum", list)
+		}
+	}
+
+	if s.arrays > 0 && s.items != nil {
+		schema.set("items", shapeSchema(s.items, enumMaxValues))
+	}
+
+	if s.objects > 0 {
+		properties := &jsonNode{kind: jsonObject}
+		required := &jsonNode{kind: jsonArray}
+		for _, f := range s.fields {
+			properties.set(f.name, shapeSchema(f.shape, enumMaxValues))
+			if !f.optional(s) {
+				required.items = append(required.items, newJSONString(f.name))
+			}
+		}
+		schema.set("properties", properties)
+		if len(required.items) > 0 {
+			schema.set("required", required)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,193 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JsonLinesValidation NDJSON 逐行校验结果
+type JsonLinesValidation struct {
+	Valid   bool          `json:"valid"`   // 所有行都是合法的 JSON
+	Records int           `json:"records"` // 非空行（记录）的数量
+	Errors  []SyntaxError `json:"errors"`  // 各错误行的语法错误，行号为在整个文本中的行号
+}
+
+// jsonLine NDJSON 中的一条记录
+type jsonLine struct {
+	number int    // 记录第一行的行号，从 1 开始
+	offset int    // 行首在文本中的字节偏移量
+	text   string // 去除行尾 \r 的原始内容，多行记录包含其中所有行
+	node   *jsonNode
+}
+
+// parseJSONLines 逐行解析 NDJSON，跳过空行；keepGoing 为 false 时遇到第一个错误即返回
+// 同时接受 FormatNdjson 输出的多行记录：从只有 { 或 [ 的一行开始，到顶格的 } 或 ] 所在行为止，遇到空行时提前结束
+func parseJSONLines(text string, keepGoing bool) ([]*jsonLine, []SyntaxError) {
+	var lines []*jsonLine
+	var errs []SyntaxError
+	offset := 0
+	if strings.HasPrefix(text, "\uFEFF") {
+		offset = len("\uFEFF")
+	}
+	rows := strings.Split(text[offset:], "\n")
+	for i := 0; i < len(rows); i++ {
+		line := &jsonLine{number: i + 1, offset: offset}
+		offset += len(rows[i]) + 1
+
+		trimmed := strings.TrimSpace(rows[i])
+		if trimmed == "" {
+			continue
+		}
+		if trimmed == "{" || trimmed == "[" {
+			for i+1 < len(rows) && strings.TrimSpace(rows[i+1]) != "" {
+				i++
+				offset += len(rows[i]) + 1
+				if c := rows[i][0]; c == '}' || c == ']' {
+					break
+				}
+			}
+		}
+		line.text = strings.TrimSuffix(text[line.offset:min(offset-1, len(text))], "\r")
+		node, err := parseJSON([]byte(line.text))
+		if err != nil {
+			errs = append(errs, lineSyntaxError(err, line))
+			if !keepGoing {
+				return nil, errs
+			}
+			continue
+		}
+		line.node = node
+		lines = append(lines, line)
+	}
+	return lines, errs
+}
+
+// lineSyntaxError 将单行内的语法错误转换为整个文本中的位置
+func lineSyntaxError(err error, line *jsonLine) SyntaxError {
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return SyntaxError{Message: fmt.Sprintf("第 %d 行: %v", line.number, err), Reason: err.Error(), Line: line.number, Column: 1, Offset: line.offset}
+	}
+	e := *syntaxErr
+	e.Line += line.number - 1
+	e.Offset += line.offset
+	e.Message = fmt.Sprintf("第 %d 行第 %d 列: %s", e.Line, e.Column, e.Reason)
+	if e.Expected != "" {
+		e.Message += fmt.Sprintf("（期望 %s，实际为 %s）", e.Expected, e.Found)
+	}
+	return e
+}
+
+// parseJSONLinesStrict 逐行解析 NDJSON，任意一行无效时返回该行的语法错误
+func parseJSONLinesStrict(text string) ([]*jsonLine, error) {
+	lines, errs := parseJSONLines(text, false)
+	if len(errs) > 0 {
+		return nil, &errs[0]
+	}
+	return lines, nil
+}
+
+// ValidateNdjson 逐行校验 NDJSON（JSON Lines），返回所有无效行及其行号；FormatNdjson 输出的多行记录同样有效
+func (j *JsonProcessor) ValidateNdjson(text string) *JsonLinesValidation {
+	lines, errs := parseJSONLines(text, true)
+	if errs == nil {
+		errs = []SyntaxError{}
+	}
+	return &JsonLinesValidation{
+		Valid:   len(errs) == 0,
+		Records: len(lines) + len(errs),
+		Errors:  errs,
+	}
+}
+
+// FormatNdjson 按选项格式化 NDJSON 中的每条记录，记录之间以空行分隔，便于阅读
+// 输出仍可用于 NDJSON 的其他操作，CompressNdjson 可将其还原为每行一条记录
+func (j *JsonProcessor) FormatNdjson(text string, options JsonFormatOptions) (string, error) {
+	lines, err := parseJSONLinesStrict(text)
+	if err != nil {
+		return "", err
+	}
+	w, err := newJSONWriter(options.indent(), options.SortKeys, options.SortRecursive)
+	if err != nil {
+		return "", err
+	}
+	for i, line := range lines {
+		if i > 0 {
+			w.b.WriteString("\n\n")
+		}
+		if options.AutoDecodeUnicode {
+			decodeUnicodeNode(line.node)
+		}
+		w.write(line.node, 0)
+	}
+	if options.TrailingNewline {
+		w.b.WriteByte('\n')
+	}
+	return w.b.String(), nil
+}
+
+// CompressNdjson 将每条记录压缩为一行，并去除空行
+func (j *JsonProcessor) CompressNdjson(text string) (string, error) {
+	lines, err := parseJSONLinesStrict(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(encodeJSON(line.node, ""))
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// FilterNdjson 按 JSONPath 过滤表达式筛选记录，表达式中 @ 和 $ 都指向当前记录，
+// 如 @.level == 'error' && @.status >= 500；命中的记录原样输出
+func (j *JsonProcessor) FilterNdjson(text string, expression string) (string, error) {
+	filter, err := compileJSONPathFilter(expression)
+	if err != nil {
+		return "", err
+	}
+	lines, err := parseJSONLinesStrict(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		if filter.test(line.node, line.node) {
+			b.WriteString(line.text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// NdjsonToArray 将 NDJSON 转换为格式化的 JSON 数组
+func (j *JsonProcessor) NdjsonToArray(text string) (string, error) {
+	lines, err := parseJSONLinesStrict(text)
+	if err != nil {
+		return "", err
+	}
+	arr := &jsonNode{kind: jsonArray, items: make([]*jsonNode, 0, len(lines))}
+	for _, line := range lines {
+		arr.items = append(arr.items, line.node)
+	}
+	return encodeJSON(arr, "  "), nil
+}
+
+// ArrayToNdjson 将 JSON 数组转换为 NDJSON，每个元素压缩为一行
+func (j *JsonProcessor) ArrayToNdjson(jsonStr string) (string, error) {
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return "", err
+	}
+	if root.kind != jsonArray {
+		return "", fmt.Errorf("JSON 顶层必须是数组")
+	}
+	var b strings.Builder
+	for _, item := range root.items {
+		b.WriteString(encodeJSON(item, ""))
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+const ndjsonSample = "{\"level\":\"info\",\"status\":200,\"id\":12345678901234567890}\r\n\n[1,2]\n\"text\"\n{\"level\":\"error\",\"status\":503,\"msg\":\"\\u4f60\\u597d\"}\n"
+
+func TestValidateNdjson(t *testing.T) {
+	j := NewJsonProcessor()
+	tests := []struct {
+		name    string
+		text    string
+		records int
+		errors  []string // 期望的错误信息
+	}{
+		{"valid", ndjsonSample, 4, nil},
+		{"empty", "", 0, nil},
+		{"bom", "\uFEFF{}\n{}", 2, nil},
+		{"bad lines", "{}\n{\"a\":}\n\n[1,\n{}\nnul", 5, []string{
+			"第 2 行第 6 列: 无效的字符（期望 值，实际为 '}'）",
+			"第 4 行第 4 列: JSON 意外结束（期望 值，实际为 文本结尾）",
+			"第 6 行第 4 列: 无效的字面量（期望 null，实际为 文本结尾）",
+		}},
+		{"pretty records", "{\n  \"a\": 1\n}\n\n[\n  1\n]\n{\n  \"b\": 2\n}\n3", 4, nil},
+		{"error inside a pretty record", "{\n  \"a\": 1,\n  \"b\": \n}\n{}", 2, []string{"第 4 行第 1 列: 无效的字符（期望 值，实际为 '}'）"}},
+		{"unclosed pretty record stops at blank line", "{\n  \"a\": 1\n\n{}", 2, []string{"第 2 行第 9 列: 对象未闭合（期望 ',' 或 '}'，实际为 文本结尾）"}},
+	}
+	for _, tt := range tests {
+		result := j.ValidateNdjson(tt.text)
+		var got []string
+		for _, e := range result.Errors {
+			got = append(got, e.Message)
+		}
+		if result.Records != tt.records || result.Valid != (len(tt.errors) == 0) || strings.Join(got, "\n") != strings.Join(tt.errors, "\n") {
+			t.Errorf("%s: got records=%d valid=%v errors=%q, want records=%d errors=%q", tt.name, result.Records, result.Valid, got, tt.records, tt.errors)
+		}
+	}
+}
+
+func TestFormatNdjsonRoundTrip(t *testing.T) {
+	j := NewJsonProcessor()
+	formatted, err := j.FormatNdjson(ndjsonSample, JsonFormatOptions{SortKeys: JsonSortAlpha, AutoDecodeUnicode: true, TrailingNewline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "id": 12345678901234567890,
+  "level": "info",
+  "status": 200
+}
+
+[
+  1,
+  2
+]
+
+"text"
+
+{
+  "level": "error",
+  "msg": "你好",
+  "status": 503
+}
+`
+	if formatted != want {
+		t.Errorf("FormatNdjson:\ngot:\n%s\nwant:\n%s", formatted, want)
+	}
+
+	// 格式化的输出仍然可以进行其他 NDJSON 操作
+	if result := j.ValidateNdjson(formatted); !result.Valid || result.Records != 4 {
+		t.Errorf("ValidateNdjson on formatted output: %+v", result)
+	}
+	compressed, err := j.CompressNdjson(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"id\":12345678901234567890,\"level\":\"info\",\"status\":200}\n[1,2]\n\"text\"\n{\"level\":\"error\",\"msg\":\"你好\",\"status\":503}\n"; compressed != want {
+		t.Errorf("CompressNdjson:\ngot  %q\nwant %q", compressed, want)
+	}
+	filtered, err := j.FilterNdjson(formatted, "@.status >= 500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"level\": \"error\",\n  \"msg\": \"你好\",\n  \"status\": 503\n}\n"; filtered != want {
+		t.Errorf("FilterNdjson on formatted output:\ngot  %q\nwant %q", filtered, want)
+	}
+	array, err := j.NdjsonToArray(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := j.ArrayToNdjson(array)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back != compressed {
+		t.Errorf("array round trip:\ngot  %q\nwant %q", back, compressed)
+	}
+
+	// 制表符缩进同样可以还原
+	tabs, err := j.FormatNdjson(ndjsonSample, JsonFormatOptions{UseTabs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ = j.CompressNdjson(ndjsonSample)
+	if again, err := j.CompressNdjson(tabs); err != nil || again != want {
+		t.Errorf("CompressNdjson on tab-indented output: %q, %v", again, err)
+	}
+}
+
+func TestCompressNdjson(t *testing.T) {
+	j := NewJsonProcessor()
+	got, err := j.CompressNdjson("{ \"a\" : [ 1 , 2 ] }\n\n  \n[ ]\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"a\":[1,2]}\n[]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := j.CompressNdjson("{}\n{"); err == nil || !strings.Contains(err.Error(), "第 2 行") {
+		t.Errorf("got %v, want an error on line 2", err)
+	}
+}
+
+func TestFilterNdjson(t *testing.T) {
+	j := NewJsonProcessor()
+	tests := []struct {
+		expression string
+		want       string
+		err        string
+	}{
+		{"@.level == 'error'", "{\"level\":\"error\",\"status\":503,\"msg\":\"\\u4f60\\u597d\"}\n", ""},
+		{"?(@.status < 500)", "{\"level\":\"info\",\"status\":200,\"id\":12345678901234567890}\n", ""},
+		{"$.id == 12345678901234567890", "{\"level\":\"info\",\"status\":200,\"id\":12345678901234567890}\n", ""},
+		{"@.missing", "", ""},
+		{"", "", "过滤表达式为空"},
+		{"@.a ==", "", "JSONPath 语法错误"},
+	}
+	for _, tt := range tests {
+		got, err := j.FilterNdjson(ndjsonSample, tt.expression)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: got %v, want an error mentioning %q", tt.expression, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.expression, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestNdjsonArrayConversion(t *testing.T) {
+	j := NewJsonProcessor()
+	array, err := j.NdjsonToArray("{\"a\":1}\n\n2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[\n  {\n    \"a\": 1\n  },\n  2\n]"; array != want {
+		t.Errorf("NdjsonToArray: got %q, want %q", array, want)
+	}
+	if array, err := j.NdjsonToArray(""); err != nil || array != "[]" {
+		t.Errorf("NdjsonToArray of empty input: %q, %v", array, err)
+	}
+	ndjson, err := j.ArrayToNdjson("[{\"a\": [1, 2]}, \"x\", null]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"a\":[1,2]}\n\"x\"\nnull\n"; ndjson != want {
+		t.Errorf("ArrayToNdjson: got %q, want %q", ndjson, want)
+	}
+	if _, err := j.ArrayToNdjson(`{"a":1}`); err == nil || !strings.Contains(err.Error(), "必须是数组") {
+		t.Errorf("ArrayToNdjson of an object: %v", err)
+	}
+}
@@ -0,0 +1,506 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsonKind JSON 节点类型
+type jsonKind int
+
+const (
+	jsonNull jsonKind = iota
+	jsonBool
+	jsonNumber
+	jsonString
+	jsonArray
+	jsonObject
+)
+
+// jsonNode 保持原始顺序与原始字面量的 JSON 语法树节点
+//
+// 数字始终以源文本中的字面量保存和输出，从不转换为 float64，
+// 因此超过 2^53 的整数、高精度小数和指数写法都能原样往返；需要比较大小时使用 compareJSONNumbers 精确比较。
+type jsonNode struct {
+	kind   jsonKind
+	raw    string       // 原始字面量：字符串含引号，数字、true/false/null 原样保存
+	value  string       // 字符串解码后的值
+	items  []*jsonNode  // 数组元素
+	fields []*jsonField // 对象成员，按源文本顺序排列
+}
+
+// jsonField 对象成员
+type jsonField struct {
+	key   *jsonNode
+	value *jsonNode
+}
+
+// name 返回成员键解码后的值
+func (f *jsonField) name() string {
+	return f.key.value
+}
+
+// field 按键查找对象成员的值，重复键以最后一个为准
+func (n *jsonNode) field(key string) *jsonNode {
+	for i := len(n.fields) - 1; i >= 0; i-- {
+		if n.fields[i].name() == key {
+			return n.fields[i].value
+		}
+	}
+	return nil
+}
+
+// maxJSONDepth 对象和数组的最大嵌套层数，与 encoding/json 相同，避免过深的输入耗尽调用栈
+const maxJSONDepth = 10000
+
+// jsonParser 严格按照 RFC 8259 解析 JSON 文本
+type jsonParser struct {
+	data  []byte
+	pos   int
+	depth int // 当前所在的对象和数组层数
+}
+
+// parseJSON 将 JSON 文本解析为语法树
+func parseJSON(data []byte) (*jsonNode, error) {
+	p := &jsonParser{data: data}
+	// 跳过 UTF-8 BOM
+	if len(data) >= 3 && data[0] == 0xEF && data[1] == 0xBB && data[2] == 0xBF {
+		p.pos = 3
+	}
+	p.skipSpace()
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.data) {
+		return nil, p.fail("文本结尾", "JSON 值之后存在多余内容")
+	}
+	return node, nil
+}
+
+// fail 在当前位置构造语法错误
+func (p *jsonParser) fail(expected string, reason string) error {
+	return newSyntaxError(p.data, p.pos, expected, reason)
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jsonParser) parseValue() (*jsonNode, error) {
+	if p.pos >= len(p.data) {
+		return nil, p.fail("值", "JSON 意外结束")
+	}
+	switch c := p.data[p.pos]; {
+	case c == '{' || c == '[':
+		if p.depth >= maxJSONDepth {
+			return nil, p.fail("值", fmt.Sprintf("嵌套超过 %d 层", maxJSONDepth))
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+		if c == '{' {
+			return p.parseObject()
+		}
+		return p.parseArray()
+	case c == '"':
+		return p.parseString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c == 't':
+		return p.parseLiteral("true", jsonBool)
+	case c == 'f':
+		return p.parseLiteral("false", jsonBool)
+	case c == 'n':
+		return p.parseLiteral("null", jsonNull)
+	default:
+		return nil, p.fail("值", "无效的字符")
+	}
+}
+
+func (p *jsonParser) peekRune() rune {
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	return r
+}
+
+func (p *jsonParser) parseObject() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonObject, fields: []*jsonField{}}
+	p.pos++ // {
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return node, nil
+	}
+	for {
+		if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+			return nil, p.fail("双引号包围的键", "对象键必须是字符串")
+		}
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.fail("':'", "对象键之后缺少冒号")
+		}
+		p.pos++
+		p.skipSpace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.fields = append(node.fields, &jsonField{key: key, value: value})
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.fail("',' 或 '}'", "对象未闭合")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case '}':
+			p.pos++
+			return node, nil
+		default:
+			return nil, p.fail("',' 或 '}'", "对象成员之间缺少逗号")
+		}
+	}
+}
+
+func (p *jsonParser) parseArray() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonArray, items: []*jsonNode{}}
+	p.pos++ // [
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return node, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, value)
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.fail("',' 或 ']'", "数组未闭合")
+		}
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case ']':
+			p.pos++
+			return node, nil
+		default:
+			return nil, p.fail("',' 或 ']'", "数组元素之间缺少逗号")
+		}
+	}
+}
+
+func (p *jsonParser) parseString() (*jsonNode, error) {
+	start := p.pos
+	p.pos++ // "
+	for {
+		if p.pos >= len(p.data) {
+			return nil, p.fail(`'"'`, "字符串未结束")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			raw := string(p.data[start:p.pos])
+			return &jsonNode{kind: jsonString, raw: raw, value: unquoteJSONString(raw)}, nil
+		case c == '\\':
+			if p.pos+1 >= len(p.data) {
+				return nil, p.fail("转义字符", "字符串未结束")
+			}
+			switch p.data[p.pos+1] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				p.pos += 2
+			case 'u':
+				if p.pos+6 > len(p.data) || !isValidHex(string(p.data[p.pos+2:p.pos+6])) {
+					return nil, p.fail("4 位十六进制数", "无效的 Unicode 转义序列")
+				}
+				p.pos += 6
+			default:
+				p.pos++
+				return nil, p.fail(`'"'、'\\'、'/'、'b'、'f'、'n'、'r'、't' 或 'u'`, "无效的转义字符")
+			}
+		case c < 0x20:
+			return nil, p.fail("", "字符串中包含未转义的控制字符")
+		case c < utf8.RuneSelf:
+			p.pos++
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return nil, p.fail("", "字符串中包含无效的 UTF-8 字节")
+			}
+			p.pos += size
+		}
+	}
+}
+
+func (p *jsonParser) parseNumber() (*jsonNode, error) {
+	start := p.pos
+	if p.data[p.pos] == '-' {
+		p.pos++
+	}
+	switch {
+	case p.pos < len(p.data) && p.data[p.pos] == '0':
+		p.pos++
+	case p.pos < len(p.data) && p.data[p.pos] >= '1' && p.data[p.pos] <= '9':
+		p.skipDigits()
+	default:
+		return nil, p.fail("数字", "无效的数字")
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if !p.skipDigits() {
+			return nil, p.fail("数字", "小数点之后缺少数字")
+		}
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return nil, p.fail("数字", "指数部分缺少数字")
+		}
+	}
+	return &jsonNode{kind: jsonNumber, raw: string(p.data[start:p.pos])}, nil
+}
+
+// isJSONNumber 判断文本是否是完整且合法的 JSON 数字字面量
+func isJSONNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	p := &jsonParser{data: []byte(s)}
+	if _, err := p.parseNumber(); err != nil {
+		return false
+	}
+	return p.pos == len(s)
+}
+
+// skipDigits 跳过连续数字，返回是否至少跳过了一个
+func (p *jsonParser) skipDigits() bool {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *jsonParser) parseLiteral(literal string, kind jsonKind) (*jsonNode, error) {
+	for i := 0; i < len(literal); i++ {
+		if p.pos >= len(p.data) || p.data[p.pos] != literal[i] {
+			return nil, p.fail(literal, "无效的字面量")
+		}
+		p.pos++
+	}
+	return &jsonNode{kind: kind, raw: literal}, nil
+}
+
+// unquoteJSONString 解码已校验过的 JSON 字符串字面量
+func unquoteJSONString(raw string) string {
+	s := raw[1 : len(raw)-1]
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r := hexRune(s[i+1 : i+5])
+			i += 4
+			// 组合 UTF-16 代理对
+			if utf16.IsSurrogate(r) && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if pair := utf16.DecodeRune(r, hexRune(s[i+3:i+7])); pair != utf8.RuneError {
+					r = pair
+					i += 6
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// hexRune 将 4 位十六进制数转换为字符
+func hexRune(hex string) rune {
+	num, err := parseInt(hex, 16, 32)
+	if err != nil {
+		return utf8.RuneError
+	}
+	return rune(num)
+}
+
+// quoteJSONString 将字符串编码为 JSON 字符串字面量，不转义 HTML 字符
+func quoteJSONString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// newJSONString 创建字符串节点
+func newJSONString(s string) *jsonNode {
+	return &jsonNode{kind: jsonString, raw: quoteJSONString(s), value: s}
+}
+
+// set 在对象末尾追加字段
+func (n *jsonNode) set(key string, value *jsonNode) {
+	n.fields = append(n.fields, &jsonField{key: newJSONString(key), value: value})
+}
+
+// jsonEqual 按语义比较两个节点：对象忽略键顺序，数字按数值比较
+func jsonEqual(a, b *jsonNode) bool {
+	if a.kind != b.kind {
+		return false
+	}
+	switch a.kind {
+	case jsonNumber:
+		return compareJSONNumbers(a.raw, b.raw) == 0
+	case jsonString:
+		return a.value == b.value
+	case jsonArray:
+		if len(a.items) != len(b.items) {
+			return false
+		}
+		for i := range a.items {
+			if !jsonEqual(a.items[i], b.items[i]) {
+				return false
+			}
+		}
+		return true
+	case jsonObject:
+		keys := a.keys()
+		if len(keys) != len(b.keys()) {
+			return false
+		}
+		for _, key := range keys {
+			other := b.field(key)
+			if other == nil || !jsonEqual(a.field(key), other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.raw == b.raw
+	}
+}
+
+// keys 返回对象中去重后的键，按首次出现的顺序排列
+func (n *jsonNode) keys() []string {
+	seen := make(map[string]bool, len(n.fields))
+	keys := make([]string, 0, len(n.fields))
+	for _, f := range n.fields {
+		if !seen[f.name()] {
+			seen[f.name()] = true
+			keys = append(keys, f.name())
+		}
+	}
+	return keys
+}
+
+// compareJSONNumbers 精确比较两个 JSON 数字字面量，返回 -1、0 或 1
+func compareJSONNumbers(a, b string) int {
+	ra, okA := parseJSONRat(a)
+	rb, okB := parseJSONRat(b)
+	if okA && okB {
+		return ra.Cmp(rb)
+	}
+	// 指数过大时退化为高精度浮点比较
+	fa, _, _ := big.ParseFloat(a, 10, 1024, big.ToNearestEven)
+	fb, _, _ := big.ParseFloat(b, 10, 1024, big.ToNearestEven)
+	if fa == nil || fb == nil {
+		return strings.Compare(a, b)
+	}
+	return fa.Cmp(fb)
+}
+
+// parseJSONRat 将 JSON 数字解析为有理数，指数过大时返回 false 以避免占用过多内存
+func parseJSONRat(s string) (*big.Rat, bool) {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil || exp > 4096 || exp < -4096 {
+			return nil, false
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	return r, ok
+}
+
+// escapeJSONPointer 转义 JSON Pointer 中的引用标记
+func escapeJSONPointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+// lineColumn 将字节偏移量转换为从 1 开始的行号与列号，列号按字符计数
+func lineColumn(data []byte, offset int) (line, column int) {
+	offset = min(max(offset, 0), len(data))
+	line, lineStart := 1, 0
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, utf8.RuneCount(data[lineStart:offset]) + 1
+}
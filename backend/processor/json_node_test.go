@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONMaxDepth(t *testing.T) {
+	j := NewJsonProcessor()
+	deep := strings.Repeat("[", 8<<20) + strings.Repeat("]", 8<<20)
+	calls := map[string]func(string) error{
+		"FormatJson":   func(s string) error { _, err := j.FormatJson(s, false); return err },
+		"CompressJson": func(s string) error { _, err := j.CompressJson(s, false); return err },
+		"QueryJson":    func(s string) error { _, err := j.QueryJson(s, "$"); return err },
+		"CanonicalizeJson": func(s string) error {
+			_, err := j.CanonicalizeJson(s, JsonCanonicalOptions{})
+			return err
+		},
+	}
+	for name, call := range calls {
+		err := call(deep)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || !strings.Contains(syntaxErr.Reason, "10000") {
+			t.Fatalf("%s: got %v, want a nesting depth SyntaxError", name, err)
+		}
+		if syntaxErr.Offset != maxJSONDepth {
+			t.Errorf("%s: error offset %d, want %d", name, syntaxErr.Offset, maxJSONDepth)
+		}
+	}
+
+	// 恰好 10000 层仍然有效
+	limit := strings.Repeat(`{"a":[`, maxJSONDepth/2) + strings.Repeat("]}", maxJSONDepth/2)
+	out, err := j.CompressJson(limit, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != limit {
+		t.Error("nesting at the limit was not preserved")
+	}
+	if _, err := j.CompressJson("["+limit+"]", false); err == nil {
+		t.Error("expected an error one level past the limit")
+	}
+}
+
+func TestRepairJsonMaxDepth(t *testing.T) {
+	j := NewJsonProcessor()
+	for _, input := range []string{
+		strings.Repeat("[", 8<<20) + strings.Repeat("]", 8<<20),
+		strings.Repeat("{'a':", 1<<20),
+	} {
+		_, err := j.RepairJson(input, JsonFormatOptions{})
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || !strings.Contains(syntaxErr.Reason, "10000") {
+			t.Errorf("got %v, want a nesting depth SyntaxError", err)
+		}
+	}
+	if _, err := j.RepairJson(strings.Repeat("[", maxJSONDepth), JsonFormatOptions{}); err != nil {
+		t.Errorf("unclosed arrays at the limit: %v", err)
+	}
+}
@@ -0,0 +1,936 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JsonQueryMatch JSONPath 查询命中项
+type JsonQueryMatch struct {
+	Path    string `json:"path"`    // 规范化路径，如 $['store']['book'][0]
+	Pointer string `json:"pointer"` // JSON Pointer 路径，如 /store/book/0
+	Value   string `json:"value"`   // 命中值格式化后的 JSON 文本
+}
+
+// QueryJson 对 JSON 执行 JSONPath 查询，支持通配符、递归下降、切片、联合和过滤表达式
+func (j *JsonProcessor) QueryJson(jsonStr string, expression string) ([]JsonQueryMatch, error) {
+	query, err := compileJSONPath(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []JsonQueryMatch{}
+	for _, m := range query.evaluate(root, root) {
+		matches = append(matches, JsonQueryMatch{
+			Path:    m.path(),
+			Pointer: m.pointer(),
+			Value:   encodeJSON(m.node, "  "),
+		})
+	}
+	return matches, nil
+}
+
+// jpLocation 节点在文档中的位置，以链表形式记录路径
+type jpLocation struct {
+	parent *jpLocation
+	key    string
+	index  int
+	isKey  bool
+}
+
+// jpNode 查询过程中的节点及其位置
+type jpNode struct {
+	node *jsonNode
+	loc  *jpLocation
+}
+
+func (n jpNode) steps() []*jpLocation {
+	var steps []*jpLocation
+	for l := n.loc; l != nil; l = l.parent {
+		steps = append(steps, l)
+	}
+	for i, k := 0, len(steps)-1; i < k; i, k = i+1, k-1 {
+		steps[i], steps[k] = steps[k], steps[i]
+	}
+	return steps
+}
+
+// path 返回 RFC 9535 规范化路径
+func (n jpNode) path() string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, s := range n.steps() {
+		if s.isKey {
+			b.WriteString("['")
+			for _, r := range s.key {
+				switch {
+				case r == '\'':
+					b.WriteString(`\'`)
+				case r == '\\':
+					b.WriteString(`\\`)
+				case r < 0x20:
+					fmt.Fprintf(&b, `\u%04x`, r)
+				default:
+					b.WriteRune(r)
+				}
+			}
+			b.WriteString("']")
+		} else {
+			fmt.Fprintf(&b, "[%d]", s.index)
+		}
+	}
+	return b.String()
+}
+
+// pointer 返回 RFC 6901 JSON Pointer
+func (n jpNode) pointer() string {
+	var b strings.Builder
+	for _, s := range n.steps() {
+		b.WriteByte('/')
+		if s.isKey {
+			b.WriteString(escapeJSONPointer(s.key))
+		} else {
+			b.WriteString(strconv.Itoa(s.index))
+		}
+	}
+	return b.String()
+}
+
+// children 返回节点的直接子节点
+func (n jpNode) children() []jpNode {
+	var result []jpNode
+	switch n.node.kind {
+	case jsonArray:
+		for i, item := range n.node.items {
+			result = append(result, jpNode{item, &jpLocation{parent: n.loc, index: i}})
+		}
+	case jsonObject:
+		for _, f := range n.node.fields {
+			result = append(result, jpNode{f.value, &jpLocation{parent: n.loc, key: f.name(), isKey: true}})
+		}
+	}
+	return result
+}
+
+// ==================== 查询结构 ====================
+
+type jpQuery struct {
+	absolute bool // $ 开头为绝对路径，@ 开头为相对当前节点
+	segments []*jpSegment
+}
+
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+type jpSelectorKind int
+
+const (
+	jpSelectName jpSelectorKind = iota
+	jpSelectWildcard
+	jpSelectIndex
+	jpSelectSlice
+	jpSelectFilter
+)
+
+type jpSelector struct {
+	kind       jpSelectorKind
+	name       string
+	index      int
+	start, end *int
+	step       int
+	filter     jpExpr
+}
+
+// evaluate 从 root 文档和 current 当前节点开始执行查询
+func (q *jpQuery) evaluate(root, current *jsonNode) []jpNode {
+	start := current
+	if q.absolute {
+		start = root
+	}
+	nodes := []jpNode{{node: start}}
+	for _, seg := range q.segments {
+		var next []jpNode
+		for _, n := range nodes {
+			if seg.descendant {
+				for _, d := range descendants(n) {
+					next = seg.apply(root, d, next)
+				}
+			} else {
+				next = seg.apply(root, n, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants 按先序遍历返回节点本身及全部后代
+func descendants(n jpNode) []jpNode {
+	result := []jpNode{n}
+	for _, c := range n.children() {
+		result = append(result, descendants(c)...)
+	}
+	return result
+}
+
+func (seg *jpSegment) apply(root *jsonNode, n jpNode, out []jpNode) []jpNode {
+	for _, sel := range seg.selectors {
+		out = sel.apply(root, n, out)
+	}
+	return out
+}
+
+func (sel jpSelector) apply(root *jsonNode, n jpNode, out []jpNode) []jpNode {
+	switch sel.kind {
+	case jpSelectName:
+		if n.node.kind == jsonObject {
+			if v := n.node.field(sel.name); v != nil {
+				out = append(out, jpNode{v, &jpLocation{parent: n.loc, key: sel.name, isKey: true}})
+			}
+		}
+	case jpSelectWildcard:
+		out = append(out, n.children()...)
+	case jpSelectIndex:
+		if n.node.kind == jsonArray {
+			i := sel.index
+			if i < 0 {
+				i += len(n.node.items)
+			}
+			if i >= 0 && i < len(n.node.items) {
+				out = append(out, jpNode{n.node.items[i], &jpLocation{parent: n.loc, index: i}})
+			}
+		}
+	case jpSelectSlice:
+		if n.node.kind == jsonArray {
+			for _, i := range sliceIndexes(len(n.node.items), sel.start, sel.end, sel.step) {
+				out = append(out, jpNode{n.node.items[i], &jpLocation{parent: n.loc, index: i}})
+			}
+		}
+	case jpSelectFilter:
+		for _, c := range n.children() {
+			if sel.filter.test(root, c.node) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// sliceIndexes 按 RFC 9535 语义计算切片选中的下标
+func sliceIndexes(length int, start, end *int, step int) []int {
+	if step == 0 {
+		return nil
+	}
+	normalize := func(i int) int {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+	clamp := func(i, lo, hi int) int {
+		return max(lo, min(i, hi))
+	}
+	var result []int
+	if step > 0 {
+		lower, upper := 0, length
+		if start != nil {
+			lower = clamp(normalize(*start), 0, length)
+		}
+		if end != nil {
+			upper = clamp(normalize(*end), 0, length)
+		}
+		for i := lower; i < upper; i += step {
+			result = append(result, i)
+		}
+	} else {
+		upper, lower := length-1, -1
+		if start != nil {
+			upper = clamp(normalize(*start), -1, length-1)
+		}
+		if end != nil {
+			lower = clamp(normalize(*end), -1, length-1)
+		}
+		for i := upper; i > lower; i += step {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// ==================== 过滤表达式 ====================
+
+// jpExpr 过滤表达式节点
+type jpExpr interface {
+	test(root, current *jsonNode) bool
+}
+
+// jpOperand 可参与比较的表达式
+type jpOperand interface {
+	value(root, current *jsonNode) *jsonNode // 结果为空时返回 nil
+}
+
+type jpOr struct{ left, right jpExpr }
+type jpAnd struct{ left, right jpExpr }
+type jpNot struct{ expr jpExpr }
+
+func (e jpOr) test(root, current *jsonNode) bool {
+	return e.left.test(root, current) || e.right.test(root, current)
+}
+
+func (e jpAnd) test(root, current *jsonNode) bool {
+	return e.left.test(root, current) && e.right.test(root, current)
+}
+
+func (e jpNot) test(root, current *jsonNode) bool {
+	return !e.expr.test(root, current)
+}
+
+// jpExists 路径存在性测试
+type jpExists struct{ query *jpQuery }
+
+func (e jpExists) test(root, current *jsonNode) bool {
+	return len(e.query.evaluate(root, current)) > 0
+}
+
+func (e jpExists) value(root, current *jsonNode) *jsonNode {
+	nodes := e.query.evaluate(root, current)
+	if len(nodes) != 1 {
+		return nil
+	}
+	return nodes[0].node
+}
+
+// jpLiteral 字面量
+type jpLiteral struct{ node *jsonNode }
+
+func (e jpLiteral) value(root, current *jsonNode) *jsonNode {
+	return e.node
+}
+
+func (e jpLiteral) test(root, current *jsonNode) bool {
+	return e.node.kind != jsonNull && e.node.raw != "false"
+}
+
+// jpCompare 比较表达式
+type jpCompare struct {
+	op          string
+	left, right jpOperand
+}
+
+func (e jpCompare) test(root, current *jsonNode) bool {
+	left, right := e.left.value(root, current), e.right.value(root, current)
+	switch e.op {
+	case "==":
+		return jpEqual(left, right)
+	case "!=":
+		return !jpEqual(left, right)
+	case "<":
+		return jpLess(left, right)
+	case ">":
+		return jpLess(right, left)
+	case "<=":
+		return jpLess(left, right) || jpEqual(left, right)
+	case ">=":
+		return jpLess(right, left) || jpEqual(left, right)
+	}
+	return false
+}
+
+func jpEqual(a, b *jsonNode) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return jsonEqual(a, b)
+}
+
+func jpLess(a, b *jsonNode) bool {
+	if a == nil || b == nil || a.kind != b.kind {
+		return false
+	}
+	switch a.kind {
+	case jsonNumber:
+		return compareJSONNumbers(a.raw, b.raw) < 0
+	case jsonString:
+		return a.value < b.value
+	}
+	return false
+}
+
+// jpRegex 正则匹配：=~ /pattern/flags、match() 与 search()
+type jpRegex struct {
+	operand jpOperand
+	re      *regexp.Regexp
+}
+
+func (e jpRegex) test(root, current *jsonNode) bool {
+	v := e.operand.value(root, current)
+	return v != nil && v.kind == jsonString && e.re.MatchString(v.value)
+}
+
+// jpLength length() 函数
+type jpLength struct{ operand jpOperand }
+
+func (e jpLength) value(root, current *jsonNode) *jsonNode {
+	v := e.operand.value(root, current)
+	if v == nil {
+		return nil
+	}
+	switch v.kind {
+	case jsonString:
+		return &jsonNode{kind: jsonNumber, raw: strconv.Itoa(utf8.RuneCountInString(v.value))}
+	case jsonArray:
+		return &jsonNode{kind: jsonNumber, raw: strconv.Itoa(len(v.items))}
+	case jsonObject:
+		return &jsonNode{kind: jsonNumber, raw: strconv.Itoa(len(v.keys()))}
+	}
+	return nil
+}
+
+// jpCount count() 函数
+type jpCount struct{ query *jpQuery }
+
+func (e jpCount) value(root, current *jsonNode) *jsonNode {
+	return &jsonNode{kind: jsonNumber, raw: strconv.Itoa(len(e.query.evaluate(root, current)))}
+}
+
+// ==================== 表达式解析 ====================
+
+type jpParser struct {
+	src string
+	pos int
+}
+
+// compileJSONPath 编译 JSONPath 表达式，省略开头的 $ 时视为从根节点开始
+func compileJSONPath(expression string) (*jpQuery, error) {
+	expr := strings.TrimSpace(expression)
+	switch {
+	case expr == "":
+		expr = "$"
+	case expr[0] == '[':
+		expr = "$" + expr
+	case expr[0] == '.':
+		expr = "$" + expr
+	case expr[0] == '@':
+		// @ 只在过滤表达式中表示当前节点，补上 $. 会得到难以理解的错误
+		return nil, (&jpParser{src: expr}).errorf("查询路径不能以 @ 开头，@ 只能在过滤表达式中使用，查询根节点请使用 $%s", expr[1:])
+	case expr[0] != '$':
+		expr = "$." + expr
+	}
+	p := &jpParser{src: expr}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("无法识别的内容 %q", p.src[p.pos:])
+	}
+	return q, nil
+}
+
+// compileJSONPathFilter 编译单独的过滤表达式，如 @.level == 'error'，可带或不带开头的 ?
+func compileJSONPathFilter(expression string) (jpExpr, error) {
+	p := &jpParser{src: strings.TrimPrefix(strings.TrimSpace(expression), "?")}
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return nil, p.errorf("过滤表达式为空")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("无法识别的内容 %q", p.src[p.pos:])
+	}
+	return expr, nil
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("JSONPath 语法错误（位置 %d）: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jpParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *jpParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// parseQuery 解析以 $ 或 @ 开头的路径
+func (p *jpParser) parseQuery() (*jpQuery, error) {
+	q := &jpQuery{}
+	switch p.peek() {
+	case '$':
+		q.absolute = true
+	case '@':
+	default:
+		return nil, p.errorf("路径必须以 $ 或 @ 开头")
+	}
+	p.pos++
+	for {
+		save := p.pos
+		p.skipSpace()
+		switch {
+		case p.consume(".."):
+			seg, err := p.parseDotSegment()
+			if err != nil {
+				return nil, err
+			}
+			seg.descendant = true
+			q.segments = append(q.segments, seg)
+		case p.consume("."):
+			seg, err := p.parseDotSegment()
+			if err != nil {
+				return nil, err
+			}
+			q.segments = append(q.segments, seg)
+		case p.peek() == '[':
+			seg, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			q.segments = append(q.segments, seg)
+		default:
+			p.pos = save
+			return q, nil
+		}
+	}
+}
+
+// parseDotSegment 解析 . 或 .. 之后的成员名、通配符或方括号
+func (p *jpParser) parseDotSegment() (*jpSegment, error) {
+	switch {
+	case p.consume("*"):
+		return &jpSegment{selectors: []jpSelector{{kind: jpSelectWildcard}}}, nil
+	case p.peek() == '[':
+		return p.parseBracket()
+	}
+	name := p.parseName()
+	if name == "" {
+		return nil, p.errorf("缺少成员名")
+	}
+	return &jpSegment{selectors: []jpSelector{{kind: jpSelectName, name: name}}}, nil
+}
+
+// parseName 解析点号表示法中的成员名
+func (p *jpParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !(r == '_' || r == '-' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r) || r >= 0x80) {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos]
+}
+
+// parseBracket 解析方括号内以逗号分隔的选择器
+func (p *jpParser) parseBracket() (*jpSegment, error) {
+	p.pos++ // [
+	seg := &jpSegment{}
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+		p.skipSpace()
+		switch {
+		case p.consume(","):
+		case p.consume("]"):
+			return seg, nil
+		default:
+			return nil, p.errorf("方括号未闭合")
+		}
+	}
+}
+
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return jpSelector{kind: jpSelectWildcard}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseStringLiteral()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		return jpSelector{kind: jpSelectName, name: s}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseOr()
+		if err != nil {
+			return jpSelector{}, err
+		}
+		return jpSelector{kind: jpSelectFilter, filter: expr}, nil
+	case c == '-' || c == ':' || isDigit(c):
+		return p.parseIndexOrSlice()
+	}
+	return jpSelector{}, p.errorf("无效的选择器")
+}
+
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var parts [3]*int
+	n := 0
+	for {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || isDigit(c) {
+			v, err := p.parseInt()
+			if err != nil {
+				return jpSelector{}, err
+			}
+			parts[n] = &v
+		}
+		p.skipSpace()
+		if n < 2 && p.consume(":") {
+			n++
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		if parts[0] == nil {
+			return jpSelector{}, p.errorf("缺少数组下标")
+		}
+		return jpSelector{kind: jpSelectIndex, index: *parts[0]}, nil
+	}
+	step := 1
+	if parts[2] != nil {
+		step = *parts[2]
+	}
+	return jpSelector{kind: jpSelectSlice, start: parts[0], end: parts[1], step: step}, nil
+}
+
+func (p *jpParser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	v, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, p.errorf("无效的整数 %q", p.src[start:p.pos])
+	}
+	return v, nil
+}
+
+// parseStringLiteral 解析单引号或双引号字符串
+func (p *jpParser) parseStringLiteral() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch e := p.src[p.pos]; e {
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+5 > len(p.src) || !isValidHex(p.src[p.pos+1:p.pos+5]) {
+					return "", p.errorf("无效的 Unicode 转义序列")
+				}
+				b.WriteRune(hexRune(p.src[p.pos+1 : p.pos+5]))
+				p.pos += 4
+			default:
+				b.WriteByte(e)
+			}
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("字符串未结束")
+}
+
+func (p *jpParser) parseOr() (jpExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = jpOr{left, right}
+	}
+}
+
+func (p *jpParser) parseAnd() (jpExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.consume("&&") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = jpAnd{left, right}
+	}
+}
+
+func (p *jpParser) parseUnary() (jpExpr, error) {
+	p.skipSpace()
+	if p.peek() == '!' && !strings.HasPrefix(p.src[p.pos:], "!=") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return jpNot{expr}, nil
+	}
+	if p.peek() == '(' {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("括号未闭合")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison 解析比较表达式，单独的路径视为存在性测试
+func (p *jpParser) parseComparison() (jpExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.consume("=~") {
+		p.skipSpace()
+		re, err := p.parseRegexLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return jpRegex{operand: left, re: re}, nil
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			p.skipSpace()
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return jpCompare{op: op, left: left, right: right}, nil
+		}
+	}
+	if expr, ok := left.(jpExpr); ok {
+		return expr, nil
+	}
+	return nil, p.errorf("缺少比较运算符")
+}
+
+// parseRegexLiteral 解析 /pattern/flags 形式的正则表达式
+func (p *jpParser) parseRegexLiteral() (*regexp.Regexp, error) {
+	if !p.consume("/") {
+		return nil, p.errorf("=~ 之后应为 /pattern/ 形式的正则表达式")
+	}
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("正则表达式未结束")
+		}
+		c := p.src[p.pos]
+		p.pos++
+		if c == '/' {
+			break
+		}
+		if c == '\\' && p.pos < len(p.src) && p.src[p.pos] == '/' {
+			c = '/'
+			p.pos++
+		} else if c == '\\' && p.pos < len(p.src) {
+			b.WriteByte(c)
+			c = p.src[p.pos]
+			p.pos++
+		}
+		b.WriteByte(c)
+	}
+	flags := ""
+	for p.pos < len(p.src) && strings.IndexByte("ims", p.src[p.pos]) >= 0 {
+		flags += string(p.src[p.pos])
+		p.pos++
+	}
+	pattern := b.String()
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf("无效的正则表达式: %v", err)
+	}
+	return re, nil
+}
+
+func (p *jpParser) parseOperand() (jpOperand, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		q, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		return jpExists{q}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseStringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return jpLiteral{newJSONString(s)}, nil
+	case c == '-' || isDigit(c):
+		start := p.pos
+		sub := &jsonParser{data: []byte(p.src), pos: p.pos}
+		node, err := sub.parseNumber()
+		if err != nil {
+			return nil, p.errorf("无效的数字")
+		}
+		p.pos = sub.pos
+		if p.pos == start {
+			return nil, p.errorf("无效的数字")
+		}
+		return jpLiteral{node}, nil
+	}
+	for _, lit := range []string{"true", "false", "null"} {
+		if p.consume(lit) {
+			kind := jsonBool
+			if lit == "null" {
+				kind = jsonNull
+			}
+			return jpLiteral{&jsonNode{kind: kind, raw: lit}}, nil
+		}
+	}
+	return p.parseFunction()
+}
+
+// parseFunction 解析 length()、count()、match()、search() 函数调用
+func (p *jpParser) parseFunction() (jpOperand, error) {
+	name := p.parseName()
+	p.skipSpace()
+	if name == "" || !p.consume("(") {
+		return nil, p.errorf("无效的表达式")
+	}
+	p.skipSpace()
+	var result jpOperand
+	switch name {
+	case "length", "value":
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if name == "length" {
+			result = jpLength{arg}
+		} else {
+			result = arg
+		}
+	case "count":
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		q, ok := arg.(jpExists)
+		if !ok {
+			return nil, p.errorf("count() 的参数必须是路径")
+		}
+		result = jpCount{q.query}
+	case "match", "search":
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(",") {
+			return nil, p.errorf("%s() 需要两个参数", name)
+		}
+		p.skipSpace()
+		if c := p.peek(); c != '\'' && c != '"' {
+			return nil, p.errorf("%s() 的第二个参数必须是字符串", name)
+		}
+		pattern, err := p.parseStringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		if name == "match" {
+			pattern = "^(?:" + pattern + ")$"
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, p.errorf("无效的正则表达式: %v", err)
+		}
+		result = jpMatchFunc{jpRegex{operand: arg, re: re}}
+	default:
+		return nil, p.errorf("不支持的函数 %s()", name)
+	}
+	p.skipSpace()
+	if !p.consume(")") {
+		return nil, p.errorf("函数 %s() 缺少右括号", name)
+	}
+	return result, nil
+}
+
+// jpMatchFunc 将 match()/search() 同时作为逻辑表达式与操作数使用
+type jpMatchFunc struct{ jpRegex }
+
+func (e jpMatchFunc) value(root, current *jsonNode) *jsonNode {
+	if e.test(root, current) {
+		return &jsonNode{kind: jsonBool, raw: "true"}
+	}
+	return &jsonNode{kind: jsonBool, raw: "false"}
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryJsonExpressionPrefix(t *testing.T) {
+	const doc = `{"a": {"b": 1}, "list": [{"x": 1}, {"x": 2}]}`
+	tests := []struct {
+		expression string
+		paths      []string
+		err        string // 非空时期望错误信息包含的内容
+	}{
+		{"$.a.b", []string{"$['a']['b']"}, ""},
+		{"a.b", []string{"$['a']['b']"}, ""},
+		{".a.b", []string{"$['a']['b']"}, ""},
+		{"['a']", []string{"$['a']"}, ""},
+		{"", []string{"$"}, ""},
+		{"$.list[?(@.x > 1)]", []string{"$['list'][1]"}, ""},
+		{"@.a", nil, "不能以 @ 开头"},
+		{"  @", nil, "不能以 @ 开头"},
+		{"@.list[?(@.x > 1)]", nil, "$.list[?(@.x > 1)]"},
+	}
+	j := NewJsonProcessor()
+	for _, tt := range tests {
+		matches, err := j.QueryJson(doc, tt.expression)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: got error %v, want one mentioning %q", tt.expression, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.expression, err)
+			continue
+		}
+		var paths []string
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+		}
+		if strings.Join(paths, ",") != strings.Join(tt.paths, ",") {
+			t.Errorf("%q: got %v, want %v", tt.expression, paths, tt.paths)
+		}
+	}
+}
@@ -0,0 +1,604 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JsonRepairFix 修复模式中应用的一处修正
+type JsonRepairFix struct {
+	Line    int    `json:"line"`    // 行号，从 1 开始
+	Column  int    `json:"column"`  // 列号，从 1 开始，按字符计数
+	Message string `json:"message"` // 修正说明
+}
+
+// JsonRepairResult 修复结果
+type JsonRepairResult struct {
+	Result string          `json:"result"` // 修复并格式化后的 JSON
+	Fixes  []JsonRepairFix `json:"fixes"`  // 应用的修正列表，输入本身合法时为空
+}
+
+// RepairJson 宽松解析 JSON5/JSONC 风格及日志中常见的不规范 JSON，修复后按选项格式化输出
+//
+// 支持单引号字符串、未加引号的键、尾随逗号、注释、Python 的 True/False/None、
+// NaN/Infinity、十六进制数字、缺失的逗号与冒号以及被截断的结尾等情况。
+func (j *JsonProcessor) RepairJson(jsonStr string, options JsonFormatOptions) (*JsonRepairResult, error) {
+	w, err := newJSONWriter(options.indent(), options.SortKeys, options.SortRecursive)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &jsonRepairer{data: []byte(jsonStr), fixes: []JsonRepairFix{}}
+	root, err := r.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	// 如果需要解码 Unicode
+	if options.AutoDecodeUnicode {
+		decodeUnicodeNode(root)
+	}
+
+	w.write(root, 0)
+	if options.TrailingNewline {
+		w.b.WriteByte('\n')
+	}
+	return &JsonRepairResult{Result: w.b.String(), Fixes: r.fixes}, nil
+}
+
+// jsonRepairer 宽松的 JSON 解析器，解析时记录所做的修正
+type jsonRepairer struct {
+	data  []byte
+	pos   int
+	depth int // 当前所在的对象和数组层数，上限与 parseJSON 相同
+	fixes []JsonRepairFix
+}
+
+func (r *jsonRepairer) fix(offset int, format string, args ...interface{}) {
+	line, column := lineColumn(r.data, offset)
+	r.fixes = append(r.fixes, JsonRepairFix{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *jsonRepairer) errorf(format string, args ...interface{}) error {
+	return newSyntaxError(r.data, r.pos, "", fmt.Sprintf(format, args...))
+}
+
+func (r *jsonRepairer) eof() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *jsonRepairer) peek() byte {
+	if r.pos < len(r.data) {
+		return r.data[r.pos]
+	}
+	return 0
+}
+
+func (r *jsonRepairer) parse() (*jsonNode, error) {
+	if bytes.HasPrefix(r.data, []byte("\xEF\xBB\xBF")) {
+		r.pos = 3
+	}
+	r.skip()
+	if r.eof() {
+		return nil, r.errorf("输入为空")
+	}
+
+	start, fixes := r.pos, len(r.fixes)
+	if r.valueStart() {
+		root, err := r.parseValue()
+		if err == nil {
+			r.skip()
+			// 开头是简单值且之后仍有对象或数组时，按日志前缀处理
+			if r.eof() || root.kind == jsonArray || root.kind == jsonObject || bytes.IndexAny(r.data[start:], "{[") < 0 {
+				return r.finish(root)
+			}
+		}
+		r.pos, r.fixes = start, r.fixes[:fixes]
+	}
+
+	// 跳过日志前缀或 JSONP 回调名等非 JSON 内容
+	i := bytes.IndexAny(r.data[r.pos:], "{[")
+	if i < 0 {
+		return nil, r.errorf("未找到 JSON 内容")
+	}
+	r.pos += i
+	r.fix(start, "忽略 JSON 之前的内容")
+
+	root, err := r.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	r.skip()
+	return r.finish(root)
+}
+
+// finish 记录顶层值之后被忽略的内容
+func (r *jsonRepairer) finish(root *jsonNode) (*jsonNode, error) {
+	if !r.eof() {
+		r.fix(r.pos, "忽略 JSON 之后的多余内容")
+	}
+	return root, nil
+}
+
+// valueStart 判断当前位置是否可能是一个值的开头
+func (r *jsonRepairer) valueStart() bool {
+	c := r.peek()
+	if strings.IndexByte("{[\"'-+.0123456789", c) >= 0 {
+		return true
+	}
+	word := r.peekWord()
+	switch strings.ToLower(word) {
+	case "true", "false", "null", "none", "nil", "undefined", "nan", "infinity":
+		return true
+	}
+	return false
+}
+
+// skip 跳过空白与注释
+func (r *jsonRepairer) skip() {
+	for !r.eof() {
+		c := r.data[r.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			r.pos++
+		case c == '/' && r.pos+1 < len(r.data) && r.data[r.pos+1] == '/', c == '#':
+			r.fix(r.pos, "删除注释")
+			for !r.eof() && r.data[r.pos] != '\n' {
+				r.pos++
+			}
+		case c == '/' && r.pos+1 < len(r.data) && r.data[r.pos+1] == '*':
+			r.fix(r.pos, "删除注释")
+			end := bytes.Index(r.data[r.pos+2:], []byte("*/"))
+			if end < 0 {
+				r.pos = len(r.data)
+			} else {
+				r.pos += end + 4
+			}
+		default:
+			// 全角空格、不间断空格等 Unicode 空白
+			ch, size := utf8.DecodeRune(r.data[r.pos:])
+			if ch < utf8.RuneSelf || !unicode.IsSpace(ch) {
+				return
+			}
+			r.fix(r.pos, "删除非标准空白字符")
+			r.pos += size
+		}
+	}
+}
+
+func (r *jsonRepairer) parseValue() (*jsonNode, error) {
+	switch c := r.peek(); {
+	case c == '{' || c == '[':
+		if r.depth >= maxJSONDepth {
+			return nil, r.errorf("嵌套超过 %d 层", maxJSONDepth)
+		}
+		r.depth++
+		defer func() { r.depth-- }()
+		if c == '{' {
+			return r.parseObject()
+		}
+		return r.parseArray()
+	case c == '"' || c == '\'':
+		return r.parseString()
+	case c == '-' || c == '+' || c == '.' || isDigit(c):
+		return r.parseNumber()
+	}
+	if r.startsWithRune('“') || r.startsWithRune('‘') {
+		return r.parseString()
+	}
+	return r.parseWord()
+}
+
+func (r *jsonRepairer) startsWithRune(ch rune) bool {
+	first, _ := utf8.DecodeRune(r.data[r.pos:])
+	return first == ch
+}
+
+func (r *jsonRepairer) parseObject() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonObject, fields: []*jsonField{}}
+	r.pos++ // {
+	for {
+		r.skip()
+		if r.eof() {
+			r.fix(r.pos, "补全缺失的 }")
+			return node, nil
+		}
+		switch r.peek() {
+		case '}':
+			r.pos++
+			return node, nil
+		case ']':
+			r.fix(r.pos, "将 ] 替换为 }")
+			r.pos++
+			return node, nil
+		case ',':
+			r.fix(r.pos, "删除多余的逗号")
+			r.pos++
+			continue
+		}
+
+		key, err := r.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		r.skip()
+		switch r.peek() {
+		case ':':
+			r.pos++
+		case '=':
+			r.fix(r.pos, "将 = 替换为冒号")
+			r.pos++
+		default:
+			r.fix(r.pos, "补全缺失的冒号")
+		}
+		r.skip()
+
+		var value *jsonNode
+		if c := r.peek(); r.eof() || c == ',' || c == '}' || c == ']' {
+			r.fix(r.pos, "为键 %s 补全缺失的值 null", key.raw)
+			value = &jsonNode{kind: jsonNull, raw: "null"}
+		} else if value, err = r.parseValue(); err != nil {
+			return nil, err
+		}
+		node.fields = append(node.fields, &jsonField{key: key, value: value})
+
+		if err := r.parseSeparator(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *jsonRepairer) parseArray() (*jsonNode, error) {
+	node := &jsonNode{kind: jsonArray, items: []*jsonNode{}}
+	r.pos++ // [
+	for {
+		r.skip()
+		if r.eof() {
+			r.fix(r.pos, "补全缺失的 ]")
+			return node, nil
+		}
+		switch r.peek() {
+		case ']':
+			r.pos++
+			return node, nil
+		case '}':
+			r.fix(r.pos, "将 } 替换为 ]")
+			r.pos++
+			return node, nil
+		case ',':
+			r.fix(r.pos, "删除多余的逗号")
+			r.pos++
+			continue
+		}
+
+		value, err := r.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.items = append(node.items, value)
+
+		if err := r.parseSeparator(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseSeparator 解析成员之间的逗号，处理尾随逗号与缺失的逗号
+func (r *jsonRepairer) parseSeparator() error {
+	r.skip()
+	if r.eof() {
+		return nil
+	}
+	switch c := r.peek(); {
+	case c == ',':
+		comma := r.pos
+		r.pos++
+		r.skip()
+		if c := r.peek(); c == '}' || c == ']' || r.eof() {
+			r.fix(comma, "删除尾随逗号")
+		}
+	case c == '}' || c == ']':
+	case c == ':':
+		return r.errorf("意外的冒号")
+	default:
+		r.fix(r.pos, "补全缺失的逗号")
+	}
+	return nil
+}
+
+// parseKey 解析对象键，支持引号字符串、未加引号的标识符和数字
+func (r *jsonRepairer) parseKey() (*jsonNode, error) {
+	if c := r.peek(); c == '"' || c == '\'' || r.startsWithRune('“') || r.startsWithRune('‘') {
+		return r.parseString()
+	}
+	start := r.pos
+	for !r.eof() {
+		ch, size := utf8.DecodeRune(r.data[r.pos:])
+		if !(ch == '_' || ch == '$' || ch == '-' || ch == '.' || ch == '@' ||
+			unicode.IsLetter(ch) || unicode.IsDigit(ch) || (ch >= utf8.RuneSelf && !unicode.IsSpace(ch) && !unicode.IsPunct(ch))) {
+			break
+		}
+		r.pos += size
+	}
+	if r.pos == start {
+		return nil, r.errorf("无法识别的字符 %q", r.peekRune())
+	}
+	r.fix(start, "为键 %s 添加双引号", string(r.data[start:r.pos]))
+	return newJSONString(string(r.data[start:r.pos])), nil
+}
+
+func (r *jsonRepairer) peekRune() rune {
+	ch, _ := utf8.DecodeRune(r.data[r.pos:])
+	return ch
+}
+
+// parseString 解析字符串，支持单引号与中文引号，修正未转义的控制字符与无效转义
+func (r *jsonRepairer) parseString() (*jsonNode, error) {
+	start := r.pos
+	open, size := utf8.DecodeRune(r.data[r.pos:])
+	closeQuote := open
+	switch open {
+	case '\'':
+		r.fix(start, "将单引号字符串替换为双引号")
+	case '“':
+		closeQuote = '”'
+		r.fix(start, "将中文引号替换为双引号")
+	case '‘':
+		closeQuote = '’'
+		r.fix(start, "将中文引号替换为双引号")
+	}
+	r.pos += size
+
+	var b strings.Builder
+	changed := open != '"'
+	for {
+		if r.eof() {
+			r.fix(r.pos, "补全字符串结尾的引号")
+			return newJSONString(b.String()), nil
+		}
+		ch, size := utf8.DecodeRune(r.data[r.pos:])
+		switch {
+		case ch == closeQuote:
+			r.pos += size
+			if !changed {
+				raw := string(r.data[start:r.pos])
+				return &jsonNode{kind: jsonString, raw: raw, value: unquoteJSONString(raw)}, nil
+			}
+			return newJSONString(b.String()), nil
+		case ch == '\\':
+			if !r.parseEscape(&b) {
+				changed = true
+			}
+		case ch < 0x20:
+			r.fix(r.pos, "转义字符串中的控制字符")
+			changed = true
+			b.WriteRune(ch)
+			r.pos += size
+		case ch == utf8.RuneError && size == 1:
+			r.fix(r.pos, "替换无效的 UTF-8 字节")
+			changed = true
+			b.WriteRune(utf8.RuneError)
+			r.pos++
+		default:
+			b.WriteRune(ch)
+			r.pos += size
+		}
+	}
+}
+
+// parseEscape 解析转义序列并写入解码后的字符，返回转义是否符合 JSON 规范
+func (r *jsonRepairer) parseEscape(b *strings.Builder) bool {
+	start := r.pos
+	r.pos++ // 反斜杠
+	if r.eof() {
+		return false
+	}
+	c := r.data[r.pos]
+	switch c {
+	case '"', '\\', '/':
+		b.WriteByte(c)
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
+		if r.pos+5 <= len(r.data) && isValidHex(string(r.data[r.pos+1:r.pos+5])) {
+			// 借用严格解码器处理代理对
+			end := r.pos + 5
+			if utf16.IsSurrogate(hexRune(string(r.data[r.pos+1:end]))) && end+6 <= len(r.data) && r.data[end] == '\\' && r.data[end+1] == 'u' && isValidHex(string(r.data[end+2:end+6])) {
+				end += 6
+			}
+			b.WriteString(unquoteJSONString(`"` + string(r.data[start:end]) + `"`))
+			r.pos = end
+			return true
+		}
+		r.fix(start, "保留无效的 Unicode 转义")
+		b.WriteString(`\u`)
+		r.pos++
+		return false
+	case 'x':
+		if r.pos+3 <= len(r.data) && isValidHex(string(r.data[r.pos+1:r.pos+3])) {
+			r.fix(start, "将 \\x 转义替换为对应字符")
+			b.WriteRune(hexRune("00" + string(r.data[r.pos+1:r.pos+3])))
+			r.pos += 3
+			return false
+		}
+		r.fix(start, "保留无效的转义字符")
+		b.WriteString(`\x`)
+	case '\'':
+		b.WriteByte('\'')
+		r.pos++
+		return false
+	case '\n':
+		r.fix(start, "删除字符串中的续行符")
+		r.pos++
+		return false
+	default:
+		// 保留反斜杠，其后的字符按普通字符处理
+		r.fix(start, "保留无效的转义字符")
+		b.WriteByte('\\')
+		return false
+	}
+	r.pos++
+	return c != 'x'
+}
+
+// parseNumber 解析数字，修正正号、前导零、省略整数或小数部分以及十六进制写法
+func (r *jsonRepairer) parseNumber() (*jsonNode, error) {
+	start := r.pos
+	for !r.eof() && strings.IndexByte("0123456789abcdefABCDEFxX+-._", r.data[r.pos]) >= 0 {
+		r.pos++
+	}
+	text := string(r.data[start:r.pos])
+
+	// 符号之后是字母，例如 -Infinity
+	sign := strings.TrimLeft(text, "+-")
+	if sign == "" && !r.eof() && unicode.IsLetter(r.peekRune()) {
+		r.pos = start
+		return r.parseWord()
+	}
+
+	if num, ok := repairNumber(text); ok {
+		if num != text {
+			r.fix(start, "将数字 %s 修正为 %s", text, num)
+		}
+		return &jsonNode{kind: jsonNumber, raw: num}, nil
+	}
+
+	// 不是数字时按未加引号的字符串处理，例如日期 2024-01-01
+	r.pos = start
+	return r.parseWord()
+}
+
+// repairNumber 将宽松的数字写法规范化为 JSON 数字，合法的数字字面量原样保留
+func repairNumber(text string) (string, bool) {
+	if isJSONNumber(text) {
+		return text, true
+	}
+	s := strings.ReplaceAll(text, "_", "")
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if s == "" {
+		return "", false
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		v, ok := new(big.Int).SetString(s[2:], 16)
+		if !ok {
+			return "", false
+		}
+		if neg {
+			v.Neg(v)
+		}
+		return v.String(), true
+	}
+
+	mantissa, exponent := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exponent = s[:i], s[i+1:]
+		if exponent == "" || exponent == "+" || exponent == "-" {
+			exponent = ""
+		} else if !isDigits(strings.TrimLeft(exponent, "+-")) || strings.Count(exponent, "-")+strings.Count(exponent, "+") > 1 {
+			return "", false
+		}
+	}
+	intPart, fracPart, hasDot := strings.Cut(mantissa, ".")
+	if (intPart != "" && !isDigits(intPart)) || (fracPart != "" && !isDigits(fracPart)) || (intPart == "" && fracPart == "") {
+		return "", false
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	result := intPart
+	if hasDot && fracPart != "" {
+		result += "." + fracPart
+	}
+	if exponent != "" {
+		result += "e" + exponent
+	}
+	if neg {
+		result = "-" + result
+	}
+	return result, true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// peekWord 返回当前位置的标识符
+func (r *jsonRepairer) peekWord() string {
+	end := r.pos
+	if end < len(r.data) && (r.data[end] == '-' || r.data[end] == '+') {
+		end++
+	}
+	for end < len(r.data) {
+		ch, size := utf8.DecodeRune(r.data[end:])
+		if !(ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)) {
+			break
+		}
+		end += size
+	}
+	return string(r.data[r.pos:end])
+}
+
+// parseWord 解析字面量，修正 Python/JavaScript 写法，其余内容视为未加引号的字符串
+func (r *jsonRepairer) parseWord() (*jsonNode, error) {
+	start := r.pos
+	word := r.peekWord()
+	var node *jsonNode
+	switch strings.ToLower(word) {
+	case "true":
+		node = &jsonNode{kind: jsonBool, raw: "true"}
+	case "false":
+		node = &jsonNode{kind: jsonBool, raw: "false"}
+	case "null", "none", "nil", "undefined":
+		node = &jsonNode{kind: jsonNull, raw: "null"}
+	case "nan", "infinity", "-infinity", "+infinity":
+		r.pos += len(word)
+		r.fix(start, "JSON 不支持 %s，已替换为 null", word)
+		return &jsonNode{kind: jsonNull, raw: "null"}, nil
+	}
+	if node != nil {
+		r.pos += len(word)
+		if word != node.raw {
+			r.fix(start, "将 %s 替换为 %s", word, node.raw)
+		}
+		return node, nil
+	}
+
+	// 未加引号的字符串读取到分隔符或行尾为止
+	for !r.eof() && strings.IndexByte(",}]\n\r", r.data[r.pos]) < 0 {
+		r.pos++
+	}
+	text := strings.TrimSpace(string(r.data[start:r.pos]))
+	if text == "" {
+		return nil, r.errorf("无法识别的字符 %q", r.peekRune())
+	}
+	r.fix(start, "为未加引号的字符串 %s 添加双引号", text)
+	return newJSONString(text), nil
+}
@@ -0,0 +1,799 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// JsonSchemaViolation JSON Schema 校验失败项
+type JsonSchemaViolation struct {
+	InstancePath string `json:"instancePath"` // 文档中出错的位置（JSON Pointer）
+	SchemaPath   string `json:"schemaPath"`   // 对应的 schema 关键字位置（JSON Pointer）
+	Keyword      string `json:"keyword"`      // 校验失败的关键字
+	Message      string `json:"message"`      // 错误描述
+}
+
+// ValidateJsonSchema 使用 JSON Schema（draft-07、2019-09 或 2020-12，未声明 $schema 时按 2020-12）校验文档，返回全部违规项，文档合法时返回空列表
+func (j *JsonProcessor) ValidateJsonSchema(doc string, schema string) ([]JsonSchemaViolation, error) {
+	instance, err := parseJSON([]byte(doc))
+	if err != nil {
+		return nil, fmt.Errorf("JSON 文档无效: %w", err)
+	}
+	root, err := parseJSON([]byte(schema))
+	if err != nil {
+		return nil, fmt.Errorf("JSON Schema 无效: %w", err)
+	}
+
+	v, err := newSchemaValidator(root)
+	if err != nil {
+		return nil, err
+	}
+	result := v.validate(root, "", instance, "")
+	if v.err != nil {
+		return nil, v.err
+	}
+	if result.errors == nil {
+		return []JsonSchemaViolation{}, nil
+	}
+	return result.errors, nil
+}
+
+// schemaDefaultBase schema 未声明 $id 时使用的基准 URI
+const schemaDefaultBase = "file:///schema.json"
+
+// schemaValidator JSON Schema 校验器
+type schemaValidator struct {
+	draft   int                    // 7 表示 draft-07，2019 表示 2019-09，2020 表示 2020-12
+	ids     map[string]*jsonNode   // 去掉片段的绝对 URI → schema
+	anchors map[string]*jsonNode   // URI#anchor → schema
+	bases   map[*jsonNode]*url.URL // schema 节点 → 所在的基准 URI
+	regexps map[string]*regexp.Regexp
+	active  map[schemaVisit]bool // 正在校验的 schema 与实例组合，用于检测循环引用
+	err     error
+}
+
+type schemaVisit struct {
+	schema   *jsonNode
+	instance *jsonNode
+}
+
+// schemaResult 单个 schema 的校验结果与注解
+type schemaResult struct {
+	errors   []JsonSchemaViolation
+	props    map[string]bool // 已评估的对象属性
+	items    int             // 已评估的数组前缀长度
+	allItems bool            // 全部数组元素已评估
+	contains map[int]bool    // contains 匹配的数组下标
+}
+
+func (r *schemaResult) valid() bool {
+	return len(r.errors) == 0
+}
+
+// merge 合并子 schema 的注解，只有校验通过的子 schema 才产生注解
+func (r *schemaResult) merge(sub *schemaResult) {
+	if !sub.valid() {
+		return
+	}
+	for k := range sub.props {
+		r.props[k] = true
+	}
+	r.items = max(r.items, sub.items)
+	r.allItems = r.allItems || sub.allItems
+	for k := range sub.contains {
+		r.contains[k] = true
+	}
+}
+
+func newSchemaValidator(root *jsonNode) (*schemaValidator, error) {
+	v := &schemaValidator{
+		draft:   2020,
+		ids:     map[string]*jsonNode{},
+		anchors: map[string]*jsonNode{},
+		bases:   map[*jsonNode]*url.URL{},
+		regexps: map[string]*regexp.Regexp{},
+		active:  map[schemaVisit]bool{},
+	}
+	if root.kind == jsonObject {
+		if s := root.field("$schema"); s != nil && s.kind == jsonString {
+			switch {
+			case strings.Contains(s.value, "draft-07"), strings.Contains(s.value, "draft-06"), strings.Contains(s.value, "draft-04"):
+				v.draft = 7
+			case strings.Contains(s.value, "2019-09"):
+				v.draft = 2019
+			case strings.Contains(s.value, "2020-12"):
+			default:
+				return nil, fmt.Errorf("不支持的 $schema 版本: %s", s.value)
+			}
+		}
+	}
+	base, _ := url.Parse(schemaDefaultBase)
+	v.ids[schemaDefaultBase] = root
+	v.index(root, base)
+	return v, nil
+}
+
+// index 遍历 schema，记录每个子 schema 的基准 URI 以及 $id 与 $anchor
+func (v *schemaValidator) index(n *jsonNode, base *url.URL) {
+	switch n.kind {
+	case jsonArray:
+		for _, item := range n.items {
+			v.index(item, base)
+		}
+		return
+	case jsonObject:
+	default:
+		return
+	}
+
+	if id := n.field("$id"); id != nil && id.kind == jsonString {
+		if v.draft == 7 && strings.HasPrefix(id.value, "#") {
+			// draft-07 使用 "$id": "#name" 声明锚点
+			v.anchors[withoutFragment(base)+id.value] = n
+		} else if ref, err := url.Parse(id.value); err == nil {
+			base = base.ResolveReference(ref)
+			v.ids[withoutFragment(base)] = n
+		}
+	}
+	for _, name := range []string{"$anchor", "$dynamicAnchor"} {
+		if a := n.field(name); a != nil && a.kind == jsonString {
+			v.anchors[withoutFragment(base)+"#"+a.value] = n
+		}
+	}
+	v.bases[n] = base
+
+	for _, f := range n.fields {
+		switch f.name() {
+		case "enum", "const", "examples", "default":
+			// 这些关键字的值是实例数据而非 schema
+			continue
+		}
+		v.index(f.value, base)
+	}
+}
+
+func withoutFragment(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	return c.String()
+}
+
+// resolveRef 根据当前 schema 的基准 URI 解析 $ref
+func (v *schemaValidator) resolveRef(from *jsonNode, ref string) (*jsonNode, error) {
+	base := v.bases[from]
+	if base == nil {
+		base, _ = url.Parse(schemaDefaultBase)
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 $ref %q", ref)
+	}
+	target := base.ResolveReference(parsed)
+	doc := v.ids[withoutFragment(target)]
+	if doc == nil {
+		return nil, fmt.Errorf("无法解析 $ref %q，不支持引用外部 schema", ref)
+	}
+
+	fragment := target.Fragment
+	switch {
+	case fragment == "":
+		return doc, nil
+	case strings.HasPrefix(fragment, "/"):
+		tokens, err := parseJSONPointer(fragment)
+		if err != nil {
+			return nil, err
+		}
+		node, err := pointerGet(doc, tokens)
+		if err != nil {
+			return nil, fmt.Errorf("无法解析 $ref %q: %v", ref, err)
+		}
+		return node, nil
+	default:
+		if node := v.anchors[withoutFragment(target)+"#"+fragment]; node != nil {
+			return node, nil
+		}
+		return nil, fmt.Errorf("无法解析 $ref %q，未找到锚点 %s", ref, fragment)
+	}
+}
+
+// compileRegexp 编译并缓存正则表达式
+func (v *schemaValidator) compileRegexp(pattern string) *regexp.Regexp {
+	if re, ok := v.regexps[pattern]; ok {
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil && v.err == nil {
+		v.err = fmt.Errorf("schema 中的正则表达式 %q 无效: %v", pattern, err)
+	}
+	v.regexps[pattern] = re
+	return re
+}
+
+func (v *schemaValidator) fail(r *schemaResult, instPath, schemaPath, keyword, format string, args ...interface{}) {
+	r.errors = append(r.errors, JsonSchemaViolation{
+		InstancePath: instPath,
+		SchemaPath:   schemaPath + "/" + keyword,
+		Keyword:      keyword,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+// validate 使用 schema 校验实例，schemaPath 与 instPath 分别为二者当前的 JSON Pointer
+func (v *schemaValidator) validate(schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) *schemaResult {
+	r := &schemaResult{props: map[string]bool{}, contains: map[int]bool{}}
+	if v.err != nil {
+		return r
+	}
+
+	switch schema.kind {
+	case jsonBool:
+		if schema.raw == "false" {
+			r.errors = append(r.errors, JsonSchemaViolation{
+				InstancePath: instPath, SchemaPath: schemaPath, Keyword: "false", Message: "schema 为 false，不允许任何值",
+			})
+		}
+		return r
+	case jsonObject:
+	default:
+		v.err = fmt.Errorf("schema %s 必须是对象或布尔值", displayPointer(schemaPath))
+		return r
+	}
+
+	// 同一 schema 重复校验同一实例说明存在无限循环引用
+	visit := schemaVisit{schema, inst}
+	if v.active[visit] {
+		return r
+	}
+	v.active[visit] = true
+	defer delete(v.active, visit)
+
+	if ref := schema.field("$ref"); ref != nil && ref.kind == jsonString {
+		v.applyRef(r, schema, schemaPath, "$ref", ref.value, inst, instPath)
+		// draft-07 中 $ref 会忽略同级的其他关键字
+		if v.draft == 7 {
+			return r
+		}
+	}
+	for _, name := range []string{"$dynamicRef", "$recursiveRef"} {
+		if ref := schema.field(name); ref != nil && ref.kind == jsonString {
+			v.applyRef(r, schema, schemaPath, name, ref.value, inst, instPath)
+		}
+	}
+
+	v.validateGeneric(r, schema, schemaPath, inst, instPath)
+	v.validateCombinators(r, schema, schemaPath, inst, instPath)
+	switch inst.kind {
+	case jsonNumber:
+		v.validateNumber(r, schema, schemaPath, inst, instPath)
+	case jsonString:
+		v.validateString(r, schema, schemaPath, inst, instPath)
+	case jsonArray:
+		v.validateArray(r, schema, schemaPath, inst, instPath)
+	case jsonObject:
+		v.validateObject(r, schema, schemaPath, inst, instPath)
+	}
+	return r
+}
+
+func (v *schemaValidator) applyRef(r *schemaResult, schema *jsonNode, schemaPath, keyword, ref string, inst *jsonNode, instPath string) {
+	target, err := v.resolveRef(schema, ref)
+	if err != nil {
+		if v.err == nil {
+			v.err = err
+		}
+		return
+	}
+	sub := v.validate(target, schemaPath+"/"+keyword, inst, instPath)
+	r.errors = append(r.errors, sub.errors...)
+	r.merge(sub)
+}
+
+// ==================== 通用关键字 ====================
+
+// schemaTypeName 返回实例的 JSON Schema 类型名
+func schemaTypeName(n *jsonNode) string {
+	switch n.kind {
+	case jsonNull:
+		return "null"
+	case jsonBool:
+		return "boolean"
+	case jsonNumber:
+		return "number"
+	case jsonString:
+		return "string"
+	case jsonArray:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// isJSONInteger 判断数字是否为整数（1.0 也视为整数）
+func isJSONInteger(n *jsonNode) bool {
+	if r, ok := parseJSONRat(n.raw); ok {
+		return r.IsInt()
+	}
+	f, _, err := big.ParseFloat(n.raw, 10, 1024, big.ToNearestEven)
+	return err == nil && f.IsInt()
+}
+
+func schemaTypeMatches(inst *jsonNode, typ string) bool {
+	switch typ {
+	case "integer":
+		return inst.kind == jsonNumber && isJSONInteger(inst)
+	case "number":
+		return inst.kind == jsonNumber
+	default:
+		return schemaTypeName(inst) == typ
+	}
+}
+
+func (v *schemaValidator) validateGeneric(r *schemaResult, schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) {
+	if t := schema.field("type"); t != nil {
+		var types []string
+		switch t.kind {
+		case jsonString:
+			types = []string{t.value}
+		case jsonArray:
+			for _, item := range t.items {
+				types = append(types, item.value)
+			}
+		}
+		matched := false
+		for _, typ := range types {
+			if schemaTypeMatches(inst, typ) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(r, instPath, schemaPath, "type", "类型应为 %s，实际为 %s", strings.Join(types, " 或 "), schemaTypeName(inst))
+		}
+	}
+
+	if e := schema.field("enum"); e != nil && e.kind == jsonArray {
+		found := false
+		for _, item := range e.items {
+			if jsonEqual(item, inst) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			var values []string
+			for _, item := range e.items {
+				values = append(values, abbreviate(encodeJSON(item, "")))
+			}
+			v.fail(r, instPath, schemaPath, "enum", "值必须是以下之一: %s", strings.Join(values, ", "))
+		}
+	}
+
+	if c := schema.field("const"); c != nil && !jsonEqual(c, inst) {
+		v.fail(r, instPath, schemaPath, "const", "值必须等于 %s", abbreviate(encodeJSON(c, "")))
+	}
+}
+
+// ==================== 组合关键字 ====================
+
+func (v *schemaValidator) validateCombinators(r *schemaResult, schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) {
+	if all := schema.field("allOf"); all != nil && all.kind == jsonArray {
+		for i, sub := range all.items {
+			res := v.validate(sub, fmt.Sprintf("%s/allOf/%d", schemaPath, i), inst, instPath)
+			r.errors = append(r.errors, res.errors...)
+			r.merge(res)
+		}
+	}
+
+	if anyOf := schema.field("anyOf"); anyOf != nil && anyOf.kind == jsonArray {
+		matched := 0
+		for i, sub := range anyOf.items {
+			res := v.validate(sub, fmt.Sprintf("%s/anyOf/%d", schemaPath, i), inst, instPath)
+			if res.valid() {
+				matched++
+				r.merge(res)
+			}
+		}
+		if matched == 0 {
+			v.fail(r, instPath, schemaPath, "anyOf", "不满足 anyOf 中的任何一个 schema")
+		}
+	}
+
+	if oneOf := schema.field("oneOf"); oneOf != nil && oneOf.kind == jsonArray {
+		var matched []string
+		for i, sub := range oneOf.items {
+			res := v.validate(sub, fmt.Sprintf("%s/oneOf/%d", schemaPath, i), inst, instPath)
+			if res.valid() {
+				matched = append(matched, strconv.Itoa(i))
+				r.merge(res)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			v.fail(r, instPath, schemaPath, "oneOf", "不满足 oneOf 中的任何一个 schema")
+		case 1:
+		default:
+			v.fail(r, instPath, schemaPath, "oneOf", "同时满足 oneOf 中的多个 schema（第 %s 个），只能满足一个", strings.Join(matched, "、"))
+		}
+	}
+
+	if not := schema.field("not"); not != nil {
+		if v.validate(not, schemaPath+"/not", inst, instPath).valid() {
+			v.fail(r, instPath, schemaPath, "not", "值不能满足 not 中的 schema")
+		}
+	}
+
+	if cond := schema.field("if"); cond != nil {
+		res := v.validate(cond, schemaPath+"/if", inst, instPath)
+		branch := "else"
+		if res.valid() {
+			r.merge(res)
+			branch = "then"
+		}
+		if sub := schema.field(branch); sub != nil {
+			res := v.validate(sub, schemaPath+"/"+branch, inst, instPath)
+			r.errors = append(r.errors, res.errors...)
+			r.merge(res)
+		}
+	}
+}
+
+// ==================== 数字 ====================
+
+// schemaNumber 读取 schema 中的数字关键字
+func schemaNumber(schema *jsonNode, name string) *jsonNode {
+	if n := schema.field(name); n != nil && n.kind == jsonNumber {
+		return n
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateNumber(r *schemaResult, schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) {
+	if m := schemaNumber(schema, "multipleOf"); m != nil {
+		x, okX := parseJSONRat(inst.raw)
+		d, okD := parseJSONRat(m.raw)
+		if okX && okD && d.Sign() > 0 && !new(big.Rat).Quo(x, d).IsInt() {
+			v.fail(r, instPath, schemaPath, "multipleOf", "必须是 %s 的倍数", m.raw)
+		}
+	}
+
+	// draft-04 中 exclusiveMaximum/exclusiveMinimum 为布尔值
+	exclusiveMax, exclusiveMin := false, false
+	if b := schema.field("exclusiveMaximum"); b != nil && b.kind == jsonBool {
+		exclusiveMax = b.raw == "true"
+	}
+	if b := schema.field("exclusiveMinimum"); b != nil && b.kind == jsonBool {
+		exclusiveMin = b.raw == "true"
+	}
+
+	if m := schemaNumber(schema, "maximum"); m != nil {
+		if c := compareJSONNumbers(inst.raw, m.raw); exclusiveMax && c >= 0 {
+			v.fail(r, instPath, schemaPath, "maximum", "必须小于 %s", m.raw)
+		} else if c > 0 {
+			v.fail(r, instPath, schemaPath, "maximum", "必须小于或等于 %s", m.raw)
+		}
+	}
+	if m := schemaNumber(schema, "exclusiveMaximum"); m != nil && compareJSONNumbers(inst.raw, m.raw) >= 0 {
+		v.fail(r, instPath, schemaPath, "exclusiveMaximum", "必须小于 %s", m.raw)
+	}
+	if m := schemaNumber(schema, "minimum"); m != nil {
+		if c := compareJSONNumbers(inst.raw, m.raw); exclusiveMin && c <= 0 {
+			v.fail(r, instPath, schemaPath, "minimum", "必须大于 %s", m.raw)
+		} else if c < 0 {
+			v.fail(r, instPath, schemaPath, "minimum", "必须大于或等于 %s", m.raw)
+		}
+	}
+	if m := schemaNumber(schema, "exclusiveMinimum"); m != nil && compareJSONNumbers(inst.raw, m.raw) <= 0 {
+		v.fail(r, instPath, schemaPath, "exclusiveMinimum", "必须大于 %s", m.raw)
+	}
+}
+
+// schemaInt 读取 schema 中的非负整数关键字
+func schemaInt(schema *jsonNode, name string) (int, bool) {
+	n := schemaNumber(schema, name)
+	if n == nil {
+		return 0, false
+	}
+	r, ok := parseJSONRat(n.raw)
+	if !ok || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+	return int(r.Num().Int64()), true
+}
+
+// ==================== 字符串 ====================
+
+func (v *schemaValidator) validateString(r *schemaResult, schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) {
+	length := utf8.RuneCountInString(inst.value)
+	if n, ok := schemaInt(schema, "maxLength"); ok && length > n {
+		v.fail(r, instPath, schemaPath, "maxLength", "长度不能超过 %d 个字符，实际为 %d", n, length)
+	}
+	if n, ok := schemaInt(schema, "minLength"); ok && length < n {
+		v.fail(r, instPath, schemaPath, "minLength", "长度不能少于 %d 个字符，实际为 %d", n, length)
+	}
+	if p := schema.field("pattern"); p != nil && p.kind == jsonString {
+		if re := v.compileRegexp(p.value); re != nil && !re.MatchString(inst.value) {
+			v.fail(r, instPath, schemaPath, "pattern", "不匹配正则表达式 %s", p.value)
+		}
+	}
+	if f := schema.field("format"); f != nil && f.kind == jsonString {
+		if ok, known := checkFormat(f.value, inst.value); known && !ok {
+			v.fail(r, instPath, schemaPath, "format", "不是有效的 %s 格式", f.value)
+		}
+	}
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*\.?$`)
+	durationPattern = regexp.MustCompile(`^P(?:\d+W|(?:\d+Y)?(?:\d+M)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+(?:\.\d+)?S)?)?)$`)
+	timePattern     = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2})(\.\d+)?(?i:z|[+-]\d{2}:\d{2})$`)
+	pointerPattern  = regexp.MustCompile(`^(/([^~/]|~[01])*)*$`)
+)
+
+// checkFormat 校验字符串格式，第二个返回值表示是否支持该格式
+func checkFormat(format, s string) (ok bool, known bool) {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s))
+		return err == nil, true
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil, true
+	case "time":
+		m := timePattern.FindStringSubmatch(s)
+		if m == nil {
+			return false, true
+		}
+		h, _ := strconv.Atoi(m[1])
+		mi, _ := strconv.Atoi(m[2])
+		sec, _ := strconv.Atoi(m[3])
+		return h < 24 && mi < 60 && sec <= 60, true
+	case "duration":
+		return s != "P" && !strings.HasSuffix(s, "T") && durationPattern.MatchString(s), true
+	case "email", "idn-email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s && addr.Name == "", true
+	case "hostname", "idn-hostname":
+		return len(s) <= 253 && hostnamePattern.MatchString(s), true
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":"), true
+	case "ipv6":
+		ip := net.ParseIP(s)
+		return ip != nil && strings.Contains(s, ":"), true
+	case "uri", "iri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != "", true
+	case "uri-reference", "iri-reference", "uri-template":
+		_, err := url.Parse(s)
+		return err == nil, true
+	case "uuid":
+		return uuidPattern.MatchString(s), true
+	case "regex":
+		_, err := regexp.Compile(s)
+		return err == nil, true
+	case "json-pointer":
+		return pointerPattern.MatchString(s), true
+	}
+	return true, false
+}
+
+// ==================== 数组 ====================
+
+func (v *schemaValidator) validateArray(r *schemaResult, schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) {
+	items := inst.items
+	if n, ok := schemaInt(schema, "maxItems"); ok && len(items) > n {
+		v.fail(r, instPath, schemaPath, "maxItems", "元素数量不能超过 %d，实际为 %d", n, len(items))
+	}
+	if n, ok := schemaInt(schema, "minItems"); ok && len(items) < n {
+		v.fail(r, instPath, schemaPath, "minItems", "元素数量不能少于 %d，实际为 %d", n, len(items))
+	}
+	if u := schema.field("uniqueItems"); u != nil && u.raw == "true" {
+	unique:
+		for i := range items {
+			for k := i + 1; k < len(items); k++ {
+				if jsonEqual(items[i], items[k]) {
+					v.fail(r, instPath, schemaPath, "uniqueItems", "第 %d 个与第 %d 个元素重复", i, k)
+					break unique
+				}
+			}
+		}
+	}
+
+	// 元组形式：2020-12 的 prefixItems 或 draft-07、2019-09 数组形式的 items
+	var prefix, rest *jsonNode
+	prefixName, restName := "prefixItems", "items"
+	if v.draft < 2020 {
+		prefixName = "items"
+		if it := schema.field("items"); it != nil && it.kind == jsonArray {
+			prefix, rest, restName = it, schema.field("additionalItems"), "additionalItems"
+		} else {
+			rest = it
+		}
+	} else {
+		prefix, rest = schema.field("prefixItems"), schema.field("items")
+		if rest != nil && rest.kind == jsonArray {
+			if v.err == nil {
+				v.err = fmt.Errorf("schema %s 不支持数组形式的 items：2020-12 中元组应使用 prefixItems，或将 $schema 声明为 draft-07 或 2019-09", displayPointer(schemaPath+"/items"))
+			}
+			return
+		}
+	}
+	start := 0
+	if prefix != nil && prefix.kind == jsonArray {
+		for i, sub := range prefix.items {
+			if i >= len(items) {
+				break
+			}
+			res := v.validate(sub, fmt.Sprintf("%s/%s/%d", schemaPath, prefixName, i), items[i], fmt.Sprintf("%s/%d", instPath, i))
+			r.errors = append(r.errors, res.errors...)
+			start = i + 1
+		}
+		r.items = max(r.items, start)
+	}
+	if rest != nil && rest.kind != jsonArray {
+		for i := start; i < len(items); i++ {
+			path := fmt.Sprintf("%s/%d", instPath, i)
+			res := v.validate(rest, schemaPath+"/"+restName, items[i], path)
+			if rest.kind == jsonBool && !res.valid() {
+				v.fail(r, path, schemaPath, restName, "不允许额外的数组元素，最多 %d 个", start)
+			} else {
+				r.errors = append(r.errors, res.errors...)
+			}
+		}
+		r.allItems = true
+	}
+
+	if contains := schema.field("contains"); contains != nil {
+		count := 0
+		for i, item := range items {
+			if v.validate(contains, schemaPath+"/contains", item, fmt.Sprintf("%s/%d", instPath, i)).valid() {
+				count++
+				r.contains[i] = true
+			}
+		}
+		minContains, hasMin := schemaInt(schema, "minContains")
+		if !hasMin {
+			minContains = 1
+		}
+		if count < minContains {
+			if minContains == 1 && !hasMin {
+				v.fail(r, instPath, schemaPath, "contains", "至少需要一个元素满足 contains 中的 schema")
+			} else {
+				v.fail(r, instPath, schemaPath, "minContains", "至少需要 %d 个元素满足 contains 中的 schema，实际为 %d", minContains, count)
+			}
+		}
+		if n, ok := schemaInt(schema, "maxContains"); ok && count > n {
+			v.fail(r, instPath, schemaPath, "maxContains", "最多允许 %d 个元素满足 contains 中的 schema，实际为 %d", n, count)
+		}
+	}
+
+	if unevaluated := schema.field("unevaluatedItems"); unevaluated != nil && !r.allItems {
+		for i := r.items; i < len(items); i++ {
+			if r.contains[i] {
+				continue
+			}
+			res := v.validate(unevaluated, schemaPath+"/unevaluatedItems", items[i], fmt.Sprintf("%s/%d", instPath, i))
+			r.errors = append(r.errors, res.errors...)
+		}
+		r.allItems = true
+	}
+}
+
+// ==================== 对象 ====================
+
+func (v *schemaValidator) validateObject(r *schemaResult, schema *jsonNode, schemaPath string, inst *jsonNode, instPath string) {
+	keys := inst.keys()
+	if n, ok := schemaInt(schema, "maxProperties"); ok && len(keys) > n {
+		v.fail(r, instPath, schemaPath, "maxProperties", "属性数量不能超过 %d，实际为 %d", n, len(keys))
+	}
+	if n, ok := schemaInt(schema, "minProperties"); ok && len(keys) < n {
+		v.fail(r, instPath, schemaPath, "minProperties", "属性数量不能少于 %d，实际为 %d", n, len(keys))
+	}
+	if req := schema.field("required"); req != nil && req.kind == jsonArray {
+		for _, name := range req.items {
+			if name.kind == jsonString && inst.field(name.value) == nil {
+				v.fail(r, instPath, schemaPath, "required", "缺少必需的属性 %s", name.value)
+			}
+		}
+	}
+
+	// draft-07 的 dependencies 同时承担 dependentRequired 与 dependentSchemas
+	for _, name := range []string{"dependentRequired", "dependentSchemas", "dependencies"} {
+		deps := schema.field(name)
+		if deps == nil || deps.kind != jsonObject {
+			continue
+		}
+		for _, dep := range deps.fields {
+			if inst.field(dep.name()) == nil {
+				continue
+			}
+			if dep.value.kind == jsonArray {
+				for _, req := range dep.value.items {
+					if req.kind == jsonString && inst.field(req.value) == nil {
+						v.fail(r, instPath, schemaPath, name, "存在属性 %s 时必须同时存在属性 %s", dep.name(), req.value)
+					}
+				}
+				continue
+			}
+			res := v.validate(dep.value, schemaPath+"/"+name+"/"+escapeJSONPointer(dep.name()), inst, instPath)
+			r.errors = append(r.errors, res.errors...)
+			r.merge(res)
+		}
+	}
+
+	if names := schema.field("propertyNames"); names != nil {
+		for _, key := range keys {
+			res := v.validate(names, schemaPath+"/propertyNames", newJSONString(key), instPath)
+			for _, e := range res.errors {
+				e.Message = fmt.Sprintf("属性名 %s %s", key, e.Message)
+				r.errors = append(r.errors, e)
+			}
+		}
+	}
+
+	properties := schema.field("properties")
+	patterns := schema.field("patternProperties")
+	additional := schema.field("additionalProperties")
+	for _, key := range keys {
+		value := inst.field(key)
+		path := instPath + "/" + escapeJSONPointer(key)
+		matched := false
+		if properties != nil && properties.kind == jsonObject {
+			if sub := properties.field(key); sub != nil {
+				matched = true
+				res := v.validate(sub, schemaPath+"/properties/"+escapeJSONPointer(key), value, path)
+				r.errors = append(r.errors, res.errors...)
+			}
+		}
+		if patterns != nil && patterns.kind == jsonObject {
+			for _, p := range patterns.fields {
+				re := v.compileRegexp(p.name())
+				if re == nil || !re.MatchString(key) {
+					continue
+				}
+				matched = true
+				res := v.validate(p.value, schemaPath+"/patternProperties/"+escapeJSONPointer(p.name()), value, path)
+				r.errors = append(r.errors, res.errors...)
+			}
+		}
+		if matched {
+			r.props[key] = true
+			continue
+		}
+		if additional != nil {
+			res := v.validate(additional, schemaPath+"/additionalProperties", value, path)
+			if additional.kind == jsonBool && !res.valid() {
+				v.fail(r, path, schemaPath, "additionalProperties", "不允许额外的属性 %s", key)
+			} else {
+				r.errors = append(r.errors, res.errors...)
+			}
+			r.props[key] = true
+		}
+	}
+
+	if unevaluated := schema.field("unevaluatedProperties"); unevaluated != nil {
+		for _, key := range keys {
+			if r.props[key] {
+				continue
+			}
+			path := instPath + "/" + escapeJSONPointer(key)
+			res := v.validate(unevaluated, schemaPath+"/unevaluatedProperties", inst.field(key), path)
+			if unevaluated.kind == jsonBool && !res.valid() {
+				v.fail(r, path, schemaPath, "unevaluatedProperties", "不允许未声明的属性 %s", key)
+			} else {
+				r.errors = append(r.errors, res.errors...)
+			}
+			r.props[key] = true
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	schemaDraft07 = `"$schema": "http://json-schema.org/draft-07/schema#"`
+	schemaDraft19 = `"$schema": "https://json-schema.org/draft/2019-09/schema"`
+	schemaDraft20 = `"$schema": "https://json-schema.org/draft/2020-12/schema"`
+)
+
+func TestValidateJsonSchema(t *testing.T) {
+	type violation struct {
+		instancePath string
+		schemaPath   string
+		keyword      string
+	}
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		want   []violation // 为空表示有效
+	}{
+		// draft-07 与 2020-12 的关键字差异
+		{"draft-07 tuple items", `{` + schemaDraft07 + `, "items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false}`, `["a", "b", true]`,
+			[]violation{{"/1", "/items/1/type", "type"}, {"/2", "/additionalItems", "additionalItems"}}},
+		{"2019-09 tuple items", `{` + schemaDraft19 + `, "items": [{"type": "string"}], "additionalItems": false}`, `["a", 1]`,
+			[]violation{{"/1", "/additionalItems", "additionalItems"}}},
+		{"2020-12 prefixItems", `{` + schemaDraft20 + `, "prefixItems": [{"type": "string"}], "items": false}`, `[1, 2]`,
+			[]violation{{"/0", "/prefixItems/0/type", "type"}, {"/1", "/items", "items"}}},
+		{"draft-07 ignores $ref siblings", `{` + schemaDraft07 + `, "definitions": {"s": {"type": "string"}}, "properties": {"a": {"$ref": "#/definitions/s", "maxLength": 1}}}`, `{"a": "long"}`, nil},
+		{"2020-12 applies $ref siblings", `{` + schemaDraft20 + `, "$defs": {"s": {"type": "string"}}, "properties": {"a": {"$ref": "#/$defs/s", "maxLength": 1}}}`, `{"a": "long"}`,
+			[]violation{{"/a", "/properties/a/maxLength", "maxLength"}}},
+		{"draft-07 dependencies", `{` + schemaDraft07 + `, "dependencies": {"a": ["b"], "c": {"required": ["d"]}}}`, `{"a": 1, "c": 2}`,
+			[]violation{{"", "/dependencies", "dependencies"}, {"", "/dependencies/c/required", "required"}}},
+		{"2020-12 dependentRequired and dependentSchemas", `{"dependentRequired": {"a": ["b"]}, "dependentSchemas": {"c": {"required": ["d"]}}}`, `{"a": 1, "c": 2}`,
+			[]violation{{"", "/dependentRequired", "dependentRequired"}, {"", "/dependentSchemas/c/required", "required"}}},
+
+		// $ref、$id 与 $anchor 的解析
+		{"$id base and $anchor", `{"$id": "https://example.com/root.json",
+			"$defs": {"pos": {"$id": "positive.json", "type": "integer", "minimum": 1}, "name": {"$anchor": "name", "type": "string"}},
+			"properties": {"n": {"$ref": "positive.json"}, "s": {"$ref": "#name"}, "t": {"$ref": "https://example.com/positive.json"}}}`,
+			`{"n": 0, "s": 1, "t": 5}`,
+			[]violation{{"/n", "/properties/n/$ref/minimum", "minimum"}, {"/s", "/properties/s/$ref/type", "type"}}},
+		{"draft-07 $id anchor", `{` + schemaDraft07 + `, "definitions": {"x": {"$id": "#num", "type": "number"}}, "properties": {"v": {"$ref": "#num"}}}`, `{"v": "x"}`,
+			[]violation{{"/v", "/properties/v/$ref/type", "type"}}},
+		{"recursive $ref", `{"type": "object", "properties": {"child": {"$ref": "#"}, "v": {"type": "integer"}}}`, `{"child": {"child": {"v": "x"}}}`,
+			[]violation{{"/child/child/v", "/properties/child/$ref/properties/child/$ref/properties/v/type", "type"}}},
+
+		// 违规位置
+		{"nested instance paths", `{"properties": {"list": {"type": "array", "items": {"properties": {"id": {"type": "integer"}}, "required": ["id"]}}}, "patternProperties": {"^x-": {"type": "string"}}}`,
+			`{"list": [{"id": 1}, {"id": "2"}, {}], "x-a": 1}`,
+			[]violation{{"/list/1/id", "/properties/list/items/properties/id/type", "type"}, {"/list/2", "/properties/list/items/required", "required"}, {"/x-a", "/patternProperties/^x-/type", "type"}}},
+		{"escaped pointer tokens", `{"properties": {"a/b": {"type": "string"}, "c~d": {"type": "string"}}}`, `{"a/b": 1, "c~d": 2}`,
+			[]violation{{"/a~1b", "/properties/a~1b/type", "type"}, {"/c~0d", "/properties/c~0d/type", "type"}}},
+		{"oneOf and format", `{"properties": {"n": {"oneOf": [{"type": "integer"}, {"minimum": 0}]}, "e": {"format": "email"}}}`, `{"n": 1, "e": "x"}`,
+			[]violation{{"/n", "/properties/n/oneOf", "oneOf"}, {"/e", "/properties/e/format", "format"}}},
+	}
+	j := NewJsonProcessor()
+	for _, tt := range tests {
+		violations, err := j.ValidateJsonSchema(tt.doc, tt.schema)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []violation
+		for _, v := range violations {
+			got = append(got, violation{v.InstancePath, v.SchemaPath, v.Keyword})
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestValidateJsonSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		want   string
+	}{
+		{"2020-12 tuple items", `{` + schemaDraft20 + `, "items": [{"type": "string"}]}`, `["a"]`, "不支持数组形式的 items"},
+		{"tuple items without $schema", `{"items": [{"type": "string"}]}`, `["a"]`, "prefixItems"},
+		{"missing $ref target", `{"properties": {"a": {"$ref": "#/$defs/missing"}}}`, `{"a": 1}`, "无法解析 $ref"},
+		{"missing anchor", `{"properties": {"a": {"$ref": "#missing"}}}`, `{"a": 1}`, "未找到锚点"},
+		{"external $ref", `{"properties": {"a": {"$ref": "https://example.com/other.json"}}}`, `{"a": 1}`, "不支持引用外部 schema"},
+		{"unknown $schema", `{"$schema": "http://example.com/custom"}`, `1`, "不支持的 $schema"},
+	}
+	for _, tt := range tests {
+		_, err := NewJsonProcessor().ValidateJsonSchema(tt.doc, tt.schema)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
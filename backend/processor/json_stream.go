@@ -0,0 +1,359 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// JsonStreamOptions 流式格式化选项
+type JsonStreamOptions struct {
+	Compress        bool `json:"compress"`        // 压缩输出，忽略缩进设置
+	IndentSize      int  `json:"indentSize"`      // 缩进空格数，默认 2
+	UseTabs         bool `json:"useTabs"`         // 使用制表符缩进
+	TrailingNewline bool `json:"trailingNewline"` // 输出末尾追加换行
+}
+
+// streamProgressInterval 两次进度回调之间至少读取的字节数
+const streamProgressInterval = 1 << 20
+
+// StreamJson 逐个标记读取 JSON 并写出格式化或压缩后的结果，内存占用只与嵌套深度有关，与输入大小无关
+// progress 每读取约 1MB 调用一次，参数为已读取的字节数；ctx 取消时中止处理
+func StreamJson(ctx context.Context, r io.Reader, w io.Writer, options JsonStreamOptions, progress func(read int64)) error {
+	indent := ""
+	if !options.Compress {
+		indent = JsonFormatOptions{IndentSize: options.IndentSize, UseTabs: options.UseTabs}.indent()
+	}
+	s := &jsonStreamer{
+		ctx:      ctx,
+		r:        bufio.NewReaderSize(r, 64<<10),
+		w:        bufio.NewWriterSize(w, 64<<10),
+		indent:   indent,
+		line:     1,
+		column:   1,
+		progress: progress,
+	}
+	if err := s.run(); err != nil {
+		return err
+	}
+	if options.TrailingNewline {
+		s.w.WriteByte('\n')
+	}
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("写入失败: %v", err)
+	}
+	if progress != nil {
+		progress(s.offset)
+	}
+	return nil
+}
+
+// errStreamEOF 内部使用，表示输入提前结束
+var errStreamEOF = errors.New("unexpected EOF")
+
+// jsonStreamer 流式 JSON 处理器，只保存未闭合的对象和数组，不保存已读取的内容
+type jsonStreamer struct {
+	ctx      context.Context
+	r        *bufio.Reader
+	w        *bufio.Writer
+	indent   string
+	offset   int64 // 已读取的字节数
+	line     int
+	column   int
+	progress func(int64)
+	reported int64 // 上次回调进度时的字节数
+	err      error // 读取失败或被取消时的错误，优先于语法错误返回
+}
+
+func (s *jsonStreamer) run() error {
+	// 跳过 UTF-8 BOM
+	if bom, err := s.r.Peek(3); err == nil && string(bom) == "\uFEFF" {
+		s.r.Discard(3)
+		s.offset = 3
+	}
+	c, err := s.peekNonSpace()
+	if err != nil {
+		return s.fail("JSON 值", "内容为空")
+	}
+	if err := s.value(c); err != nil {
+		return err
+	}
+	if _, err := s.peekNonSpace(); err == nil {
+		return s.fail("文本结尾", "JSON 值之后存在多余内容")
+	}
+	return s.err
+}
+
+// read 读取一个字节并更新位置
+func (s *jsonStreamer) read() (byte, error) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, errStreamEOF
+		}
+		s.err = fmt.Errorf("读取失败: %v", err)
+		return 0, s.err
+	}
+	s.offset++
+	switch {
+	case c == '\n':
+		s.line++
+		s.column = 1
+	case utf8.RuneStart(c):
+		s.column++
+	}
+	if s.offset-s.reported >= streamProgressInterval {
+		s.reported = s.offset
+		if s.progress != nil {
+			s.progress(s.offset)
+		}
+		if s.ctx.Err() != nil {
+			s.err = errors.New("已取消")
+			return 0, s.err
+		}
+	}
+	return c, nil
+}
+
+// peekNonSpace 跳过空白并返回下一个字节，不消耗该字节
+func (s *jsonStreamer) peekNonSpace() (byte, error) {
+	for {
+		b, err := s.r.Peek(1)
+		if err != nil {
+			return 0, errStreamEOF
+		}
+		switch b[0] {
+		case ' ', '\t', '\n', '\r':
+			if _, err := s.read(); err != nil {
+				return 0, err
+			}
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// fail 构造当前位置的语法错误
+func (s *jsonStreamer) fail(expected, reason string) error {
+	if s.err != nil {
+		return s.err
+	}
+	e := &SyntaxError{
+		Reason:   reason,
+		Line:     s.line,
+		Column:   s.column,
+		Offset:   int(s.offset),
+		Expected: expected,
+		Found:    "文本结尾",
+	}
+	if b, _ := s.r.Peek(utf8.UTFMax); len(b) > 0 {
+		e.Found = describeFound(b, 0)
+	}
+	e.Message = fmt.Sprintf("第 %d 行第 %d 列: %s", e.Line, e.Column, reason)
+	if expected != "" {
+		e.Message += fmt.Sprintf("（期望 %s，实际为 %s）", expected, e.Found)
+	}
+	return e
+}
+
+func (s *jsonStreamer) newline(depth int) {
+	if s.indent == "" {
+		return
+	}
+	s.w.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		s.w.WriteString(s.indent)
+	}
+}
+
+// value 处理一个完整的 JSON 值，c 为值的第一个字节（尚未读取）
+// 嵌套的对象和数组使用显式的栈而不是递归，嵌套深度不受调用栈大小限制
+func (s *jsonStreamer) value(c byte) error {
+	var stack []byte // 尚未闭合的对象或数组的结束符
+	for {
+		if c == '{' || c == '[' {
+			closer := byte('}')
+			if c == '[' {
+				closer = ']'
+			}
+			s.read()
+			s.w.WriteByte(c)
+			next, err := s.peekNonSpace()
+			if err != nil {
+				return s.fail(fmt.Sprintf("'%c'", closer), "JSON 意外结束")
+			}
+			if next != closer {
+				stack = append(stack, closer)
+				s.newline(len(stack))
+				if c, err = s.member(next, closer); err != nil {
+					return err
+				}
+				continue
+			}
+			s.read()
+			s.w.WriteByte(closer)
+		} else if err := s.scalar(c); err != nil {
+			return err
+		}
+
+		// 一个值处理完毕，读取所在对象或数组中的逗号或结束符
+		for {
+			if len(stack) == 0 {
+				return nil
+			}
+			closer := stack[len(stack)-1]
+			next, err := s.peekNonSpace()
+			if err != nil {
+				return s.fail(fmt.Sprintf("',' 或 '%c'", closer), "JSON 意外结束")
+			}
+			if next == closer {
+				s.read()
+				stack = stack[:len(stack)-1]
+				s.newline(len(stack))
+				s.w.WriteByte(closer)
+				continue
+			}
+			if next != ',' {
+				return s.fail(fmt.Sprintf("',' 或 '%c'", closer), "缺少逗号")
+			}
+			s.read()
+			s.w.WriteByte(',')
+			if next, err = s.peekNonSpace(); err != nil {
+				return s.fail("JSON 值", "JSON 意外结束")
+			}
+			if next == closer {
+				return s.fail("JSON 值", "多余的逗号")
+			}
+			s.newline(len(stack))
+			if c, err = s.member(next, closer); err != nil {
+				return err
+			}
+			break
+		}
+	}
+}
+
+// member 处理对象或数组中一个成员的开头，对象需先读取键和冒号；返回成员值的第一个字节
+func (s *jsonStreamer) member(c byte, closer byte) (byte, error) {
+	if closer != '}' {
+		return c, nil
+	}
+	if c != '"' {
+		return 0, s.fail("'\"'", "对象的键必须是字符串")
+	}
+	if err := s.string(); err != nil {
+		return 0, err
+	}
+	c, err := s.peekNonSpace()
+	if err != nil || c != ':' {
+		return 0, s.fail("':'", "键后面缺少冒号")
+	}
+	s.read()
+	s.w.WriteByte(':')
+	if s.indent != "" {
+		s.w.WriteByte(' ')
+	}
+	if c, err = s.peekNonSpace(); err != nil {
+		return 0, s.fail("JSON 值", "JSON 意外结束")
+	}
+	return c, nil
+}
+
+// scalar 处理字符串、数字或字面量
+func (s *jsonStreamer) scalar(c byte) error {
+	switch {
+	case c == '"':
+		return s.string()
+	case c == '-' || isDigit(c):
+		return s.number()
+	case c == 't':
+		return s.literal("true")
+	case c == 'f':
+		return s.literal("false")
+	case c == 'n':
+		return s.literal("null")
+	}
+	return s.fail("JSON 值", "无效的值")
+}
+
+// string 原样复制字符串，同时校验转义序列和控制字符
+func (s *jsonStreamer) string() error {
+	c, _ := s.read()
+	s.w.WriteByte(c)
+	for {
+		if p, err := s.r.Peek(1); err == nil && p[0] < 0x20 {
+			return s.fail("", "字符串中不能包含未转义的控制字符")
+		}
+		c, err := s.read()
+		if err != nil {
+			return s.fail("'\"'", "字符串没有结束")
+		}
+		switch {
+		case c == '"':
+			s.w.WriteByte(c)
+			return nil
+		case c == '\\':
+			s.w.WriteByte(c)
+			e, err := s.read()
+			if err != nil {
+				return s.fail("转义字符", "字符串没有结束")
+			}
+			s.w.WriteByte(e)
+			switch e {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				for i := 0; i < 4; i++ {
+					h, err := s.read()
+					if err != nil || !isHexDigit(h) {
+						return s.fail("十六进制数字", "无效的 Unicode 转义")
+					}
+					s.w.WriteByte(h)
+				}
+			default:
+				return s.fail("", fmt.Sprintf("无效的转义字符 \\%c", e))
+			}
+		default:
+			s.w.WriteByte(c)
+		}
+	}
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// number 读取数字，原样输出
+func (s *jsonStreamer) number() error {
+	line, column, offset := s.line, s.column, s.offset
+	var b strings.Builder
+	for {
+		p, err := s.r.Peek(1)
+		if err != nil || !strings.ContainsRune("+-.eE0123456789", rune(p[0])) {
+			break
+		}
+		c, _ := s.read()
+		b.WriteByte(c)
+	}
+	if !isJSONNumber(b.String()) {
+		// 错误定位到数字的开头
+		s.line, s.column, s.offset = line, column, offset
+		return s.fail("", "无效的数字 "+abbreviate(b.String()))
+	}
+	s.w.WriteString(b.String())
+	return nil
+}
+
+func (s *jsonStreamer) literal(literal string) error {
+	for i := 0; i < len(literal); i++ {
+		if p, err := s.r.Peek(1); err != nil || p[0] != literal[i] {
+			return s.fail(literal, "无效的值")
+		}
+		s.read()
+	}
+	s.w.WriteString(literal)
+	return nil
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func streamJSON(input string, options JsonStreamOptions) (string, error) {
+	var out bytes.Buffer
+	err := StreamJson(context.Background(), strings.NewReader(input), &out, options, nil)
+	return out.String(), err
+}
+
+func TestStreamJsonDeepNesting(t *testing.T) {
+	const depth = 20 << 20
+	_, err := streamJSON(strings.Repeat("[", depth), JsonStreamOptions{Compress: true})
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Reason != "JSON 意外结束" || syntaxErr.Offset != depth {
+		t.Fatalf("unclosed arrays: got %v", err)
+	}
+
+	const balanced = 1 << 20
+	input := strings.Repeat(`{"a":[`, balanced) + "1" + strings.Repeat("]}", balanced)
+	out, err := streamJSON(input, JsonStreamOptions{Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != input {
+		t.Errorf("balanced nesting was not copied verbatim")
+	}
+}
+
+func TestStreamJsonFormat(t *testing.T) {
+	input := ` {"a": [1, 2.50, {"b": null}], "c": {}, "d": [], "e": "xé\"y"} `
+	want := `{
+  "a": [
+    1,
+    2.50,
+    {
+      "b": null
+    }
+  ],
+  "c": {},
+  "d": [],
+  "e": "xé\"y"
+}`
+	out, err := streamJSON(input, JsonStreamOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+	out, err = streamJSON(input, JsonStreamOptions{Compress: true, TrailingNewline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":[1,2.50,{"b":null}],"c":{},"d":[],"e":"xé\"y"}` + "\n"; out != want {
+		t.Errorf("got %s, want %s", out, want)
+	}
+}
+
+func TestStreamJsonErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+		column int
+	}{
+		{``, "内容为空", 1},
+		{`[1,]`, "多余的逗号", 4},
+		{`[1 2]`, "缺少逗号", 4},
+		{`{"a" 1}`, "键后面缺少冒号", 6},
+		{`{1: 2}`, "对象的键必须是字符串", 2},
+		{`{"a": 1`, "JSON 意外结束", 8},
+		{`[01]`, "无效的数字 01", 2},
+		{`[tru]`, "无效的值", 5},
+		{`[] []`, "JSON 值之后存在多余内容", 4},
+	}
+	for _, tt := range tests {
+		_, err := streamJSON(tt.input, JsonStreamOptions{})
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: got %v, want syntax error", tt.input, err)
+			continue
+		}
+		if syntaxErr.Reason != tt.reason || syntaxErr.Column != tt.column {
+			t.Errorf("%s: got %q at column %d, want %q at column %d", tt.input, syntaxErr.Reason, syntaxErr.Column, tt.reason, tt.column)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+// numberSamples 超出 float64 精度或依赖原始写法的数字，所有操作都必须原样保留
+var numberSamples = []string{
+	"12345678901234567890123",
+	"-9223372036854775809",
+	"3.141592653589793238462643383279",
+	"0.10000000000000000000001",
+	"1.50",
+	"1.5E+300",
+	"6.02e-23",
+	"-0.0",
+	"1e400",
+	"1e-400",
+}
+
+func TestNumberFidelityFormat(t *testing.T) {
+	j := NewJsonProcessor()
+	for _, n := range numberSamples {
+		input := `{"n": ` + n + `, "list": [` + n + `]}`
+		formatted, err := j.FormatJson(input, false)
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if want := "{\n  \"n\": " + n + ",\n  \"list\": [\n    " + n + "\n  ]\n}"; formatted != want {
+			t.Errorf("FormatJson(%s) = %s", n, formatted)
+		}
+		compressed, err := j.CompressJson(formatted, false)
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if want := `{"n":` + n + `,"list":[` + n + `]}`; compressed != want {
+			t.Errorf("CompressJson(%s) = %s", n, compressed)
+		}
+		sorted, err := j.FormatJsonWithOptions(input, JsonFormatOptions{IndentSize: 4, SortKeys: "alpha"})
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if !strings.Contains(sorted, `"n": `+n+"\n") {
+			t.Errorf("FormatJsonWithOptions(%s) = %s", n, sorted)
+		}
+	}
+}
+
+func TestNumberFidelityQuery(t *testing.T) {
+	j := NewJsonProcessor()
+	for _, n := range numberSamples {
+		matches, err := j.QueryJson(`{"a": {"n": `+n+`}, "b": [`+n+`, 1]}`, "$..n")
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if len(matches) != 1 || matches[0].Value != n {
+			t.Errorf("QueryJson(%s) = %+v", n, matches)
+		}
+		matches, err = j.QueryJson(`{"b": [`+n+`, 1]}`, "$.b[?(@ == "+n+")]")
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if len(matches) != 1 || matches[0].Value != n {
+			t.Errorf("QueryJson filter (%s) = %+v", n, matches)
+		}
+	}
+}
+
+func TestNumberFidelityDiffPatch(t *testing.T) {
+	j := NewJsonProcessor()
+	tests := []struct {
+		left, right string
+	}{
+		{"12345678901234567890123", "12345678901234567890124"},
+		{"3.141592653589793238462643383279", "3.141592653589793238462643383278"},
+		{"0.10000000000000000000001", "0.10000000000000000000002"},
+		{"9007199254740993", "9007199254740992"},
+	}
+	for _, tt := range tests {
+		left, right := `{"n": `+tt.left+`}`, `{"n": `+tt.right+`}`
+		result, err := j.DiffJson(left, right, JsonDiffOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.left, err)
+		}
+		if result.Equal || len(result.Changes) != 1 {
+			t.Fatalf("DiffJson(%s, %s) = %+v", tt.left, tt.right, result)
+		}
+		if c := result.Changes[0]; c.OldValue != tt.left || c.NewValue != tt.right {
+			t.Errorf("change %+v", c)
+		}
+		patched, err := j.ApplyJsonPatch(left, result.Patch)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.left, err)
+		}
+		if compressed, _ := j.CompressJson(patched, false); compressed != `{"n":`+tt.right+`}` {
+			t.Errorf("ApplyJsonPatch = %s", patched)
+		}
+
+		same, err := j.DiffJson(left, `{ "n" : `+tt.left+` }`, JsonDiffOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !same.Equal {
+			t.Errorf("DiffJson(%s, itself) = %+v", tt.left, same)
+		}
+	}
+}
+
+func TestNumberFidelityConvert(t *testing.T) {
+	c := NewConvertProcessor()
+	for _, n := range numberSamples {
+		input := `{"n":` + n + `}`
+		yamlText, err := c.Convert(input, ConvertOptions{From: FormatJSON, To: FormatYAML})
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		back, err := c.Convert(yamlText, ConvertOptions{From: FormatYAML, To: FormatJSON})
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if want := "{\n  \"n\": " + n + "\n}"; back != want {
+			t.Errorf("JSON → YAML → JSON (%s) = %s", n, back)
+		}
+	}
+
+	toml := `pi = 3.141592653589793238462643383279
+price = 1.50
+avogadro = 6.022_140_76e+23
+big = 9_223_372_036_854_775_807
+list = [0.10000000000000000000001, 2.0]
+
+[[items]]
+weight = 0.30000000000000000004
+
+[[items]]
+weight = 1e-7
+inline = { x = 1.250, "quoted key" = [1.10] }
+
+[items.sub]
+v = 2.50
+`
+	out, err := c.Convert(toml, ConvertOptions{From: FormatTOML, To: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "pi": 3.141592653589793238462643383279,
+  "price": 1.50,
+  "avogadro": 6.02214076e+23,
+  "big": 9223372036854775807,
+  "list": [
+    0.10000000000000000000001,
+    2.0
+  ],
+  "items": [
+    {
+      "weight": 0.30000000000000000004
+    },
+    {
+      "weight": 1e-7,
+      "inline": {
+        "x": 1.250,
+        "quoted key": [
+          1.10
+        ]
+      },
+      "sub": {
+        "v": 2.50
+      }
+    }
+  ]
+}`
+	if out != want {
+		t.Errorf("TOML → JSON\n%s\nwant\n%s", out, want)
+	}
+
+	fromJSON, err := c.Convert(`{"pi": 3.141592653589793238462643383279, "price": 1.50, "big": 12345678901234567890123}`, ConvertOptions{From: FormatJSON, To: FormatTOML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TOML 的整数只有 64 位，超出范围的整数以浮点数写法输出，数字保持不变
+	if want := "pi = 3.141592653589793238462643383279\nprice = 1.50\nbig = 12345678901234567890123.0\n"; fromJSON != want {
+		t.Errorf("JSON → TOML = %q, want %q", fromJSON, want)
+	}
+
+	tomlOut, err := c.Convert(toml, ConvertOptions{From: FormatTOML, To: FormatTOML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []string{"3.141592653589793238462643383279", "1.50", "0.30000000000000000004", "2.50", "1.250"} {
+		if !strings.Contains(tomlOut, n) {
+			t.Errorf("TOML → TOML lost %s:\n%s", n, tomlOut)
+		}
+	}
+}
+
+func TestNumberFidelityCSV(t *testing.T) {
+	c := NewCSVProcessor()
+	input := `[{"id": 12345678901234567890123, "x": 3.141592653589793238462643383279, "y": 1.50, "z": 1.5E+300}]`
+	csvText, err := c.JsonToCsv(input, CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,x,y,z\n12345678901234567890123,3.141592653589793238462643383279,1.50,1.5E+300\n"; csvText != want {
+		t.Errorf("JsonToCsv = %q, want %q", csvText, want)
+	}
+	back, err := c.CsvToJson(csvText, CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := NewJsonProcessor().CompressJson(back, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"id":12345678901234567890123,"x":3.141592653589793238462643383279,"y":1.50,"z":1.5E+300}]`; compressed != want {
+		t.Errorf("CsvToJson = %s", compressed)
+	}
+}
+
+func TestNumberFidelityRepair(t *testing.T) {
+	j := NewJsonProcessor()
+	for _, n := range numberSamples {
+		result, err := j.RepairJson(`{n: `+n+`, /* c */ list: [`+n+`,],}`, JsonFormatOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		compressed, err := j.CompressJson(result.Result, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"n":` + n + `,"list":[` + n + `]}`; compressed != want {
+			t.Errorf("RepairJson(%s) = %s", n, compressed)
+		}
+	}
+}
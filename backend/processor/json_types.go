@@ -0,0 +1,342 @@
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 类型生成支持的目标语言
+const (
+	TypeLangGo         = "go"
+	TypeLangTypeScript = "typescript"
+	TypeLangRust       = "rust"
+	TypeLangJava       = "java"
+	TypeLangPython     = "python"
+	TypeLangKotlin     = "kotlin"
+)
+
+// TypeGenOptions 根据 JSON 样本生成类型定义的选项
+type TypeGenOptions struct {
+	Language       string `json:"language"`       // 目标语言：go、typescript、rust、java、python、kotlin
+	TypeName       string `json:"typeName"`       // 顶层类型名，默认 AutoGenerated
+	PythonStyle    string `json:"pythonStyle"`    // Python 输出风格：dataclass（默认）或 pydantic
+	ArrayAsSamples bool   `json:"arrayAsSamples"` // 只有一个样本且为数组时，将数组元素视为多个样本
+}
+
+// GenerateTypes 根据一个或多个 JSON 样本生成指定语言的类型定义
+func (j *JsonProcessor) GenerateTypes(samples []string, options TypeGenOptions) (string, error) {
+	if options.Language == TypeLangGo {
+		return j.JsonToGo(samples, GoStructOptions{
+			TypeName:       options.TypeName,
+			Flatten:        true,
+			Gofmt:          true,
+			ArrayAsSamples: options.ArrayAsSamples,
+		})
+	}
+
+	nodes, err := parseSamples(samples, options.ArrayAsSamples)
+	if err != nil {
+		return "", err
+	}
+	if options.TypeName == "" {
+		options.TypeName = "AutoGenerated"
+	}
+	m := buildTypeModel(inferShape(nodes), pascalCase(options.TypeName))
+
+	switch options.Language {
+	case TypeLangTypeScript:
+		return emitTypeScript(m), nil
+	case TypeLangRust:
+		return emitRust(m), nil
+	case TypeLangJava:
+		return emitJava(m), nil
+	case TypeLangPython:
+		switch options.PythonStyle {
+		case "", "dataclass":
+			return emitPython(m, false), nil
+		case "pydantic":
+			return emitPython(m, true), nil
+		}
+		return "", fmt.Errorf("不支持的 Python 输出风格: %s", options.PythonStyle)
+	case TypeLangKotlin:
+		return emitKotlin(m), nil
+	}
+	return "", fmt.Errorf("不支持的目标语言: %s", options.Language)
+}
+
+// ==================== 中间类型模型 ====================
+
+// typeKind 与语言无关的类型种类
+type typeKind int
+
+const (
+	typeAny    typeKind = iota // 无法确定的类型（多种类型混合或只有 null）
+	typeBool                   // 布尔值
+	typeInt                    // int32 范围内的整数
+	typeLong                   // int64 范围内的整数
+	typeBigInt                 // 超出 int64 的整数
+	typeFloat                  // 小数
+	typeString                 // 字符串
+	typeArray                  // 数组
+	typeObject                 // 对象
+)
+
+// typeRef 类型引用
+type typeRef struct {
+	kind     typeKind
+	nullable bool        // 样本中出现过 null
+	elem     *typeRef    // 数组元素类型
+	object   *objectType // 对象类型
+}
+
+// objectType 具名对象类型
+type objectType struct {
+	name      string
+	fields    []*objectField
+	signature string // 结构签名，用于合并同名且结构相同的类型
+}
+
+// objectField 对象字段
+type objectField struct {
+	key      string // JSON 中的原始键名
+	typ      *typeRef
+	optional bool // 部分样本中缺失
+}
+
+// typeModel 由样本推断出的类型模型，各语言的生成器共用
+type typeModel struct {
+	rootName string
+	root     *typeRef
+	objects  []*objectType          // 按首次出现的顺序排列，父类型在子类型之前，被合并的为 nil
+	taken    map[string]*objectType // 已占用的类型名
+}
+
+// buildTypeModel 将合并后的样本结构转换为类型模型
+func buildTypeModel(shape *jsonShape, rootName string) *typeModel {
+	// 先占用顶层类型名，嵌套类型不会与其重名
+	m := &typeModel{rootName: rootName, taken: map[string]*objectType{rootName: nil}}
+	if shape.total > 0 && shape.objects == shape.total {
+		m.root = &typeRef{kind: typeObject, object: m.object(shape, rootName, "", true)}
+	} else {
+		m.root = m.ref(shape, rootName+"Item", rootName)
+	}
+	return m
+}
+
+// ref 返回结构对应的类型，name 为对象类型的候选名称，parent 为所属类型名
+func (m *typeModel) ref(s *jsonShape, name, parent string) *typeRef {
+	kinds := 0
+	for _, count := range []int{s.booleans, s.integers + s.floats, s.strings, s.arrays, s.objects} {
+		if count > 0 {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return &typeRef{kind: typeAny}
+	}
+
+	r := &typeRef{nullable: s.nullable()}
+	switch {
+	case s.booleans > 0:
+		r.kind = typeBool
+	case s.floats > 0:
+		r.kind = typeFloat
+	case s.hugeInts > 0:
+		r.kind = typeBigInt
+	case s.wideInts > 0:
+		r.kind = typeLong
+	case s.integers > 0:
+		r.kind = typeInt
+	case s.strings > 0:
+		r.kind = typeString
+	case s.arrays > 0:
+		r.kind = typeArray
+		if s.items == nil {
+			r.elem = &typeRef{kind: typeAny}
+		} else {
+			r.elem = m.ref(s.items, singular(name), parent)
+		}
+	default:
+		r.kind = typeObject
+		r.object = m.object(s, name, parent, false)
+	}
+	return r
+}
+
+// object 生成对象类型并分配类型名，结构相同的同名类型会被复用
+func (m *typeModel) object(s *jsonShape, name, parent string, root bool) *objectType {
+	index := len(m.objects)
+	m.objects = append(m.objects, nil)
+
+	o := &objectType{}
+	var sig strings.Builder
+	for _, f := range s.fields {
+		field := &objectField{key: f.name, typ: m.ref(f.shape, pascalCase(f.name), name), optional: f.optional(s)}
+		o.fields = append(o.fields, field)
+		fmt.Fprintf(&sig, "%s:%s,%t;", strconv.Quote(f.name), field.typ.signature(), field.optional)
+	}
+	o.signature = sig.String()
+
+	if root {
+		o.name = name
+		m.taken[name] = o
+		m.objects[index] = o
+		return o
+	}
+	candidate := name
+	for i := 1; ; i++ {
+		existing, ok := m.taken[candidate]
+		if !ok {
+			o.name = candidate
+			m.taken[candidate] = o
+			m.objects[index] = o
+			return o
+		}
+		if existing != nil && existing.signature == o.signature {
+			return existing
+		}
+		// 重名时先尝试加上所属类型名作为前缀，再追加序号
+		if i == 1 {
+			candidate = parent + name
+		} else {
+			candidate = name + strconv.Itoa(i)
+		}
+	}
+}
+
+// signature 返回类型的签名
+func (r *typeRef) signature() string {
+	s := strconv.Itoa(int(r.kind))
+	switch r.kind {
+	case typeArray:
+		s += "[" + r.elem.signature() + "]"
+	case typeObject:
+		s += "{" + r.object.name + "}"
+	}
+	if r.nullable {
+		s += "?"
+	}
+	return s
+}
+
+// namedObjects 返回全部具名对象类型
+func (m *typeModel) namedObjects() []*objectType {
+	var objects []*objectType
+	for _, o := range m.objects {
+		if o != nil {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// rootIsObject 顶层是否为对象
+func (m *typeModel) rootIsObject() bool {
+	return m.root.kind == typeObject && m.root.object.name == m.rootName
+}
+
+// ==================== 命名 ====================
+
+// singular 将复数形式的类型名转换为单数，用作数组元素的类型名
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ss"), !strings.HasSuffix(name, "s"), len(name) == 1:
+		return name
+	}
+	return strings.TrimSuffix(name, "s")
+}
+
+// capitalize 首字母大写，其余小写
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// pascalCase 转换为大驼峰命名，如 user_id → UserId
+func pascalCase(key string) string {
+	var b strings.Builder
+	for _, word := range splitWords(key) {
+		b.WriteString(capitalize(word))
+	}
+	if b.Len() == 0 {
+		return "Field"
+	}
+	return safeIdentifier(b.String(), "Num")
+}
+
+// camelCase 转换为小驼峰命名，如 user_id → userId
+func camelCase(key string) string {
+	var b strings.Builder
+	for i, word := range splitWords(key) {
+		if i == 0 {
+			b.WriteString(strings.ToLower(word))
+		} else {
+			b.WriteString(capitalize(word))
+		}
+	}
+	return safeIdentifier(b.String(), "_")
+}
+
+// snakeCase 转换为下划线命名，如 userId → user_id
+func snakeCase(key string) string {
+	words := splitWords(key)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return safeIdentifier(strings.Join(words, "_"), "_")
+}
+
+// safeIdentifier 处理空名称和数字开头的名称
+func safeIdentifier(name, digitPrefix string) string {
+	switch {
+	case name == "":
+		return "field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return digitPrefix + name
+	}
+	return name
+}
+
+// splitWords 按分隔符和驼峰边界拆分单词，如 userID → user ID，HTTPServer → HTTP Server
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && len(current) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+// uniqueName 在同一作用域内为重复的名称追加序号
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,427 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 各语言生成器：根据同一份类型模型输出对应语言的类型定义
+
+// ==================== TypeScript ====================
+
+// tsIdentifierPattern 可以不加引号的 TypeScript 属性名
+var tsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// emitTypeScript 生成 TypeScript 接口
+func emitTypeScript(m *typeModel) string {
+	var defs []string
+	if !m.rootIsObject() {
+		defs = append(defs, fmt.Sprintf("export type %s = %s;\n", m.rootName, tsType(m.root)))
+	}
+	for _, o := range m.namedObjects() {
+		var b strings.Builder
+		fmt.Fprintf(&b, "export interface %s {\n", o.name)
+		for _, f := range o.fields {
+			key := f.key
+			if !tsIdentifierPattern.MatchString(key) {
+				key = quoteJSONString(key)
+			}
+			if f.optional {
+				key += "?"
+			}
+			fmt.Fprintf(&b, "  %s: %s;\n", key, tsType(f.typ))
+		}
+		b.WriteString("}\n")
+		defs = append(defs, b.String())
+	}
+	return strings.Join(defs, "\n")
+}
+
+func tsType(r *typeRef) string {
+	var t string
+	switch r.kind {
+	case typeAny:
+		return "unknown"
+	case typeBool:
+		t = "boolean"
+	case typeInt, typeLong, typeBigInt, typeFloat:
+		t = "number"
+	case typeString:
+		t = "string"
+	case typeArray:
+		elem := tsType(r.elem)
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		t = elem + "[]"
+	case typeObject:
+		t = r.object.name
+	}
+	if r.nullable {
+		return t + " | null"
+	}
+	return t
+}
+
+// ==================== Rust ====================
+
+// rustKeywords Rust 关键字，作为字段名时需要使用原始标识符
+var rustKeywords = toSet("as", "async", "await", "break", "const", "continue", "crate", "dyn", "else", "enum",
+	"extern", "false", "fn", "for", "if", "impl", "in", "let", "loop", "match", "mod", "move", "mut", "pub",
+	"ref", "return", "self", "static", "struct", "super", "trait", "true", "type", "unsafe", "use", "where",
+	"while", "abstract", "become", "box", "do", "final", "macro", "override", "priv", "try", "typeof",
+	"unsized", "virtual", "yield")
+
+// emitRust 生成带 serde 注解的 Rust 结构体
+func emitRust(m *typeModel) string {
+	defs := []string{"use serde::{Deserialize, Serialize};\n"}
+	if !m.rootIsObject() {
+		defs = append(defs, fmt.Sprintf("pub type %s = %s;\n", m.rootName, rustType(m.root, false)))
+	}
+	for _, o := range m.namedObjects() {
+		var b strings.Builder
+		b.WriteString("#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]\n")
+		fmt.Fprintf(&b, "pub struct %s {\n", o.name)
+		used := map[string]bool{}
+		for _, f := range o.fields {
+			name := uniqueName(used, snakeCase(f.key))
+			if name != f.key {
+				fmt.Fprintf(&b, "    #[serde(rename = %s)]\n", quoteJSONString(f.key))
+			}
+			if f.optional {
+				b.WriteString("    #[serde(default, skip_serializing_if = \"Option::is_none\")]\n")
+			}
+			switch {
+			case name == "self" || name == "super" || name == "crate":
+				// 这几个关键字不能作为原始标识符
+				name += "_"
+			case rustKeywords[name]:
+				name = "r#" + name
+			}
+			fmt.Fprintf(&b, "    pub %s: %s,\n", name, rustType(f.typ, f.optional))
+		}
+		b.WriteString("}\n")
+		defs = append(defs, b.String())
+	}
+	return strings.Join(defs, "\n")
+}
+
+func rustType(r *typeRef, optional bool) string {
+	var t string
+	switch r.kind {
+	case typeAny:
+		t = "serde_json::Value"
+	case typeBool:
+		t = "bool"
+	case typeInt, typeLong:
+		t = "i64"
+	case typeBigInt:
+		t = "serde_json::Number"
+	case typeFloat:
+		t = "f64"
+	case typeString:
+		t = "String"
+	case typeArray:
+		t = "Vec<" + rustType(r.elem, false) + ">"
+	case typeObject:
+		t = r.object.name
+	}
+	// serde_json::Value 本身可以表示 null
+	if (r.nullable && r.kind != typeAny) || optional {
+		return "Option<" + t + ">"
+	}
+	return t
+}
+
+// ==================== Java ====================
+
+// javaKeywords Java 关键字及保留字
+var javaKeywords = toSet("abstract", "assert", "boolean", "break", "byte", "case", "catch", "char", "class",
+	"const", "continue", "default", "do", "double", "else", "enum", "extends", "final", "finally", "float",
+	"for", "goto", "if", "implements", "import", "instanceof", "int", "interface", "long", "native", "new",
+	"package", "private", "protected", "public", "return", "short", "static", "strictfp", "super", "switch",
+	"synchronized", "this", "throw", "throws", "transient", "try", "void", "volatile", "while", "true",
+	"false", "null", "record", "var", "yield")
+
+// emitJava 生成 Java record，字段名与 JSON 键不一致时使用 Jackson 注解
+func emitJava(m *typeModel) string {
+	imports := map[string]bool{}
+	var defs []string
+	if !m.rootIsObject() {
+		defs = append(defs, fmt.Sprintf("// %s 对应的类型为 %s\n", m.rootName, javaType(m.root, false, imports)))
+	}
+	for _, o := range m.namedObjects() {
+		modifier := ""
+		if o.name == m.rootName {
+			modifier = "public "
+		}
+		if len(o.fields) == 0 {
+			defs = append(defs, fmt.Sprintf("%srecord %s() {\n}\n", modifier, o.name))
+			continue
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%srecord %s(\n", modifier, o.name)
+		used := map[string]bool{}
+		for i, f := range o.fields {
+			name := uniqueName(used, camelCase(f.key))
+			if javaKeywords[name] {
+				name += "_"
+			}
+			b.WriteString("        ")
+			if name != f.key {
+				imports["com.fasterxml.jackson.annotation.JsonProperty"] = true
+				fmt.Fprintf(&b, "@JsonProperty(%s) ", quoteJSONString(f.key))
+			}
+			fmt.Fprintf(&b, "%s %s", javaType(f.typ, f.optional, imports), name)
+			if i < len(o.fields)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(") {\n}\n")
+		defs = append(defs, b.String())
+	}
+	return importBlock("import %s;\n", imports) + strings.Join(defs, "\n")
+}
+
+// javaType 返回 Java 类型，可能缺失或为 null 的基本类型使用包装类型
+func javaType(r *typeRef, optional bool, imports map[string]bool) string {
+	boxed := r.nullable || optional
+	switch r.kind {
+	case typeBool:
+		return pick(boxed, "Boolean", "boolean")
+	case typeInt:
+		return pick(boxed, "Integer", "int")
+	case typeLong:
+		return pick(boxed, "Long", "long")
+	case typeFloat:
+		return pick(boxed, "Double", "double")
+	case typeBigInt:
+		imports["java.math.BigInteger"] = true
+		return "BigInteger"
+	case typeString:
+		return "String"
+	case typeArray:
+		imports["java.util.List"] = true
+		return "List<" + javaType(r.elem, true, imports) + ">"
+	case typeObject:
+		return r.object.name
+	}
+	return "Object"
+}
+
+// ==================== Python ====================
+
+// pythonKeywords Python 关键字
+var pythonKeywords = toSet("False", "None", "True", "and", "as", "assert", "async", "await", "break", "class",
+	"continue", "def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import",
+	"in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield")
+
+// emitPython 生成 Python dataclass 或 pydantic 模型
+func emitPython(m *typeModel, pydantic bool) string {
+	typing := map[string]bool{}
+	var defs []string
+	// 子类型定义在父类型之前
+	objects := m.namedObjects()
+	for i := len(objects) - 1; i >= 0; i-- {
+		o := objects[i]
+		var b strings.Builder
+		if pydantic {
+			fmt.Fprintf(&b, "class %s(BaseModel):\n", o.name)
+		} else {
+			fmt.Fprintf(&b, "@dataclass\nclass %s:\n", o.name)
+		}
+		if len(o.fields) == 0 {
+			b.WriteString("    pass\n")
+		}
+
+		// dataclass 中有默认值的字段必须排在后面
+		fields := append([]*objectField(nil), o.fields...)
+		if !pydantic {
+			sort.SliceStable(fields, func(a, b int) bool {
+				return !fields[a].optional && fields[b].optional
+			})
+		}
+		used := map[string]bool{}
+		for _, f := range fields {
+			name := uniqueName(used, snakeCase(f.key))
+			if pythonKeywords[name] {
+				name += "_"
+			}
+			line := name + ": " + pythonType(f.typ, f.optional, typing)
+			switch {
+			case pydantic && name != f.key && f.optional:
+				line += " = Field(default=None, alias=" + quoteJSONString(f.key) + ")"
+			case pydantic && name != f.key:
+				line += " = Field(alias=" + quoteJSONString(f.key) + ")"
+			case f.optional:
+				line += " = None"
+			}
+			if !pydantic && name != f.key {
+				// dataclass 无法声明别名，注明原始键名
+				line += "  # JSON: " + quoteJSONString(f.key)
+			}
+			b.WriteString("    " + line + "\n")
+		}
+		defs = append(defs, b.String())
+	}
+	if !m.rootIsObject() {
+		defs = append(defs, fmt.Sprintf("%s = %s\n", m.rootName, pythonType(m.root, false, typing)))
+	}
+
+	header := "from __future__ import annotations\n\n"
+	if !pydantic {
+		header += "from dataclasses import dataclass\n"
+	}
+	if len(typing) > 0 {
+		var names []string
+		for name := range typing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		header += "from typing import " + strings.Join(names, ", ") + "\n"
+	}
+	if pydantic {
+		header += "\nfrom pydantic import BaseModel, Field\n"
+	}
+	return header + "\n\n" + strings.Join(defs, "\n\n")
+}
+
+func pythonType(r *typeRef, optional bool, typing map[string]bool) string {
+	var t string
+	switch r.kind {
+	case typeAny:
+		typing["Any"] = true
+		return "Any"
+	case typeBool:
+		t = "bool"
+	case typeInt, typeLong, typeBigInt:
+		t = "int"
+	case typeFloat:
+		t = "float"
+	case typeString:
+		t = "str"
+	case typeArray:
+		t = "list[" + pythonType(r.elem, false, typing) + "]"
+	case typeObject:
+		t = r.object.name
+	}
+	if r.nullable || optional {
+		typing["Optional"] = true
+		return "Optional[" + t + "]"
+	}
+	return t
+}
+
+// ==================== Kotlin ====================
+
+// kotlinKeywords Kotlin 硬关键字，作为属性名时需要使用反引号
+var kotlinKeywords = toSet("as", "break", "class", "continue", "do", "else", "false", "for", "fun", "if", "in",
+	"interface", "is", "null", "object", "package", "return", "super", "this", "throw", "true", "try",
+	"typealias", "typeof", "val", "var", "when", "while")
+
+// emitKotlin 生成 kotlinx.serialization 数据类
+func emitKotlin(m *typeModel) string {
+	imports := map[string]bool{"kotlinx.serialization.Serializable": true}
+	var defs []string
+	if !m.rootIsObject() {
+		defs = append(defs, fmt.Sprintf("typealias %s = %s\n", m.rootName, kotlinType(m.root, false, imports)))
+	}
+	for _, o := range m.namedObjects() {
+		if len(o.fields) == 0 {
+			// 数据类至少需要一个属性
+			defs = append(defs, fmt.Sprintf("@Serializable\nclass %s\n", o.name))
+			continue
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "@Serializable\ndata class %s(\n", o.name)
+		used := map[string]bool{}
+		for _, f := range o.fields {
+			name := uniqueName(used, camelCase(f.key))
+			if name != f.key {
+				imports["kotlinx.serialization.SerialName"] = true
+				fmt.Fprintf(&b, "    @SerialName(%s)\n", quoteJSONString(f.key))
+			}
+			if kotlinKeywords[name] {
+				name = "`" + name + "`"
+			}
+			fmt.Fprintf(&b, "    val %s: %s", name, kotlinType(f.typ, f.optional, imports))
+			if f.optional {
+				b.WriteString(" = null")
+			}
+			b.WriteString(",\n")
+		}
+		b.WriteString(")\n")
+		defs = append(defs, b.String())
+	}
+	return importBlock("import %s\n", imports) + strings.Join(defs, "\n")
+}
+
+func kotlinType(r *typeRef, optional bool, imports map[string]bool) string {
+	var t string
+	switch r.kind {
+	case typeAny:
+		imports["kotlinx.serialization.json.JsonElement"] = true
+		t = "JsonElement"
+	case typeBool:
+		t = "Boolean"
+	case typeInt:
+		t = "Int"
+	case typeLong:
+		t = "Long"
+	case typeBigInt:
+		// 超出 Long 的整数保留为 JSON 原始值，避免丢失精度
+		imports["kotlinx.serialization.json.JsonPrimitive"] = true
+		t = "JsonPrimitive"
+	case typeFloat:
+		t = "Double"
+	case typeString:
+		t = "String"
+	case typeArray:
+		t = "List<" + kotlinType(r.elem, false, imports) + ">"
+	case typeObject:
+		t = r.object.name
+	}
+	if r.nullable || optional {
+		return t + "?"
+	}
+	return t
+}
+
+// ==================== 辅助函数 ====================
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+func pick(cond bool, yes, no string) string {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+// importBlock 按字母顺序生成导入语句
+func importBlock(format string, imports map[string]bool) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	var names []string
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, format, name)
+	}
+	return b.String() + "\n"
+}
@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "重新生成 testdata 下的 golden 文件")
+
+// typeGenSamples 覆盖嵌套对象、可选字段（name 只在第一个样本中出现）、可空字段、
+// 与关键字冲突的键、int64 和超出 int64 的整数，以及始终为空的数组
+var typeGenSamples = []string{
+	`{
+		"id": 1,
+		"name": "alice",
+		"nickname": null,
+		"class": "admin",
+		"type": "user",
+		"createdAt": 1700000000000,
+		"balance": 123456789012345678901234567890,
+		"score": 9.5,
+		"tags": [],
+		"owner": {"login": "a", "address": {"city": "Paris", "zip": null}},
+		"items": [{"sku": "a-1", "qty": 1}, {"sku": "b-2"}]
+	}`,
+	`{
+		"id": 2,
+		"nickname": "bob",
+		"class": "guest",
+		"type": "bot",
+		"createdAt": 1,
+		"balance": 5,
+		"score": 7,
+		"tags": [],
+		"owner": {"login": "b", "address": {"city": "Oslo", "zip": "0150"}},
+		"items": []
+	}`,
+}
+
+func TestGenerateTypesGolden(t *testing.T) {
+	tests := []struct {
+		golden  string
+		options TypeGenOptions
+	}{
+		{"types.ts.golden", TypeGenOptions{Language: TypeLangTypeScript, TypeName: "user"}},
+		{"types.rs.golden", TypeGenOptions{Language: TypeLangRust, TypeName: "user"}},
+		{"types.java.golden", TypeGenOptions{Language: TypeLangJava, TypeName: "user"}},
+		{"types.py.golden", TypeGenOptions{Language: TypeLangPython, TypeName: "user"}},
+		{"types_pydantic.py.golden", TypeGenOptions{Language: TypeLangPython, TypeName: "user", PythonStyle: "pydantic"}},
+		{"types.kt.golden", TypeGenOptions{Language: TypeLangKotlin, TypeName: "user"}},
+	}
+	j := NewJsonProcessor()
+	for _, tt := range tests {
+		got, err := j.GenerateTypes(typeGenSamples, tt.options)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.golden, err)
+		}
+		path := filepath.Join("testdata", tt.golden)
+		if *update {
+			if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v（使用 -update 生成）", tt.golden, err)
+		}
+		if got != string(want) {
+			t.Errorf("%s mismatch:\ngot:\n%s\nwant:\n%s", tt.golden, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package processor
+
+import "testing"
+
+func TestDecodeUnicode(t *testing.T) {
+	tests := []struct {
+		name    string
+		literal string
+		want    string
+	}{
+		{"no escapes", `"plain"`, `"plain"`},
+		{"bmp", `"\u4f60\u597D"`, `"你好"`},
+		{"surrogate pair", `"\ud83d\ude00"`, `"😀"`},
+		{"surrogate pair upper case", `"\uD83D\uDE00!"`, `"😀!"`},
+		{"lone high surrogate", `"\ud83d"`, `"\ud83d"`},
+		{"lone high surrogate before text", `"\ud83dx"`, `"\ud83dx"`},
+		{"lone high surrogate before bmp escape", `"\ud83d\u0041"`, `"\ud83dA"`},
+		{"lone high surrogate before pair", `"\ud83d\ud83d\ude00"`, `"\ud83d😀"`},
+		{"lone low surrogate", `"\ude00"`, `"\ude00"`},
+		{"low then high", `"\ude00\ud83d"`, `"\ude00\ud83d"`},
+		{"escaped backslash", `"\\u0041"`, `"\\u0041"`},
+		{"escaped backslash then escape", `"\\\u0041"`, `"\\A"`},
+		{"quote stays escaped", `"\u0022"`, `"\u0022"`},
+		{"backslash stays escaped", `"\u005c"`, `"\u005c"`},
+		{"backslash upper case stays escaped", `"\u005C"`, `"\u005C"`},
+		{"control character stays escaped", `"a\u0000b\u001f"`, `"a\u0000b\u001f"`},
+		{"other escapes untouched", `"\n\t\/\u0041"`, `"\n\t\/A"`},
+	}
+	for _, tt := range tests {
+		if got := decodeUnicode(tt.literal); got != tt.want {
+			t.Errorf("%s: decodeUnicode(%s) = %s, want %s", tt.name, tt.literal, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeUnicodeNode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"object key", `{"\u540d\u5b57":"\u5f20\u4e09"}`, `{"名字":"张三"}`},
+		{"key with quote", `{"a\u0022b":1}`, `{"a\u0022b":1}`},
+		{"key with surrogate pair", `{"\ud83d\ude00":true}`, `{"😀":true}`},
+		{"nested", `{"a":[{"\u0062":"\u0063"},"\u005c"]}`, `{"a":[{"b":"c"},"\u005c"]}`},
+		{"numbers untouched", `[1e5,"\u0031"]`, `[1e5,"1"]`},
+	}
+	for _, tt := range tests {
+		root, err := parseJSON([]byte(tt.input))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		decodeUnicodeNode(root)
+		got := encodeJSON(root, "")
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+		// 解码结果必须仍然是合法且含义不变的 JSON
+		if _, err := parseJSON([]byte(got)); err != nil {
+			t.Errorf("%s: decoded output is invalid: %v", tt.name, err)
+		}
+	}
+}
+
+func TestFormatJsonAutoDecodeUnicode(t *testing.T) {
+	got, err := NewJsonProcessor().FormatJson(`{"\u0022k\u0022":"\\u0041 \u0041 \ud83d"}`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"\\u0022k\\u0022\": \"\\\\u0041 A \\ud83d\"\n}"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError 带位置信息的语法错误，前端可据此在编辑器中标记出错位置
+type SyntaxError struct {
+	Message  string `json:"message"`  // 完整的错误信息，包含行列号
+	Reason   string `json:"reason"`   // 错误原因
+	Line     int    `json:"line"`     // 行号，从 1 开始
+	Column   int    `json:"column"`   // 列号，从 1 开始，按字符计数
+	Offset   int    `json:"offset"`   // 字节偏移量，从 0 开始
+	Expected string `json:"expected"` // 期望出现的内容
+	Found    string `json:"found"`    // 实际遇到的内容
+	Snippet  string `json:"snippet"`  // 出错位置附近的文本，第二行用 ^ 标出出错列
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+// newSyntaxError 根据字节偏移量构造语法错误
+func newSyntaxError(data []byte, offset int, expected string, reason string) *SyntaxError {
+	offset = min(max(offset, 0), len(data))
+	line, column := lineColumn(data, offset)
+	e := &SyntaxError{
+		Reason:   reason,
+		Line:     line,
+		Column:   column,
+		Offset:   offset,
+		Expected: expected,
+		Found:    describeFound(data, offset),
+		Snippet:  snippetAt(data, offset),
+	}
+	e.Message = fmt.Sprintf("第 %d 行第 %d 列: %s", line, column, reason)
+	if expected != "" {
+		e.Message += fmt.Sprintf("（期望 %s，实际为 %s）", expected, e.Found)
+	}
+	return e
+}
+
+// describeFound 描述出错位置的内容
+func describeFound(data []byte, offset int) string {
+	if offset >= len(data) {
+		return "文本结尾"
+	}
+	r, _ := utf8.DecodeRune(data[offset:])
+	switch r {
+	case '\n', '\r':
+		return "换行符"
+	case '\t':
+		return "制表符"
+	case ' ':
+		return "空格"
+	}
+	return strconv.QuoteRune(r)
+}
+
+// snippetAt 截取出错位置所在行的附近文本，并在下一行用 ^ 标出列位置
+func snippetAt(data []byte, offset int) string {
+	const context = 30
+	start := offset
+	for start > 0 && data[start-1] != '\n' {
+		start--
+	}
+	end := offset
+	for end < len(data) && data[end] != '\n' {
+		end++
+	}
+	before := []rune(strings.TrimRight(string(data[start:offset]), "\r"))
+	after := []rune(strings.TrimRight(string(data[offset:end]), "\r"))
+	prefix, suffix := "", ""
+	if len(before) > context {
+		before, prefix = before[len(before)-context:], "…"
+	}
+	if len(after) > context {
+		after, suffix = after[:context], "…"
+	}
+	text := prefix + string(before) + string(after) + suffix
+	marker := strings.Repeat(" ", utf8.RuneCountInString(prefix)+len(before)) + "^"
+	return strings.ReplaceAll(text, "\t", " ") + "\n" + marker
+}
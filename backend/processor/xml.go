@@ -1,107 +1,213 @@
 package processor
 
 import (
-	"bytes"
-	"encoding/xml"
-	"io"
+	"fmt"
 	"strings"
 )
 
+// 空元素的输出方式
+const (
+	XMLSelfClosingPreserve = "preserve" // 保持源文本中的写法
+	XMLSelfClosingAlways   = "always"   // 统一输出为 <a/>
+	XMLSelfClosingNever    = "never"    // 统一输出为 <a></a>
+)
+
+// XMLFormatOptions XML 格式化选项
+type XMLFormatOptions struct {
+	IndentSize       int            `json:"indentSize"`       // 缩进空格数，默认 2
+	UseTabs          bool           `json:"useTabs"`          // 使用制表符缩进
+	AttributePerLine bool           `json:"attributePerLine"` // 元素有多个属性时每个属性单独一行
+	SelfClosing      string         `json:"selfClosing"`      // 空元素的写法：preserve（默认）、always、never
+	Limits           XMLParseLimits `json:"limits"`           // 解析时的安全限制
+}
+
+// 实体引用的处理策略
+const (
+	XMLEntityExpand = "expand" // 展开内部实体，拒绝外部实体
+	XMLEntityKeep   = "keep"   // 不展开自定义实体，引用按原样保留
+	XMLEntityForbid = "forbid" // 不允许在 DOCTYPE 中声明实体
+)
+
+// XMLParseLimits 解析不可信 XML 时的安全限制，数值为 0 时使用默认值，负数表示不限制
+type XMLParseLimits struct {
+	MaxSize            int    `json:"maxSize"`            // 输入的最大字节数，默认 32 MiB
+	MaxDepth           int    `json:"maxDepth"`           // 元素的最大嵌套层数，默认 512
+	MaxTokens          int    `json:"maxTokens"`          // 标签、文本、注释等标记的最大数量，默认 1000000
+	MaxAttributes      int    `json:"maxAttributes"`      // 单个元素的最大属性数，默认 256
+	MaxEntityExpansion int    `json:"maxEntityExpansion"` // 自定义实体展开的总字节数上限，默认 1 MiB
+	Entities           string `json:"entities"`           // 实体处理策略：expand（默认）、keep、forbid
+}
+
+// normalize 填充默认值并检查实体策略
+func (l XMLParseLimits) normalize() (XMLParseLimits, error) {
+	defaults := []struct {
+		value *int
+		def   int
+	}{
+		{&l.MaxSize, 32 << 20},
+		{&l.MaxDepth, 512},
+		{&l.MaxTokens, 1000000},
+		{&l.MaxAttributes, 256},
+		{&l.MaxEntityExpansion, 1 << 20},
+	}
+	for _, d := range defaults {
+		if *d.value == 0 {
+			*d.value = d.def
+		}
+	}
+	switch l.Entities {
+	case "":
+		l.Entities = XMLEntityExpand
+	case XMLEntityExpand, XMLEntityKeep, XMLEntityForbid:
+	default:
+		return l, fmt.Errorf("不支持的实体处理策略: %s", l.Entities)
+	}
+	return l, nil
+}
+
+// exceeds 判断 n 是否超过限制，limit 为负数时不限制
+func exceeds(n, limit int) bool {
+	return limit >= 0 && n > limit
+}
+
 type XMLProcessor struct{}
 
 func NewXMLProcessor() *XMLProcessor {
 	return &XMLProcessor{}
 }
 
+// FormatXML 使用两个空格缩进格式化 XML
 func (x *XMLProcessor) FormatXML(input string) (string, error) {
-	var buf bytes.Buffer
-	decoder := xml.NewDecoder(strings.NewReader(strings.TrimSpace(input)))
-	encoder := xml.NewEncoder(&buf)
-	encoder.Indent("", "  ")
-
-	// 处理第一个元素
-	for {
-		token, err := decoder.Token()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", err
-		}
+	return x.FormatXMLWithOptions(input, XMLFormatOptions{})
+}
 
-		// 跳过空白字符
-		if charData, ok := token.(xml.CharData); ok {
-			if len(strings.TrimSpace(string(charData))) == 0 {
-				continue
-			}
-		}
+// FormatXMLWithOptions 按选项格式化 XML，命名空间前缀、CDATA、注释、处理指令和 DOCTYPE 按源文本原样保留
+func (x *XMLProcessor) FormatXMLWithOptions(input string, options XMLFormatOptions) (string, error) {
+	switch options.SelfClosing {
+	case "":
+		options.SelfClosing = XMLSelfClosingPreserve
+	case XMLSelfClosingPreserve, XMLSelfClosingAlways, XMLSelfClosingNever:
+	default:
+		return "", fmt.Errorf("不支持的空元素写法: %s", options.SelfClosing)
+	}
+	doc, err := parseXMLWithLimits([]byte(input), options.Limits)
+	if err != nil {
+		return "", err
+	}
+	indent := JsonFormatOptions{IndentSize: options.IndentSize, UseTabs: options.UseTabs}.indent()
+	w := &xmlWriter{indent: indent, attributePerLine: options.AttributePerLine, selfClosing: options.SelfClosing}
+	w.writeDocument(doc)
+	return w.b.String(), nil
+}
 
-		// 处理 XML 声明
-		if procInst, ok := token.(xml.ProcInst); ok && procInst.Target == "xml" {
-			encoder.EncodeToken(token)
-			buf.WriteString("\n")
-			continue
-		}
+// CompressXML 去除元素之间的空白，输出为一行
+func (x *XMLProcessor) CompressXML(input string) (string, error) {
+	return x.CompressXMLWithLimits(input, XMLParseLimits{})
+}
 
-		if err := encoder.EncodeToken(token); err != nil {
-			return "", err
-		}
+// CompressXMLWithLimits 按指定的解析限制压缩 XML
+func (x *XMLProcessor) CompressXMLWithLimits(input string, limits XMLParseLimits) (string, error) {
+	doc, err := parseXMLWithLimits([]byte(input), limits)
+	if err != nil {
+		return "", err
 	}
+	w := &xmlWriter{selfClosing: XMLSelfClosingPreserve}
+	w.writeDocument(doc)
+	return w.b.String(), nil
+}
 
-	if err := encoder.Flush(); err != nil {
-		return "", err
+// xmlWriter 将语法树输出为 XML 文本，元素之间的空白按缩进重新生成，其余内容保持源文本
+type xmlWriter struct {
+	b                strings.Builder
+	indent           string // 为空时输出压缩格式
+	attributePerLine bool
+	selfClosing      string
+}
+
+func (w *xmlWriter) newline(depth int) {
+	if w.indent == "" {
+		return
+	}
+	w.b.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.b.WriteString(w.indent)
 	}
+}
 
-	// 移除多余的空行
-	lines := strings.Split(buf.String(), "\n")
-	var result []string
-	for i := range len(lines) {
-		line := strings.TrimSpace(lines[i])
-		if line != "" || (i > 0 && i < len(lines)-1) {
-			result = append(result, lines[i])
+func (w *xmlWriter) writeDocument(doc *xmlNode) {
+	for i, child := range doc.children {
+		if i > 0 {
+			w.newline(0)
 		}
+		w.write(child, 0, false)
 	}
+}
 
-	// 去掉编码器自动添加的换行符
-	finalResult := strings.Join(result, "\n")
-	return strings.TrimSuffix(finalResult, "\n"), nil
+// write 输出节点，inline 为 true 时处于混合内容中，原样输出空白且不再缩进
+func (w *xmlWriter) write(n *xmlNode, depth int, inline bool) {
+	switch n.kind {
+	case xmlElement:
+		w.writeElement(n, depth, inline)
+	case xmlText:
+		w.b.WriteString(n.raw)
+	case xmlCData:
+		w.b.WriteString("<![CDATA[" + n.text + "]]>")
+	case xmlComment:
+		w.b.WriteString("<!--" + n.text + "-->")
+	case xmlProcInst:
+		w.b.WriteString("<?" + n.name.local + n.text + "?>")
+	case xmlDoctype:
+		w.b.WriteString("<!DOCTYPE" + n.text + ">")
+	}
 }
 
-func (x *XMLProcessor) CompressXML(input string) (string, error) {
-	var buf bytes.Buffer
-	decoder := xml.NewDecoder(strings.NewReader(strings.TrimSpace(input)))
-	encoder := xml.NewEncoder(&buf)
-
-	// 禁用自动换行
-	for {
-		token, err := decoder.Token()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", err
+func (w *xmlWriter) writeElement(n *xmlNode, depth int, inline bool) {
+	w.b.WriteString("<" + n.name.qualified())
+	perLine := w.attributePerLine && w.indent != "" && !inline && len(n.attrs) > 1
+	for _, a := range n.attrs {
+		if perLine {
+			w.newline(depth + 1)
+		} else {
+			w.b.WriteByte(' ')
 		}
+		w.b.WriteString(a.name.qualified() + "=" + string(a.quote) + a.raw + string(a.quote))
+	}
 
-		// 跳过空白字符
-		if _, ok := token.(xml.CharData); ok {
-			str := strings.TrimSpace(string(token.(xml.CharData)))
-			if str == "" {
-				continue
+	// 混合内容（包含非空白文本或 CDATA）以及 xml:space="preserve" 的元素，子节点原样输出
+	keepSpace := inline || n.preserveSpace()
+	if !keepSpace {
+		for _, child := range n.children {
+			if child.kind == xmlCData || (child.kind == xmlText && !child.isWhitespace()) {
+				keepSpace = true
+				break
 			}
 		}
-
-		if err := encoder.EncodeToken(token); err != nil {
-			return "", err
+	}
+	var children []*xmlNode
+	for _, child := range n.children {
+		if keepSpace || !child.isWhitespace() {
+			children = append(children, child)
 		}
 	}
 
-	if err := encoder.Flush(); err != nil {
-		return "", err
+	if len(children) == 0 {
+		if w.selfClosing == XMLSelfClosingAlways || (w.selfClosing == XMLSelfClosingPreserve && n.selfClosing) {
+			w.b.WriteString("/>")
+		} else {
+			w.b.WriteString("></" + n.name.qualified() + ">")
+		}
+		return
 	}
 
-	// 移除多余的空白字符
-	result := strings.ReplaceAll(buf.String(), ">  <", "><")
-	result = strings.ReplaceAll(result, "> <", "><")
-	// 去掉编码器自动添加的换行符
-	return strings.TrimSuffix(result, "\n"), nil
+	w.b.WriteByte('>')
+	for _, child := range children {
+		if !keepSpace {
+			w.newline(depth + 1)
+		}
+		w.write(child, depth+1, keepSpace)
+	}
+	if !keepSpace {
+		w.newline(depth)
+	}
+	w.b.WriteString("</" + n.name.qualified() + ">")
 }
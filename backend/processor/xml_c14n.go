@@ -0,0 +1,515 @@
+package processor
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+// XML 规范化方法
+const (
+	XMLC14N10  = "c14n"     // Canonical XML 1.0
+	XMLC14N11  = "c14n11"   // Canonical XML 1.1
+	XMLExcC14N = "exc-c14n" // Exclusive XML Canonicalization 1.0
+)
+
+// 规范化 XML 摘要算法
+const (
+	XMLHashNone   = ""
+	XMLHashSHA1   = "sha1"
+	XMLHashSHA256 = "sha256"
+	XMLHashSHA512 = "sha512"
+)
+
+// XMLCanonicalOptions XML 规范化选项
+type XMLCanonicalOptions struct {
+	Method            string            `json:"method"`            // 规范化方法：c14n（默认）、c14n11、exc-c14n
+	WithComments      bool              `json:"withComments"`      // 保留注释
+	InclusivePrefixes []string          `json:"inclusivePrefixes"` // exc-c14n 的 InclusiveNamespaces PrefixList，#default 表示默认命名空间
+	XPath             string            `json:"xpath"`             // 只规范化选中的第一个元素及其后代，为空时规范化整个文档
+	Namespaces        map[string]string `json:"namespaces"`        // XPath 中使用的前缀到命名空间 URI 的映射
+	Hash              string            `json:"hash"`              // 摘要算法：空（不计算）、sha1、sha256、sha512
+}
+
+// XMLCanonicalResult XML 规范化结果
+type XMLCanonicalResult struct {
+	Canonical    string `json:"canonical"`    // 规范化后的 XML
+	Digest       string `json:"digest"`       // 规范化结果 UTF-8 字节的摘要，十六进制小写
+	DigestBase64 string `json:"digestBase64"` // 同一摘要的 Base64 编码，对应 XML 签名中的 DigestValue
+}
+
+// CanonicalizeXML 按 Canonical XML 1.0/1.1 或 Exclusive C14N 输出规范化 XML，可用于排查 XML 签名的摘要不一致
+// 内部子集中 ATTLIST 声明的默认属性和非 CDATA 属性的空白规范化会被应用；不支持外部 DTD
+func (x *XMLProcessor) CanonicalizeXML(input string, options XMLCanonicalOptions) (*XMLCanonicalResult, error) {
+	switch options.Method {
+	case "":
+		options.Method = XMLC14N10
+	case XMLC14N10, XMLC14N11, XMLExcC14N:
+	default:
+		return nil, fmt.Errorf("不支持的规范化方法: %s", options.Method)
+	}
+	doc, err := parseXML([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &c14nWriter{method: options.Method, comments: options.WithComments, inclusive: map[string]bool{}}
+	for _, prefix := range options.InclusivePrefixes {
+		if prefix = strings.TrimSpace(prefix); prefix == "#default" {
+			prefix = ""
+		}
+		w.inclusive[prefix] = true
+	}
+	for _, child := range doc.children {
+		if child.kind == xmlDoctype {
+			w.attlists = dtdAttributeDecls(child.text)
+		}
+	}
+
+	if options.XPath != "" {
+		apex, err := selectXMLElement(doc, options.XPath, options.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+		w.element(apex, inScopeNamespaces(apex.parent), map[string]string{}, true)
+	} else {
+		w.document(doc)
+	}
+	result := &XMLCanonicalResult{Canonical: w.b.String()}
+
+	var sum []byte
+	switch strings.ToLower(options.Hash) {
+	case XMLHashNone:
+		return result, nil
+	case XMLHashSHA1:
+		digest := sha1.Sum([]byte(result.Canonical))
+		sum = digest[:]
+	case XMLHashSHA256:
+		digest := sha256.Sum256([]byte(result.Canonical))
+		sum = digest[:]
+	case XMLHashSHA512:
+		digest := sha512.Sum512([]byte(result.Canonical))
+		sum = digest[:]
+	default:
+		return nil, fmt.Errorf("不支持的摘要算法: %s", options.Hash)
+	}
+	result.Digest = hex.EncodeToString(sum)
+	result.DigestBase64 = base64.StdEncoding.EncodeToString(sum)
+	return result, nil
+}
+
+// selectXMLElement 返回 XPath 选中的第一个元素
+func selectXMLElement(doc *xmlNode, expression string, namespaces map[string]string) (el *xmlNode, err error) {
+	expr, err := xpath.CompileWithNS(expression, namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("XPath 语法错误: %v", err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			el, err = nil, fmt.Errorf("XPath 执行失败: %v", r)
+		}
+	}()
+	if it, ok := expr.Evaluate(newXMLNavigator(doc)).(*xpath.NodeIterator); ok {
+		for it.MoveNext() {
+			if nav := it.Current().(*xmlNavigator); nav.attr < 0 && nav.curr.kind == xmlElement {
+				return nav.curr, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("XPath %s 没有选中任何元素", expression)
+}
+
+// inScopeNamespaces 返回元素作用域内的命名空间绑定，键为前缀，默认命名空间的键为空字符串
+func inScopeNamespaces(n *xmlNode) map[string]string {
+	var chain []*xmlNode
+	for e := n; e != nil && e.kind == xmlElement; e = e.parent {
+		chain = append(chain, e)
+	}
+	scope := map[string]string{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		declareNamespaces(chain[i], scope)
+	}
+	return scope
+}
+
+// declareNamespaces 将元素上的命名空间声明加入作用域
+func declareNamespaces(el *xmlNode, scope map[string]string) {
+	for _, a := range el.attrs {
+		switch {
+		case a.name.prefix == "" && a.name.local == "xmlns":
+			scope[""] = a.value
+		case a.name.prefix == "xmlns":
+			scope[a.name.local] = a.value
+		}
+	}
+}
+
+// xmlAttDecl DTD 中 ATTLIST 声明的属性
+type xmlAttDecl struct {
+	name       string
+	cdata      bool   // 属性类型为 CDATA，值不做额外的空白规范化
+	value      string // 默认值
+	hasDefault bool
+}
+
+// dtdAttributeDecls 读取 DOCTYPE 内部子集中的 ATTLIST 声明，键为元素名
+func dtdAttributeDecls(doctype string) map[string][]xmlAttDecl {
+	decls := map[string][]xmlAttDecl{}
+	start, end := strings.IndexByte(doctype, '['), strings.LastIndexByte(doctype, ']')
+	if start < 0 || end < start {
+		return decls
+	}
+	subset := doctype[start+1 : end]
+	for i := 0; i < len(subset); {
+		rest := subset[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i += strings.Index(rest, "-->") + 3
+		case strings.HasPrefix(rest, "<?"):
+			i += strings.Index(rest, "?>") + 2
+		case strings.HasPrefix(rest, "<!"):
+			e := declEnd(rest)
+			if e < 0 {
+				return decls
+			}
+			if strings.HasPrefix(rest, "<!ATTLIST") {
+				element, attrs := attlistDecl(rest[len("<!ATTLIST"):e])
+				decls[element] = append(decls[element], attrs...)
+			}
+			i += e + 1
+		default:
+			i++
+		}
+	}
+	return decls
+}
+
+// attlistDecl 解析 <!ATTLIST 与 > 之间的内容
+func attlistDecl(decl string) (string, []xmlAttDecl) {
+	// 枚举类型的括号中可能有空白，先去掉以便按空白拆分
+	var b strings.Builder
+	depth := 0
+	var quote byte
+	for i := 0; i < len(decl); i++ {
+		c := decl[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth > 0 && isXMLSpace(c):
+			continue
+		}
+		b.WriteByte(c)
+	}
+	fields := declFields(b.String())
+	if len(fields) == 0 {
+		return "", nil
+	}
+	var attrs []xmlAttDecl
+	for i := 1; i+1 < len(fields); {
+		attr := xmlAttDecl{name: fields[i], cdata: fields[i+1] == "CDATA"}
+		i += 2
+		if fields[i-1] == "NOTATION" && i < len(fields) {
+			i++
+		}
+		if i >= len(fields) {
+			attrs = append(attrs, attr)
+			break
+		}
+		switch fields[i] {
+		case "#REQUIRED", "#IMPLIED":
+			i++
+		case "#FIXED":
+			i++
+			fallthrough
+		default:
+			if i < len(fields) && len(fields[i]) >= 2 {
+				attr.value, attr.hasDefault = decodeAttDefault(fields[i][1:len(fields[i])-1]), true
+			}
+			i++
+		}
+		attrs = append(attrs, attr)
+	}
+	return fields[0], attrs
+}
+
+// decodeAttDefault 展开默认值中的字符引用和预定义实体，并规范化空白
+func decodeAttDefault(raw string) string {
+	p := &xmlParser{data: []byte(raw), src: raw, entities: map[string]*xmlEntity{}}
+	if value, err := p.decode(raw, 0, true); err == nil {
+		return value
+	}
+	return raw
+}
+
+// c14nWriter 输出规范化 XML
+type c14nWriter struct {
+	b         strings.Builder
+	method    string
+	comments  bool
+	inclusive map[string]bool // exc-c14n 中按包含式规则处理的前缀
+	attlists  map[string][]xmlAttDecl
+}
+
+// document 输出整个文档：去掉 XML 声明和 DOCTYPE，根元素之外的注释和处理指令以换行分隔
+func (w *c14nWriter) document(doc *xmlNode) {
+	afterRoot := false
+	for _, child := range doc.children {
+		switch {
+		case child.kind == xmlElement:
+			w.element(child, map[string]string{}, map[string]string{}, false)
+			afterRoot = true
+		case child.kind == xmlComment && w.comments, child.kind == xmlProcInst && child.name.local != "xml":
+			if afterRoot {
+				w.b.WriteByte('\n')
+			}
+			w.node(child)
+			if !afterRoot {
+				w.b.WriteByte('\n')
+			}
+		}
+	}
+}
+
+// node 输出元素之外的节点
+func (w *c14nWriter) node(n *xmlNode) {
+	switch n.kind {
+	case xmlText:
+		// 文本中的 \r 只可能来自字符引用，需要保留
+		w.b.WriteString(escapeC14NText(n.text))
+	case xmlCData:
+		w.b.WriteString(escapeC14NText(normalizeNewlines(n.text)))
+	case xmlComment:
+		if w.comments {
+			w.b.WriteString("<!--" + normalizeNewlines(n.text) + "-->")
+		}
+	case xmlProcInst:
+		w.b.WriteString("<?" + n.name.local)
+		if data := strings.TrimLeft(normalizeNewlines(n.text), " \t\n"); data != "" {
+			w.b.WriteString(" " + data)
+		}
+		w.b.WriteString("?>")
+	}
+}
+
+func normalizeNewlines(s string) string {
+	if !strings.ContainsRune(s, '\r') {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// collapseSpaces 非 CDATA 类型的属性值去掉首尾空格并将连续空格合并为一个
+func collapseSpaces(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == ' ' }), " ")
+}
+
+// c14nAttr 待输出的属性
+type c14nAttr struct {
+	qualified string
+	space     string
+	local     string
+	value     string
+}
+
+// element 输出元素，parentScope 为父元素作用域内的命名空间，rendered 为输出的祖先元素已声明的命名空间
+// apex 为 true 时元素是文档子集的顶点，其祖先不在输出中
+func (w *c14nWriter) element(el *xmlNode, parentScope, rendered map[string]string, apex bool) {
+	scope := make(map[string]string, len(parentScope))
+	for prefix, uri := range parentScope {
+		scope[prefix] = uri
+	}
+	declareNamespaces(el, scope)
+
+	var candidates []string
+	if w.method == XMLExcC14N {
+		// 只输出元素名和属性中实际用到的命名空间，以及 InclusiveNamespaces 中的前缀
+		used := map[string]bool{el.name.prefix: true}
+		for _, a := range el.attrs {
+			if !a.isNamespaceDecl() && a.name.prefix != "" {
+				used[a.name.prefix] = true
+			}
+		}
+		for prefix := range w.inclusive {
+			if _, ok := scope[prefix]; ok {
+				used[prefix] = true
+			}
+		}
+		for prefix := range used {
+			candidates = append(candidates, prefix)
+		}
+	} else {
+		for prefix := range scope {
+			candidates = append(candidates, prefix)
+		}
+	}
+	sort.Strings(candidates)
+
+	childRendered := make(map[string]string, len(rendered))
+	for prefix, uri := range rendered {
+		childRendered[prefix] = uri
+	}
+	var decls []string
+	for _, prefix := range candidates {
+		uri, declared := scope[prefix]
+		if prefix == "xml" || (prefix != "" && !declared) {
+			continue
+		}
+		previous, renderedBefore := rendered[prefix]
+		if prefix == "" && uri == "" {
+			// 只有输出的祖先声明过非空的默认命名空间时才需要 xmlns=""
+			if previous == "" {
+				continue
+			}
+		} else if renderedBefore && previous == uri {
+			continue
+		}
+		childRendered[prefix] = uri
+		if prefix == "" {
+			decls = append(decls, ` xmlns="`+escapeC14NAttr(uri)+`"`)
+		} else {
+			decls = append(decls, ` xmlns:`+prefix+`="`+escapeC14NAttr(uri)+`"`)
+		}
+	}
+
+	attrs := w.attributes(el, apex)
+	w.b.WriteString("<" + el.name.qualified())
+	for _, decl := range decls {
+		w.b.WriteString(decl)
+	}
+	for _, a := range attrs {
+		w.b.WriteString(" " + a.qualified + `="` + escapeC14NAttr(a.value) + `"`)
+	}
+	w.b.WriteByte('>')
+	for _, child := range el.children {
+		if child.kind == xmlElement {
+			w.element(child, scope, childRendered, false)
+		} else {
+			w.node(child)
+		}
+	}
+	w.b.WriteString("</" + el.name.qualified() + ">")
+}
+
+// attributes 返回排序后的属性：先按命名空间 URI，再按本地名称
+// 应用 DTD 中的默认值和非 CDATA 属性的空白规范化；包含式规范化的子集顶点继承祖先的 xml:* 属性
+func (w *c14nWriter) attributes(el *xmlNode, apex bool) []c14nAttr {
+	var attrs []c14nAttr
+	present := map[string]bool{}
+	for _, a := range el.attrs {
+		if a.isNamespaceDecl() {
+			continue
+		}
+		attrs = append(attrs, c14nAttr{a.name.qualified(), a.name.space, a.name.local, a.value})
+		present[a.name.qualified()] = true
+	}
+	for _, decl := range w.attlists[el.name.qualified()] {
+		if present[decl.name] {
+			if !decl.cdata {
+				for i := range attrs {
+					if attrs[i].qualified == decl.name {
+						attrs[i].value = collapseSpaces(attrs[i].value)
+					}
+				}
+			}
+			continue
+		}
+		if decl.hasDefault {
+			value := decl.value
+			if !decl.cdata {
+				value = collapseSpaces(value)
+			}
+			prefix, local, found := strings.Cut(decl.name, ":")
+			space := ""
+			if found {
+				space, _ = el.lookupNamespace(prefix)
+			} else {
+				local = prefix
+			}
+			attrs = append(attrs, c14nAttr{decl.name, space, local, value})
+			present[decl.name] = true
+		}
+	}
+
+	if apex && w.method != XMLExcC14N {
+		attrs = w.inheritXMLAttributes(el, attrs, present)
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if attrs[i].space != attrs[j].space {
+			return attrs[i].space < attrs[j].space
+		}
+		return attrs[i].local < attrs[j].local
+	})
+	return attrs
+}
+
+// inheritXMLAttributes 子集顶点继承祖先中最近的 xml:* 属性
+// C14N 1.1 不继承 xml:id，并将各级 xml:base 按 URI 解析规则合并
+func (w *c14nWriter) inheritXMLAttributes(el *xmlNode, attrs []c14nAttr, present map[string]bool) []c14nAttr {
+	var bases []string
+	for e := el.parent; e != nil && e.kind == xmlElement; e = e.parent {
+		for _, a := range e.attrs {
+			if a.name.space != xmlNamespace {
+				continue
+			}
+			switch {
+			case w.method == XMLC14N11 && a.name.local == "id":
+			case w.method == XMLC14N11 && a.name.local == "base":
+				bases = append(bases, a.value)
+			case !present[a.name.qualified()]:
+				attrs = append(attrs, c14nAttr{a.name.qualified(), xmlNamespace, a.name.local, a.value})
+				present[a.name.qualified()] = true
+			}
+		}
+	}
+	if len(bases) == 0 {
+		return attrs
+	}
+
+	base := bases[len(bases)-1]
+	for i := len(bases) - 2; i >= 0; i-- {
+		base = resolveXMLBase(base, bases[i])
+	}
+	for i := range attrs {
+		if attrs[i].space == xmlNamespace && attrs[i].local == "base" {
+			attrs[i].value = resolveXMLBase(base, attrs[i].value)
+			return attrs
+		}
+	}
+	return append(attrs, c14nAttr{"xml:base", xmlNamespace, "base", base})
+}
+
+// resolveXMLBase 按 RFC 3986 将 ref 相对 base 解析，无法解析时返回 ref
+func resolveXMLBase(base, ref string) string {
+	b, errB := url.Parse(base)
+	r, errR := url.Parse(ref)
+	if errB != nil || errR != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// escapeC14NText 按规范化规则转义文本
+func escapeC14NText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;").Replace(s)
+}
+
+// escapeC14NAttr 按规范化规则转义属性值
+func escapeC14NAttr(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;").Replace(s)
+}
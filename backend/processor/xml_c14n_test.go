@@ -0,0 +1,243 @@
+package processor
+
+import "testing"
+
+// W3C Canonical XML 1.0 第 3 节的示例文档和期望输出
+// 3.5 中的外部实体 ent2 改为内部实体声明（不解析外部实体），替换文本与示例约定的 world.txt 内容相同
+// 3.7 的节点集表达式使用 namespace 轴和 id()，XPath 引擎不支持，未包含在内
+var c14nSpecExamples = []struct {
+	name         string
+	input        string
+	want         string
+	wantComments string // 为空时与 want 相同
+}{
+	{
+		name: "3.1 PIs, Comments, and Outside of Document Element",
+		input: `<?xml version="1.0"?>
+
+<?xml-stylesheet   href="doc.xsl"
+   type="text/xsl"   ?>
+
+<!DOCTYPE doc SYSTEM "doc.dtd">
+
+<doc>Hello, world!<!-- Comment 1 --></doc>
+
+<?pi-without-data     ?>
+
+<!-- Comment 2 -->
+
+<!-- Comment 3 -->`,
+		want: `<?xml-stylesheet href="doc.xsl"
+   type="text/xsl"   ?>
+<doc>Hello, world!</doc>
+<?pi-without-data?>`,
+		wantComments: `<?xml-stylesheet href="doc.xsl"
+   type="text/xsl"   ?>
+<doc>Hello, world!<!-- Comment 1 --></doc>
+<?pi-without-data?>
+<!-- Comment 2 -->
+<!-- Comment 3 -->`,
+	},
+	{
+		name: "3.2 Whitespace in Document Content",
+		input: `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`,
+		want: `<doc>
+   <clean>   </clean>
+   <dirty>   A   B   </dirty>
+   <mixed>
+      A
+      <clean>   </clean>
+      B
+      <dirty>   A   B   </dirty>
+      C
+   </mixed>
+</doc>`,
+	},
+	{
+		name: "3.3 Start and End Tags",
+		input: `<!DOCTYPE doc [<!ATTLIST e9 attr CDATA "default">]>
+<doc>
+   <e1   />
+   <e2   ></e2>
+   <e3   name = "elem3"   id="elem3"   />
+   <e4   name="elem4"   id="elem4"   ></e4>
+   <e5 a:attr="out" b:attr="sorted" attr2="all" attr="I'm"
+      xmlns:b="http://www.ietf.org"
+      xmlns:a="http://www.w3.org"
+      xmlns="http://example.org"/>
+   <e6 xmlns="" xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="" xmlns:a="http://www.w3.org">
+            <e9 xmlns="" xmlns:a="http://www.ietf.org"/>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+		want: `<doc>
+   <e1></e1>
+   <e2></e2>
+   <e3 id="elem3" name="elem3"></e3>
+   <e4 id="elem4" name="elem4"></e4>
+   <e5 xmlns="http://example.org" xmlns:a="http://www.w3.org" xmlns:b="http://www.ietf.org" attr="I'm" attr2="all" b:attr="sorted" a:attr="out"></e5>
+   <e6 xmlns:a="http://www.w3.org">
+      <e7 xmlns="http://www.ietf.org">
+         <e8 xmlns="">
+            <e9 xmlns:a="http://www.ietf.org" attr="default"></e9>
+         </e8>
+      </e7>
+   </e6>
+</doc>`,
+	},
+	{
+		name: "3.4 Character Modifications and Character References",
+		input: `<!DOCTYPE doc [
+<!ATTLIST normId id ID #IMPLIED>
+<!ATTLIST normNames attr NMTOKENS #IMPLIED>
+]>
+<doc>
+   <text>First line&#x0d;&#10;Second line</text>
+   <value>&#x32;</value>
+   <compute><![CDATA[value>"0" && value<"10" ?"valid":"error"]]></compute>
+   <compute expr='value>"0" &amp;&amp; value&lt;"10" ?"valid":"error"'>valid</compute>
+   <norm attr=' &apos;   &#x20;&#13;&#xa;&#9;   &apos; '/>
+   <normNames attr='   A   &#x20;&#13;&#xa;&#9;   B   '/>
+   <normId id=' &apos;   &#x20;&#13;&#xa;&#9;   &apos; '/>
+</doc>`,
+		want: `<doc>
+   <text>First line&#xD;
+Second line</text>
+   <value>2</value>
+   <compute>value&gt;"0" &amp;&amp; value&lt;"10" ?"valid":"error"</compute>
+   <compute expr="value>&quot;0&quot; &amp;&amp; value&lt;&quot;10&quot; ?&quot;valid&quot;:&quot;error&quot;">valid</compute>
+   <norm attr=" '    &#xD;&#xA;&#x9;   ' "></norm>
+   <normNames attr="A &#xD;&#xA;&#x9; B"></normNames>
+   <normId id="' &#xD;&#xA;&#x9; '"></normId>
+</doc>`,
+	},
+	{
+		name: "3.5 Entity References",
+		input: `<!DOCTYPE doc [
+<!ATTLIST doc attrExtEnt ENTITY #IMPLIED>
+<!ENTITY ent1 "Hello">
+<!ENTITY ent2 "world">
+<!ENTITY entExt SYSTEM "earth.gif" NDATA gif>
+<!NOTATION gif SYSTEM "viewgif.exe">
+]>
+<doc attrExtEnt="entExt">
+   &ent1;, &ent2;!
+</doc>
+
+<!-- Let world.txt contain "world" (excluding the quotes) -->`,
+		want: `<doc attrExtEnt="entExt">
+   Hello, world!
+</doc>`,
+		wantComments: `<doc attrExtEnt="entExt">
+   Hello, world!
+</doc>
+<!-- Let world.txt contain "world" (excluding the quotes) -->`,
+	},
+	{
+		name: "3.6 UTF-8 Encoding",
+		input: `<?xml version="1.0" encoding="ISO-8859-1"?>
+<doc>&#169;</doc>`,
+		want: "<doc>©</doc>",
+	},
+}
+
+func TestCanonicalizeXMLSpecExamples(t *testing.T) {
+	x := NewXMLProcessor()
+	for _, tt := range c14nSpecExamples {
+		for _, method := range []string{XMLC14N10, XMLC14N11} {
+			for _, comments := range []bool{false, true} {
+				want := tt.want
+				if comments && tt.wantComments != "" {
+					want = tt.wantComments
+				}
+				result, err := x.CanonicalizeXML(tt.input, XMLCanonicalOptions{Method: method, WithComments: comments})
+				if err != nil {
+					t.Errorf("%s (%s, comments=%v): %v", tt.name, method, comments, err)
+					continue
+				}
+				if result.Canonical != want {
+					t.Errorf("%s (%s, comments=%v):\ngot:\n%s\nwant:\n%s", tt.name, method, comments, result.Canonical, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCanonicalizeXMLExternalEntity(t *testing.T) {
+	input := `<!DOCTYPE doc [<!ENTITY ent2 SYSTEM "world.txt">]><doc>&ent2;</doc>`
+	if _, err := NewXMLProcessor().CanonicalizeXML(input, XMLCanonicalOptions{}); err == nil {
+		t.Fatal("expected an error for an external entity reference")
+	}
+}
+
+// Exclusive XML Canonicalization 1.0 第 2.2 节的示例，对 n1:elem2 子树规范化
+func TestCanonicalizeXMLExclusiveSpecExamples(t *testing.T) {
+	const first = `<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org">
+   <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"/>
+   </n1:elem2>
+</n0:local>`
+	const second = `<n2:pdu xmlns:n1="http://example.com"
+           xmlns:n2="http://foo.example"
+           xml:lang="fr"
+           xml:space="retain">
+   <n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"/>
+   </n1:elem2>
+</n2:pdu>`
+	const exclusive = `<n1:elem2 xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+   </n1:elem2>`
+
+	tests := []struct {
+		name      string
+		input     string
+		method    string
+		inclusive []string
+		want      string
+	}{
+		{"first c14n", first, XMLC14N10, nil, `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xmlns:n3="ftp://example.org" xml:lang="en">
+      <n3:stuff></n3:stuff>
+   </n1:elem2>`},
+		{"second c14n", second, XMLC14N10, nil, `<n1:elem2 xmlns:n1="http://example.net" xmlns:n2="http://foo.example" xml:lang="en" xml:space="retain">
+      <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+   </n1:elem2>`},
+		{"first exc-c14n", first, XMLExcC14N, nil, exclusive},
+		{"second exc-c14n", second, XMLExcC14N, nil, exclusive},
+		{"first exc-c14n with InclusiveNamespaces n0", first, XMLExcC14N, []string{"n0"}, `<n1:elem2 xmlns:n0="foo:bar" xmlns:n1="http://example.net" xml:lang="en">
+      <n3:stuff xmlns:n3="ftp://example.org"></n3:stuff>
+   </n1:elem2>`},
+	}
+	x := NewXMLProcessor()
+	for _, tt := range tests {
+		for _, comments := range []bool{false, true} {
+			result, err := x.CanonicalizeXML(tt.input, XMLCanonicalOptions{
+				Method:            tt.method,
+				WithComments:      comments,
+				InclusivePrefixes: tt.inclusive,
+				XPath:             "//n1:elem2",
+				Namespaces:        map[string]string{"n1": "http://example.net"},
+			})
+			if err != nil {
+				t.Errorf("%s (comments=%v): %v", tt.name, comments, err)
+				continue
+			}
+			if result.Canonical != tt.want {
+				t.Errorf("%s (comments=%v):\ngot:\n%s\nwant:\n%s", tt.name, comments, result.Canonical, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package processor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// XMLDiffOptions XML 结构化对比选项
+type XMLDiffOptions struct {
+	IgnorePrefixes     bool `json:"ignorePrefixes"`     // 按命名空间 URI 和本地名比较元素与属性，忽略前缀的差异
+	IgnoreComments     bool `json:"ignoreComments"`     // 忽略注释
+	PreserveWhitespace bool `json:"preserveWhitespace"` // 文本按原样比较，默认去掉首尾空白并合并连续空白；只有空白的文本总是被忽略
+}
+
+// XMLChange XML 的单条差异
+type XMLChange struct {
+	Op        string `json:"op"`                 // add、remove、change
+	Type      string `json:"type"`               // element、attribute、text、comment、processing-instruction
+	Path      string `json:"path"`               // 节点的位置路径，新增的节点为右侧文档中的路径，其余为左侧文档中的路径
+	OldValue  string `json:"oldValue,omitempty"` // 原值，元素为 XML 片段
+	NewValue  string `json:"newValue,omitempty"` // 新值，元素为 XML 片段
+	LeftLine  int    `json:"leftLine"`           // 节点在左侧文档中的行号，新增时为 0
+	RightLine int    `json:"rightLine"`          // 节点在右侧文档中的行号，删除时为 0
+	Message   string `json:"message"`            // 可读的差异描述
+}
+
+// XMLDiffResult XML 结构化对比结果
+type XMLDiffResult struct {
+	Equal   bool        `json:"equal"`   // 两个文档语义相同
+	Changes []XMLChange `json:"changes"` // 差异列表，按文档顺序排列
+}
+
+// DiffXML 语义化对比两个 XML 文档，忽略属性顺序、格式化空白、实体与 CDATA 写法的差异
+// 子节点先按完全相同的内容对齐，剩余部分再按元素名对齐后递归比较
+func (x *XMLProcessor) DiffXML(left string, right string, options XMLDiffOptions) (*XMLDiffResult, error) {
+	leftDoc, err := parseXML([]byte(left))
+	if err != nil {
+		return nil, fmt.Errorf("左侧 XML 无效: %w", err)
+	}
+	rightDoc, err := parseXML([]byte(right))
+	if err != nil {
+		return nil, fmt.Errorf("右侧 XML 无效: %w", err)
+	}
+
+	d := &xmlDiffer{options: options, texts: map[*xmlNode]string{}, signatures: map[*xmlNode]uint64{}, identical: map[[2]*xmlNode]bool{}, changes: []XMLChange{}}
+	d.element(leftDoc.root(), rightDoc.root())
+	return &XMLDiffResult{Equal: len(d.changes) == 0, Changes: d.changes}, nil
+}
+
+type xmlDiffer struct {
+	options    XMLDiffOptions
+	texts      map[*xmlNode]string // 合并相邻文本和 CDATA 并规范化空白后的文本，键为其中第一个节点
+	signatures map[*xmlNode]uint64
+	identical  map[[2]*xmlNode]bool // 摘要相同的节点对逐项比较的结果
+	changes    []XMLChange
+}
+
+// nameKey 返回用于比较的元素名或属性名
+func (d *xmlDiffer) nameKey(n xmlName) string {
+	if d.options.IgnorePrefixes {
+		return "{" + n.space + "}" + n.local
+	}
+	return n.qualified() + "{" + n.space + "}"
+}
+
+// content 返回参与比较的子节点，相邻的文本和 CDATA 合并为一个文本节点
+func (d *xmlDiffer) content(el *xmlNode) []*xmlNode {
+	var nodes []*xmlNode
+	var text *xmlNode
+	var b strings.Builder
+	flush := func() {
+		if text == nil {
+			return
+		}
+		value := b.String()
+		if !d.options.PreserveWhitespace {
+			value = strings.Join(strings.Fields(value), " ")
+		}
+		if !isXMLWhitespace(value) {
+			d.texts[text] = value
+			nodes = append(nodes, text)
+		}
+		text = nil
+		b.Reset()
+	}
+	for _, child := range el.children {
+		switch child.kind {
+		case xmlText, xmlCData:
+			if text == nil {
+				text = child
+			}
+			b.WriteString(child.text)
+			continue
+		case xmlComment:
+			if d.options.IgnoreComments {
+				continue
+			}
+		}
+		flush()
+		nodes = append(nodes, child)
+	}
+	flush()
+	return nodes
+}
+
+func isXMLWhitespace(s string) bool {
+	return strings.Trim(s, " \t\r\n") == ""
+}
+
+// kindName 返回节点类型在差异中的名称
+func kindName(n *xmlNode) string {
+	switch n.kind {
+	case xmlElement:
+		return "element"
+	case xmlComment:
+		return "comment"
+	case xmlProcInst:
+		return "processing-instruction"
+	}
+	return "text"
+}
+
+// key 返回对齐剩余子节点时使用的键：元素按名称，其他节点按类型
+func (d *xmlDiffer) key(n *xmlNode) string {
+	switch n.kind {
+	case xmlElement:
+		return "e" + d.nameKey(n.name)
+	case xmlProcInst:
+		return "p" + n.name.local
+	}
+	return kindName(n)
+}
+
+// value 返回非元素节点用于比较和展示的值
+func (d *xmlDiffer) value(n *xmlNode) string {
+	switch n.kind {
+	case xmlText, xmlCData:
+		return d.texts[n]
+	case xmlProcInst:
+		return strings.TrimSpace(n.text)
+	}
+	return n.text
+}
+
+// signature 返回节点内容的摘要，摘要不同的节点一定不同，摘要相同时由 same 逐项确认
+func (d *xmlDiffer) signature(n *xmlNode) uint64 {
+	if s, ok := d.signatures[n]; ok {
+		return s
+	}
+	h := fnv.New64a()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	write(d.key(n))
+	if n.kind == xmlElement {
+		attrs := d.attributes(n)
+		keys := make([]string, 0, len(attrs))
+		for key := range attrs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			write(key)
+			write(attrs[key].value)
+		}
+		for _, child := range d.content(n) {
+			write(fmt.Sprintf("%x", d.signature(child)))
+		}
+	} else {
+		write(d.value(n))
+	}
+	d.signatures[n] = h.Sum64()
+	return d.signatures[n]
+}
+
+// same 判断两个节点的内容是否完全相同，摘要相同时再逐项比较，避免哈希碰撞把不同的节点当作相同
+func (d *xmlDiffer) same(a, b *xmlNode) bool {
+	if d.signature(a) != d.signature(b) {
+		return false
+	}
+	pair := [2]*xmlNode{a, b}
+	if result, ok := d.identical[pair]; ok {
+		return result
+	}
+	result := d.key(a) == d.key(b)
+	if result && a.kind != xmlElement {
+		result = d.value(a) == d.value(b)
+	} else if result {
+		leftAttrs, rightAttrs := d.attributes(a), d.attributes(b)
+		result = len(leftAttrs) == len(rightAttrs)
+		for key, attr := range leftAttrs {
+			if other, ok := rightAttrs[key]; !ok || other.value != attr.value {
+				result = false
+				break
+			}
+		}
+		left, right := d.content(a), d.content(b)
+		result = result && len(left) == len(right)
+		for i := 0; result && i < len(left); i++ {
+			result = d.same(left[i], right[i])
+		}
+	}
+	d.identical[pair] = result
+	return result
+}
+
+// attributes 返回参与比较的属性，命名空间声明不参与比较
+func (d *xmlDiffer) attributes(el *xmlNode) map[string]*xmlAttr {
+	attrs := map[string]*xmlAttr{}
+	for _, a := range el.attrs {
+		if !a.isNamespaceDecl() {
+			attrs[d.nameKey(a.name)] = a
+		}
+	}
+	return attrs
+}
+
+func (d *xmlDiffer) add(n *xmlNode) {
+	value := d.display(n)
+	d.changes = append(d.changes, XMLChange{
+		Op:        "add",
+		Type:      kindName(n),
+		Path:      xmlLocationPath(n),
+		NewValue:  value,
+		RightLine: n.line,
+		Message:   fmt.Sprintf("新增%s %s", kindLabel(n), abbreviate(value)),
+	})
+}
+
+func (d *xmlDiffer) remove(n *xmlNode) {
+	value := d.display(n)
+	d.changes = append(d.changes, XMLChange{
+		Op:       "remove",
+		Type:     kindName(n),
+		Path:     xmlLocationPath(n),
+		OldValue: value,
+		LeftLine: n.line,
+		Message:  fmt.Sprintf("删除%s %s", kindLabel(n), abbreviate(value)),
+	})
+}
+
+func (d *xmlDiffer) change(a, b *xmlNode) {
+	oldValue, newValue := d.display(a), d.display(b)
+	d.changes = append(d.changes, XMLChange{
+		Op:        "change",
+		Type:      kindName(a),
+		Path:      xmlLocationPath(a),
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		LeftLine:  a.line,
+		RightLine: b.line,
+		Message:   fmt.Sprintf("修改%s: %s → %s", kindLabel(a), abbreviate(oldValue), abbreviate(newValue)),
+	})
+}
+
+// display 返回节点在差异中展示的值
+func (d *xmlDiffer) display(n *xmlNode) string {
+	if n.kind == xmlElement {
+		return serializeXMLFragment(n)
+	}
+	return d.value(n)
+}
+
+func kindLabel(n *xmlNode) string {
+	switch n.kind {
+	case xmlElement:
+		return "元素"
+	case xmlComment:
+		return "注释"
+	case xmlProcInst:
+		return "处理指令"
+	}
+	return "文本"
+}
+
+// element 比较两个元素
+func (d *xmlDiffer) element(a, b *xmlNode) {
+	if d.nameKey(a.name) != d.nameKey(b.name) {
+		d.change(a, b)
+		from, to := "<"+a.name.qualified()+">", "<"+b.name.qualified()+">"
+		if from == to {
+			from, to = from+namespaceLabel(a.name.space), to+namespaceLabel(b.name.space)
+		}
+		d.changes[len(d.changes)-1].Message = fmt.Sprintf("元素 %s 改为 %s", from, to)
+		return
+	}
+	d.diffAttributes(a, b)
+	d.children(a, b)
+}
+
+// namespaceLabel 返回元素名相同而命名空间不同时展示的命名空间
+func namespaceLabel(space string) string {
+	if space == "" {
+		return "（无命名空间）"
+	}
+	return "（命名空间 " + space + "）"
+}
+
+func (d *xmlDiffer) diffAttributes(a, b *xmlNode) {
+	leftAttrs, rightAttrs := d.attributes(a), d.attributes(b)
+	path := xmlLocationPath(a)
+	for _, attr := range a.attrs {
+		if attr.isNamespaceDecl() {
+			continue
+		}
+		other, ok := rightAttrs[d.nameKey(attr.name)]
+		switch {
+		case !ok:
+			d.changes = append(d.changes, XMLChange{
+				Op:       "remove",
+				Type:     "attribute",
+				Path:     path + "/@" + attr.name.qualified(),
+				OldValue: attr.value,
+				LeftLine: attr.line,
+				Message:  fmt.Sprintf("删除属性 %s=%q", attr.name.qualified(), abbreviate(attr.value)),
+			})
+		case other.value != attr.value:
+			d.changes = append(d.changes, XMLChange{
+				Op:        "change",
+				Type:      "attribute",
+				Path:      path + "/@" + attr.name.qualified(),
+				OldValue:  attr.value,
+				NewValue:  other.value,
+				LeftLine:  attr.line,
+				RightLine: other.line,
+				Message:   fmt.Sprintf("修改属性 %s: %q → %q", attr.name.qualified(), abbreviate(attr.value), abbreviate(other.value)),
+			})
+		}
+	}
+	for _, attr := range b.attrs {
+		if attr.isNamespaceDecl() {
+			continue
+		}
+		if _, ok := leftAttrs[d.nameKey(attr.name)]; !ok {
+			d.changes = append(d.changes, XMLChange{
+				Op:        "add",
+				Type:      "attribute",
+				Path:      xmlLocationPath(b) + "/@" + attr.name.qualified(),
+				NewValue:  attr.value,
+				RightLine: attr.line,
+				Message:   fmt.Sprintf("新增属性 %s=%q", attr.name.qualified(), abbreviate(attr.value)),
+			})
+		}
+	}
+}
+
+// children 比较子节点：先对齐内容完全相同的节点，再在每个未对齐的区间内按名称对齐
+func (d *xmlDiffer) children(a, b *xmlNode) {
+	left, right := d.content(a), d.content(b)
+	same := lcsIndexPairs(len(left), len(right), func(i, k int) bool {
+		return d.same(left[i], right[k])
+	})
+	alignGaps(len(left), len(right), same, func(i0, i1, k0, k1 int) {
+		d.gap(left[i0:i1], right[k0:k1])
+	})
+}
+
+// gap 比较一段没有完全相同节点的子节点区间
+func (d *xmlDiffer) gap(left, right []*xmlNode) {
+	pairs := lcsIndexPairs(len(left), len(right), func(i, k int) bool {
+		return d.key(left[i]) == d.key(right[k])
+	})
+	i, k := 0, 0
+	for _, pair := range append(pairs, [2]int{len(left), len(right)}) {
+		for ; i < pair[0]; i++ {
+			d.remove(left[i])
+		}
+		for ; k < pair[1]; k++ {
+			d.add(right[k])
+		}
+		if pair[0] < len(left) {
+			if left[i].kind == xmlElement {
+				d.element(left[i], right[k])
+			} else if d.value(left[i]) != d.value(right[k]) {
+				d.change(left[i], right[k])
+			}
+			i, k = i+1, k+1
+		}
+	}
+}
+
+// alignGaps 按匹配的下标对依次回调两侧之间未匹配的区间，两侧都为空的区间被跳过
+func alignGaps(n, m int, pairs [][2]int, gap func(i0, i1, k0, k1 int)) {
+	i, k := 0, 0
+	for _, pair := range append(pairs, [2]int{n, m}) {
+		if i < pair[0] || k < pair[1] {
+			gap(i, pair[0], k, pair[1])
+		}
+		i, k = pair[0]+1, pair[1]+1
+	}
+}
@@ -0,0 +1,76 @@
+package processor
+
+import "testing"
+
+func TestDiffXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		left    string
+		right   string
+		options XMLDiffOptions
+		changes []string // 期望的 op 和路径
+	}{
+		{"attribute order and whitespace", `<a x="1" y="2"> <b>t  u</b> </a>`, `<a y="2" x="1"><b> t u </b></a>`, XMLDiffOptions{}, nil},
+		{"prefixes ignored", `<p:a xmlns:p="urn:x"><p:b/></p:a>`, `<q:a xmlns:q="urn:x"><q:b/></q:a>`, XMLDiffOptions{IgnorePrefixes: true}, nil},
+		{"changed text", `<a><b>1</b><b>2</b></a>`, `<a><b>1</b><b>3</b></a>`, XMLDiffOptions{}, []string{"change /a[1]/b[2]/text()[1]"}},
+		{"added element", `<a><b/></a>`, `<a><b/><c/></a>`, XMLDiffOptions{}, []string{"add /a[1]/c[1]"}},
+		{"removed attribute", `<a k="v"/>`, `<a/>`, XMLDiffOptions{}, []string{"remove /a[1]/@k"}},
+	}
+	x := NewXMLProcessor()
+	for _, tt := range tests {
+		result, err := x.DiffXML(tt.left, tt.right, tt.options)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, c := range result.Changes {
+			got = append(got, c.Op+" "+c.Path)
+		}
+		if len(got) != len(tt.changes) || result.Equal != (len(tt.changes) == 0) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.changes)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.changes[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.changes)
+				break
+			}
+		}
+	}
+}
+
+// 摘要碰撞时不能把内容不同的节点当作相同
+func TestDiffXMLSignatureCollision(t *testing.T) {
+	leftDoc, err := parseXML([]byte(`<a><b>1</b><c k="x"/></a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rightDoc, err := parseXML([]byte(`<a><b>2</b><c k="y"/></a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &xmlDiffer{texts: map[*xmlNode]string{}, signatures: map[*xmlNode]uint64{}, identical: map[[2]*xmlNode]bool{}, changes: []XMLChange{}}
+	left, right := d.content(leftDoc.root()), d.content(rightDoc.root())
+	for i := range left {
+		d.signatures[left[i]], d.signatures[right[i]] = uint64(i), uint64(i)
+	}
+	d.element(leftDoc.root(), rightDoc.root())
+	if len(d.changes) != 2 {
+		t.Fatalf("got %d changes, want 2: %+v", len(d.changes), d.changes)
+	}
+}
+
+// 限定名相同而命名空间不同时，差异描述中给出命名空间
+func TestDiffXMLNamespaceChange(t *testing.T) {
+	result, err := NewXMLProcessor().DiffXML(`<a xmlns="urn:x"/>`, `<a xmlns="urn:y"/>`, XMLDiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 {
+		t.Fatalf("got %d changes, want 1: %+v", len(result.Changes), result.Changes)
+	}
+	want := "元素 <a>（命名空间 urn:x） 改为 <a>（命名空间 urn:y）"
+	if got := result.Changes[0].Message; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,482 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XML 与 JSON 之间的映射约定
+const (
+	XMLJsonConventionDefault    = "default"    // 属性加 @ 前缀，有属性或子元素时文本放在 #text 中，只有文本的元素直接映射为值
+	XMLJsonConventionBadgerFish = "badgerfish" // 元素始终映射为对象，属性加 @ 前缀，文本放在 $ 中，命名空间声明放在 @xmlns 中
+	XMLJsonConventionParker     = "parker"     // 忽略属性和根元素名，只保留元素结构和文本
+)
+
+// XML 转 JSON 时命名空间的处理方式
+const (
+	XMLNamespaceKeep  = "keep"  // 保留前缀，命名空间声明作为属性输出
+	XMLNamespaceStrip = "strip" // 去掉前缀和命名空间声明
+)
+
+// xmlJSONArrayItem JSON 数组中的数组转为 XML 时使用的元素名
+const xmlJSONArrayItem = "item"
+
+// XMLJsonOptions XML 与 JSON 互相转换的选项
+type XMLJsonOptions struct {
+	Convention      string   `json:"convention"`      // 映射约定：default（默认）、badgerfish、parker
+	AttributePrefix string   `json:"attributePrefix"` // default 约定中属性键的前缀，默认 @
+	TextKey         string   `json:"textKey"`         // default 约定中文本内容的键，默认 #text
+	Namespaces      string   `json:"namespaces"`      // XML 转 JSON 时命名空间的处理方式：keep（默认）、strip
+	AlwaysArray     []string `json:"alwaysArray"`     // 即使只出现一次也输出为数组的元素名，可以是限定名或本地名
+	InferTypes      bool     `json:"inferTypes"`      // XML 转 JSON 时将数字和 true/false 文本转为 JSON 数字和布尔值
+	RootName        string   `json:"rootName"`        // JSON 转 XML 时的根元素名；为空时 JSON 必须是只有一个键的对象，以该键为根元素（parker 约定默认为 root）
+	XmlDeclaration  bool     `json:"xmlDeclaration"`  // JSON 转 XML 时输出 XML 声明
+	IndentSize      int      `json:"indentSize"`      // 缩进空格数，默认 2
+	UseTabs         bool     `json:"useTabs"`         // 使用制表符缩进
+}
+
+// normalize 校验选项并填充默认值
+func (o *XMLJsonOptions) normalize() error {
+	switch o.Convention {
+	case "":
+		o.Convention = XMLJsonConventionDefault
+	case XMLJsonConventionDefault, XMLJsonConventionBadgerFish, XMLJsonConventionParker:
+	default:
+		return fmt.Errorf("不支持的映射约定: %s", o.Convention)
+	}
+	switch o.Namespaces {
+	case "":
+		o.Namespaces = XMLNamespaceKeep
+	case XMLNamespaceKeep, XMLNamespaceStrip:
+	default:
+		return fmt.Errorf("不支持的命名空间处理方式: %s", o.Namespaces)
+	}
+	if o.AttributePrefix == "" {
+		o.AttributePrefix = "@"
+	}
+	if o.TextKey == "" {
+		o.TextKey = "#text"
+	}
+	if o.AttributePrefix == o.TextKey {
+		return fmt.Errorf("属性前缀不能与文本键相同")
+	}
+	return nil
+}
+
+// XmlToJson 按映射约定将 XML 转换为 JSON，同名的兄弟元素合并为数组；注释和处理指令被忽略
+func (x *XMLProcessor) XmlToJson(input string, options XMLJsonOptions) (string, error) {
+	if err := options.normalize(); err != nil {
+		return "", err
+	}
+	doc, err := parseXML([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	c := &xmlJSONConverter{options: options, alwaysArray: map[string]bool{}}
+	for _, name := range options.AlwaysArray {
+		c.alwaysArray[strings.TrimSpace(name)] = true
+	}
+
+	root := doc.root()
+	var result *jsonNode
+	if options.Convention == XMLJsonConventionParker {
+		result = c.element(root)
+	} else {
+		result = &jsonNode{kind: jsonObject}
+		value := c.element(root)
+		if c.isArray(root) {
+			value = &jsonNode{kind: jsonArray, items: []*jsonNode{value}}
+		}
+		result.set(c.name(root.name), value)
+	}
+	indent := JsonFormatOptions{IndentSize: options.IndentSize, UseTabs: options.UseTabs}.indent()
+	return encodeJSON(result, indent), nil
+}
+
+// xmlJSONConverter 将 XML 语法树转换为 JSON 语法树
+type xmlJSONConverter struct {
+	options     XMLJsonOptions
+	alwaysArray map[string]bool
+}
+
+// name 返回元素或属性在 JSON 中的键
+func (c *xmlJSONConverter) name(n xmlName) string {
+	if c.options.Namespaces == XMLNamespaceStrip {
+		return n.local
+	}
+	return n.qualified()
+}
+
+func (c *xmlJSONConverter) isArray(el *xmlNode) bool {
+	return c.alwaysArray[el.name.qualified()] || c.alwaysArray[el.name.local]
+}
+
+// scalar 将文本转换为 JSON 值，开启类型推断时识别数字和布尔值
+func (c *xmlJSONConverter) scalar(text string) *jsonNode {
+	if c.options.InferTypes {
+		switch trimmed := strings.TrimSpace(text); {
+		case trimmed == "true" || trimmed == "false":
+			return &jsonNode{kind: jsonBool, raw: trimmed}
+		case isJSONNumber(trimmed):
+			return &jsonNode{kind: jsonNumber, raw: trimmed}
+		}
+	}
+	return newJSONString(text)
+}
+
+// content 返回元素的文本内容以及是否包含子元素；包含子元素时去掉文本首尾的空白
+func (c *xmlJSONConverter) content(el *xmlNode) (string, bool) {
+	var b strings.Builder
+	hasElements := false
+	for _, child := range el.children {
+		switch child.kind {
+		case xmlText, xmlCData:
+			b.WriteString(child.text)
+		case xmlElement:
+			hasElements = true
+		}
+	}
+	if hasElements {
+		return strings.TrimSpace(b.String()), true
+	}
+	return b.String(), false
+}
+
+// attributes 返回需要输出的属性，去掉命名空间时不输出命名空间声明
+func (c *xmlJSONConverter) attributes(el *xmlNode) []*xmlAttr {
+	var attrs []*xmlAttr
+	for _, a := range el.attrs {
+		if a.isNamespaceDecl() && c.options.Namespaces == XMLNamespaceStrip {
+			continue
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs
+}
+
+// element 按映射约定转换元素
+func (c *xmlJSONConverter) element(el *xmlNode) *jsonNode {
+	text, hasElements := c.content(el)
+	switch c.options.Convention {
+	case XMLJsonConventionParker:
+		if !hasElements {
+			if text == "" {
+				return &jsonNode{kind: jsonNull, raw: "null"}
+			}
+			return c.scalar(text)
+		}
+		obj := &jsonNode{kind: jsonObject}
+		c.children(el, obj)
+		return obj
+
+	case XMLJsonConventionBadgerFish:
+		obj := &jsonNode{kind: jsonObject}
+		namespaces := &jsonNode{kind: jsonObject}
+		for _, a := range c.attributes(el) {
+			switch {
+			case a.isNamespaceDecl() && a.name.prefix == "":
+				namespaces.set("$", newJSONString(a.value))
+			case a.isNamespaceDecl():
+				namespaces.set(a.name.local, newJSONString(a.value))
+			default:
+				obj.set("@"+c.name(a.name), c.scalar(a.value))
+			}
+		}
+		if len(namespaces.fields) > 0 {
+			obj.set("@xmlns", namespaces)
+		}
+		if text != "" {
+			obj.set("$", c.scalar(text))
+		}
+		c.children(el, obj)
+		return obj
+	}
+
+	attrs := c.attributes(el)
+	if len(attrs) == 0 && !hasElements {
+		if text == "" {
+			return &jsonNode{kind: jsonNull, raw: "null"}
+		}
+		return c.scalar(text)
+	}
+	obj := &jsonNode{kind: jsonObject}
+	for _, a := range attrs {
+		obj.set(c.options.AttributePrefix+c.name(a.name), c.scalar(a.value))
+	}
+	if text != "" {
+		obj.set(c.options.TextKey, c.scalar(text))
+	}
+	c.children(el, obj)
+	return obj
+}
+
+// children 将子元素按名称分组加入对象，重复出现或指定为数组的元素输出为数组，位置取第一次出现处
+func (c *xmlJSONConverter) children(el *xmlNode, obj *jsonNode) {
+	var order []string
+	groups := map[string][]*xmlNode{}
+	for _, child := range el.children {
+		if child.kind != xmlElement {
+			continue
+		}
+		key := c.name(child.name)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], child)
+	}
+	for _, key := range order {
+		elements := groups[key]
+		if len(elements) == 1 && !c.isArray(elements[0]) {
+			obj.set(key, c.element(elements[0]))
+			continue
+		}
+		array := &jsonNode{kind: jsonArray}
+		for _, child := range elements {
+			array.items = append(array.items, c.element(child))
+		}
+		obj.set(key, array)
+	}
+}
+
+// JsonToXml 按映射约定将 JSON 转换为 XML，不是合法 XML 名称的键会将非法字符替换为下划线
+// 键中的命名空间前缀没有对应的 xmlns 声明时，冒号同样替换为下划线，如 x:b 输出为 <x_b>
+func (x *XMLProcessor) JsonToXml(jsonStr string, options XMLJsonOptions) (string, error) {
+	if err := options.normalize(); err != nil {
+		return "", err
+	}
+	value, err := parseJSON([]byte(jsonStr))
+	if err != nil {
+		return "", err
+	}
+	b := &jsonXMLBuilder{options: options}
+
+	rootName := options.RootName
+	if rootName == "" && options.Convention == XMLJsonConventionParker {
+		rootName = "root"
+	}
+	if rootName == "" {
+		if value.kind != jsonObject || len(value.fields) != 1 {
+			return "", fmt.Errorf("JSON 顶层需要是只有一个键的对象，或者指定根元素名")
+		}
+		rootName, value = value.fields[0].name(), value.fields[0].value
+		if value.kind == jsonArray {
+			if len(value.items) != 1 {
+				return "", fmt.Errorf("根元素 %s 的值是包含 %d 个元素的数组，请指定根元素名", rootName, len(value.items))
+			}
+			value = value.items[0]
+		}
+	}
+
+	doc := &xmlNode{kind: xmlDocument}
+	if options.XmlDeclaration {
+		doc.children = append(doc.children, &xmlNode{kind: xmlProcInst, name: xmlName{local: "xml"}, text: ` version="1.0" encoding="UTF-8"`, parent: doc})
+	}
+	root, err := b.element(doc, rootName, value)
+	if err != nil {
+		return "", err
+	}
+	if err := bindPrefixes(root, map[string]bool{"xml": true}); err != nil {
+		return "", err
+	}
+	doc.children = append(doc.children, root)
+
+	indent := JsonFormatOptions{IndentSize: options.IndentSize, UseTabs: options.UseTabs}.indent()
+	w := &xmlWriter{indent: indent, selfClosing: XMLSelfClosingAlways}
+	w.writeDocument(doc)
+	return w.b.String(), nil
+}
+
+// jsonXMLBuilder 将 JSON 语法树转换为 XML 语法树
+type jsonXMLBuilder struct {
+	options XMLJsonOptions
+}
+
+// element 创建名为 name 的元素，value 为其内容
+func (b *jsonXMLBuilder) element(parent *xmlNode, name string, value *jsonNode) (*xmlNode, error) {
+	el := &xmlNode{kind: xmlElement, name: xmlElementName(name), parent: parent}
+	switch value.kind {
+	case jsonNull:
+	case jsonArray:
+		for _, item := range value.items {
+			if err := b.child(el, xmlJSONArrayItem, item); err != nil {
+				return nil, err
+			}
+		}
+	case jsonObject:
+		for _, f := range value.fields {
+			if err := b.field(el, f.name(), f.value); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if err := b.text(el, value); err != nil {
+			return nil, err
+		}
+	}
+	return el, nil
+}
+
+// child 添加子元素，数组展开为多个同名元素
+func (b *jsonXMLBuilder) child(el *xmlNode, name string, value *jsonNode) error {
+	items := []*jsonNode{value}
+	if value.kind == jsonArray {
+		items = value.items
+	}
+	for _, item := range items {
+		child, err := b.element(el, name, item)
+		if err != nil {
+			return err
+		}
+		el.children = append(el.children, child)
+	}
+	return nil
+}
+
+// field 按映射约定将对象成员转换为属性、文本、命名空间声明或子元素
+func (b *jsonXMLBuilder) field(el *xmlNode, key string, value *jsonNode) error {
+	switch b.options.Convention {
+	case XMLJsonConventionParker:
+		return b.child(el, key, value)
+
+	case XMLJsonConventionBadgerFish:
+		switch {
+		case key == "$":
+			return b.text(el, value)
+		case key == "@xmlns":
+			if value.kind != jsonObject {
+				return fmt.Errorf("@xmlns 的值必须是对象")
+			}
+			for _, ns := range value.fields {
+				name := xmlName{prefix: "xmlns", local: ns.name()}
+				if ns.name() == "$" {
+					name = xmlName{local: "xmlns"}
+				}
+				if err := b.attribute(el, name, ns.value); err != nil {
+					return err
+				}
+			}
+			return nil
+		case strings.HasPrefix(key, "@") && len(key) > 1:
+			return b.attribute(el, xmlElementName(key[1:]), value)
+		}
+		return b.child(el, key, value)
+	}
+
+	switch {
+	case key == b.options.TextKey:
+		return b.text(el, value)
+	case strings.HasPrefix(key, b.options.AttributePrefix) && len(key) > len(b.options.AttributePrefix):
+		return b.attribute(el, xmlElementName(key[len(b.options.AttributePrefix):]), value)
+	}
+	return b.child(el, key, value)
+}
+
+// attribute 添加属性，值必须是标量
+func (b *jsonXMLBuilder) attribute(el *xmlNode, name xmlName, value *jsonNode) error {
+	if value.kind == jsonObject || value.kind == jsonArray {
+		return fmt.Errorf("属性 %s 的值必须是字符串、数字、布尔值或 null", name.qualified())
+	}
+	for _, a := range el.attrs {
+		if a.name == name {
+			return fmt.Errorf("元素 <%s> 的属性 %s 重复", el.name.qualified(), name.qualified())
+		}
+	}
+	el.attrs = append(el.attrs, newXMLAttr(name, jsonScalarText(value)))
+	return nil
+}
+
+// text 添加文本内容，值必须是标量
+func (b *jsonXMLBuilder) text(el *xmlNode, value *jsonNode) error {
+	if value.kind == jsonObject || value.kind == jsonArray {
+		return fmt.Errorf("元素 <%s> 的文本内容必须是字符串、数字、布尔值或 null", el.name.qualified())
+	}
+	switch text := jsonScalarText(value); {
+	case text == "":
+	case isXMLWhitespace(text):
+		// 只有空白的文本会被当作缩进去掉，使用 CDATA 保留
+		el.children = append(el.children, &xmlNode{kind: xmlCData, text: text, parent: el})
+	default:
+		el.children = append(el.children, &xmlNode{kind: xmlText, text: text, raw: escapeXMLText(text), parent: el})
+	}
+	return nil
+}
+
+// bindPrefixes 检查元素和属性的前缀是否在作用域内声明，没有声明的前缀与本地名用下划线连接
+// declared 为祖先元素已声明的前缀
+func bindPrefixes(el *xmlNode, declared map[string]bool) error {
+	scope := declared
+	for _, a := range el.attrs {
+		if a.name.prefix != "xmlns" || scope[a.name.local] {
+			continue
+		}
+		if len(scope) == len(declared) {
+			scope = make(map[string]bool, len(declared)+1)
+			for prefix := range declared {
+				scope[prefix] = true
+			}
+		}
+		scope[a.name.local] = true
+	}
+	bind := func(n xmlName) xmlName {
+		if n.prefix == "" || n.prefix == "xmlns" || scope[n.prefix] {
+			return n
+		}
+		return xmlName{local: n.prefix + "_" + n.local}
+	}
+	el.name = bind(el.name)
+	seen := make(map[xmlName]bool, len(el.attrs))
+	for _, a := range el.attrs {
+		a.name = bind(a.name)
+		if seen[a.name] {
+			return fmt.Errorf("元素 <%s> 的属性 %s 重复", el.name.qualified(), a.name.qualified())
+		}
+		seen[a.name] = true
+	}
+	for _, child := range el.children {
+		if child.kind == xmlElement {
+			if err := bindPrefixes(child, scope); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonScalarText 返回标量的文本形式，null 为空字符串
+func jsonScalarText(n *jsonNode) string {
+	switch n.kind {
+	case jsonString:
+		return n.value
+	case jsonNull:
+		return ""
+	}
+	return n.raw
+}
+
+// xmlElementName 将 JSON 键转换为 XML 名称：带合法前缀的键保留前缀，非法字符替换为下划线
+func xmlElementName(key string) xmlName {
+	if prefix, local, found := strings.Cut(key, ":"); found && isNCName(prefix) && isNCName(local) {
+		return xmlName{prefix: prefix, local: local}
+	}
+	var b strings.Builder
+	for i, r := range key {
+		switch {
+		case r == ':' || !isNameChar(r):
+			b.WriteByte('_')
+		case i == 0 && !isNameStart(r):
+			b.WriteByte('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return xmlName{local: "_"}
+	}
+	return xmlName{local: b.String()}
+}
+
+// escapeXMLText 转义文本内容
+func escapeXMLText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#13;").Replace(s)
+}
@@ -0,0 +1,129 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+const xmlJSONSample = `<order xmlns:p="urn:p" id="7">
+  <p:item sku="a">2</p:item>
+  <p:item sku="b">3</p:item>
+  <note>hi</note>
+  <empty/>
+  <flag>true</flag>
+</order>`
+
+func TestXmlJsonRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		options XMLJsonOptions
+		xml     string
+		json    string // XmlToJson 压缩后的结果
+		back    string // 再转换回 XML 的结果
+	}{
+		{"default", XMLJsonOptions{}, xmlJSONSample,
+			`{"order":{"@xmlns:p":"urn:p","@id":"7","p:item":[{"@sku":"a","#text":"2"},{"@sku":"b","#text":"3"}],"note":"hi","empty":null,"flag":"true"}}`,
+			xmlJSONSample},
+		{"custom prefix and text key", XMLJsonOptions{AttributePrefix: "-", TextKey: "_"}, `<a k="1">t<b/></a>`,
+			`{"a":{"-k":"1","_":"t","b":null}}`,
+			"<a k=\"1\">t<b/></a>"},
+		{"badgerfish", XMLJsonOptions{Convention: XMLJsonConventionBadgerFish}, xmlJSONSample,
+			`{"order":{"@id":"7","@xmlns":{"p":"urn:p"},"p:item":[{"@sku":"a","$":"2"},{"@sku":"b","$":"3"}],"note":{"$":"hi"},"empty":{},"flag":{"$":"true"}}}`,
+			strings.Replace(xmlJSONSample, `xmlns:p="urn:p" id="7"`, `id="7" xmlns:p="urn:p"`, 1)},
+		{"badgerfish default namespace", XMLJsonOptions{Convention: XMLJsonConventionBadgerFish}, `<a xmlns="urn:a"><b>1</b></a>`,
+			`{"a":{"@xmlns":{"$":"urn:a"},"b":{"$":"1"}}}`,
+			"<a xmlns=\"urn:a\">\n  <b>1</b>\n</a>"},
+		{"parker", XMLJsonOptions{Convention: XMLJsonConventionParker}, `<root><item>2</item><item>3</item><note>hi</note><empty/></root>`,
+			`{"item":["2","3"],"note":"hi","empty":null}`,
+			"<root>\n  <item>2</item>\n  <item>3</item>\n  <note>hi</note>\n  <empty/>\n</root>"},
+		{"always array", XMLJsonOptions{AlwaysArray: []string{"note", "p:item"}}, `<r xmlns:p="urn:p"><p:item>1</p:item><note>hi</note></r>`,
+			`{"r":{"@xmlns:p":"urn:p","p:item":["1"],"note":["hi"]}}`,
+			"<r xmlns:p=\"urn:p\">\n  <p:item>1</p:item>\n  <note>hi</note>\n</r>"},
+		{"always array root", XMLJsonOptions{AlwaysArray: []string{"r"}}, `<r>1</r>`,
+			`{"r":["1"]}`,
+			"<r>1</r>"},
+		{"strip namespaces and infer types", XMLJsonOptions{Namespaces: XMLNamespaceStrip, InferTypes: true}, xmlJSONSample,
+			`{"order":{"@id":7,"item":[{"@sku":"a","#text":2},{"@sku":"b","#text":3}],"note":"hi","empty":null,"flag":true}}`,
+			"<order id=\"7\">\n  <item sku=\"a\">2</item>\n  <item sku=\"b\">3</item>\n  <note>hi</note>\n  <empty/>\n  <flag>true</flag>\n</order>"},
+		{"whitespace text", XMLJsonOptions{}, `<t>  </t>`,
+			`{"t":"  "}`,
+			"<t><![CDATA[  ]]></t>"},
+	}
+	x := NewXMLProcessor()
+	j := NewJsonProcessor()
+	for _, tt := range tests {
+		jsonText, err := x.XmlToJson(tt.xml, tt.options)
+		if err != nil {
+			t.Errorf("%s: XmlToJson: %v", tt.name, err)
+			continue
+		}
+		if compressed, _ := j.CompressJson(jsonText, false); compressed != tt.json {
+			t.Errorf("%s: XmlToJson:\ngot  %s\nwant %s", tt.name, compressed, tt.json)
+		}
+		back, err := x.JsonToXml(jsonText, tt.options)
+		if err != nil {
+			t.Errorf("%s: JsonToXml: %v", tt.name, err)
+			continue
+		}
+		if back != tt.back {
+			t.Errorf("%s: JsonToXml:\ngot:\n%s\nwant:\n%s", tt.name, back, tt.back)
+		}
+		// 转换回的 XML 必须能被重新解析，且再次转换得到相同的 JSON
+		again, err := x.XmlToJson(back, tt.options)
+		if err != nil {
+			t.Errorf("%s: output is not valid XML: %v", tt.name, err)
+			continue
+		}
+		if again != jsonText {
+			t.Errorf("%s: second round trip changed the JSON:\n%s\n%s", tt.name, jsonText, again)
+		}
+	}
+}
+
+func TestJsonToXml(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		options XMLJsonOptions
+		want    string
+		err     string // 非空时期望错误信息包含的内容
+	}{
+		{"root name", `[1,{"a":2}]`, XMLJsonOptions{RootName: "list"}, "<list>\n  <item>1</item>\n  <item>\n    <a>2</a>\n  </item>\n</list>", ""},
+		{"root name with object", `{"a":1,"b":2}`, XMLJsonOptions{RootName: "r"}, "<r>\n  <a>1</a>\n  <b>2</b>\n</r>", ""},
+		{"declaration", `{"a":1}`, XMLJsonOptions{XmlDeclaration: true}, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<a>1</a>", ""},
+		{"single item root array", `{"a":[{"b":1}]}`, XMLJsonOptions{}, "<a>\n  <b>1</b>\n</a>", ""},
+		{"invalid name characters", `{"a b":{"1x":true}}`, XMLJsonOptions{}, "<a_b>\n  <_1x>true</_1x>\n</a_b>", ""},
+		{"escaping", `{"a":{"@k":"\"<&>","#text":"<&>"}}`, XMLJsonOptions{}, `<a k="&quot;&lt;&amp;>">&lt;&amp;&gt;</a>`, ""},
+		{"undeclared element prefix", `{"a":{"x:b":1}}`, XMLJsonOptions{}, "<a>\n  <x_b>1</x_b>\n</a>", ""},
+		{"undeclared attribute prefix", `{"a":{"@xmlns:x":"urn:x","x:b":{"@x:k":1,"@y:k":2}}}`, XMLJsonOptions{}, "<a xmlns:x=\"urn:x\">\n  <x:b x:k=\"1\" y_k=\"2\"/>\n</a>", ""},
+		{"prefix declared on the element itself", `{"x:a":{"@xmlns:x":"urn:x"}}`, XMLJsonOptions{}, `<x:a xmlns:x="urn:x"/>`, ""},
+		{"xml prefix is always bound", `{"a":{"@xml:lang":"en"}}`, XMLJsonOptions{}, `<a xml:lang="en"/>`, ""},
+		{"undeclared prefix collides", `{"a":{"@x:k":1,"@x_k":2}}`, XMLJsonOptions{}, "", "属性 x_k 重复"},
+		{"whitespace text", `{"t":"  "}`, XMLJsonOptions{}, "<t><![CDATA[  ]]></t>", ""},
+		{"parker default root", `{"a":[1,2]}`, XMLJsonOptions{Convention: XMLJsonConventionParker}, "<root>\n  <a>1</a>\n  <a>2</a>\n</root>", ""},
+		{"several top-level keys", `{"a":1,"b":2}`, XMLJsonOptions{}, "", "只有一个键"},
+		{"root array with several items", `{"a":[1,2]}`, XMLJsonOptions{}, "", "请指定根元素名"},
+		{"object attribute", `{"a":{"@k":{}}}`, XMLJsonOptions{}, "", "属性 k 的值"},
+		{"bad convention", `{"a":1}`, XMLJsonOptions{Convention: "x"}, "", "不支持的映射约定"},
+	}
+	x := NewXMLProcessor()
+	for _, tt := range tests {
+		got, err := x.JsonToXml(tt.json, tt.options)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got %q, %v; want an error mentioning %q", tt.name, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+		if _, err := x.FormatXML(got); err != nil {
+			t.Errorf("%s: output rejected by FormatXML: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,936 @@
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 预定义的命名空间
+const (
+	xmlNamespace   = "http://www.w3.org/XML/1998/namespace"
+	xmlnsNamespace = "http://www.w3.org/2000/xmlns/"
+)
+
+// xmlKind XML 节点类型
+type xmlKind int
+
+const (
+	xmlDocument xmlKind = iota // 文档根节点
+	xmlElement                 // 元素
+	xmlText                    // 文本
+	xmlCData                   // CDATA 段
+	xmlComment                 // 注释
+	xmlProcInst                // 处理指令（包括 XML 声明）
+	xmlDoctype                 // 文档类型声明
+)
+
+// xmlName 带前缀的名称
+type xmlName struct {
+	prefix string
+	local  string
+	space  string // 前缀解析后的命名空间 URI，无命名空间时为空
+}
+
+// qualified 返回源文本中的限定名，如 soap:Envelope
+func (n xmlName) qualified() string {
+	if n.prefix == "" {
+		return n.local
+	}
+	return n.prefix + ":" + n.local
+}
+
+// xmlAttr 元素的属性
+type xmlAttr struct {
+	name   xmlName
+	value  string // 解码并规范化空白后的值
+	raw    string // 源文本中引号之间的原始内容，保留实体引用
+	quote  byte   // 源文本使用的引号
+	offset int
+	line   int
+	column int
+}
+
+// isNamespaceDecl 是否为命名空间声明 xmlns 或 xmlns:p
+func (a *xmlAttr) isNamespaceDecl() bool {
+	return a.name.prefix == "xmlns" || (a.name.prefix == "" && a.name.local == "xmlns")
+}
+
+// xmlNode 保留源文本细节的 XML 语法树节点
+type xmlNode struct {
+	kind        xmlKind
+	name        xmlName // 元素名；处理指令的目标保存在 name.local
+	attrs       []*xmlAttr
+	children    []*xmlNode
+	parent      *xmlNode
+	text        string // 文本为解码后的内容；CDATA、注释为其中的原始内容；处理指令为目标之后的原始内容；DOCTYPE 为 <!DOCTYPE 与 > 之间的原始内容
+	raw         string // 文本节点的原始内容，保留实体引用
+	selfClosing bool   // 源文本中使用 <a/> 形式
+	offset      int    // 节点在输入中的字节偏移量
+	line        int
+	column      int
+
+	// 由父节点的 indexChildren 填充
+	indexed  bool     // 子节点的 index、position 和 textRun 已计算
+	index    int      // 在父节点 children 中的下标
+	position int      // 在同名元素或同类节点的兄弟中的序号，从 1 开始，相邻的文本与 CDATA 共用一个序号
+	textRun  *xmlNode // 文本和 CDATA 节点所在的连续文本中的第一个节点，XPath 中整段连续文本是一个文本节点
+}
+
+// indexChildren 计算子节点的下标和位置序号，语法树建立后不再修改，只需计算一次
+func (n *xmlNode) indexChildren() {
+	if n.indexed {
+		return
+	}
+	counts := map[string]int{}
+	var run *xmlNode // 当前连续文本的第一个节点，处理指令和 DOCTYPE 不在 XPath 数据模型中，不打断连续文本
+	for i, child := range n.children {
+		child.index = i
+		key := strconv.Itoa(int(child.kind))
+		switch child.kind {
+		case xmlElement:
+			key = child.name.qualified()
+		case xmlText, xmlCData:
+			if run != nil {
+				child.position, child.textRun = run.position, run
+				continue
+			}
+			key, run = "text()", child
+			child.textRun = child
+		}
+		if child.kind != xmlText && child.kind != xmlCData && navigable(child) {
+			run = nil
+		}
+		counts[key]++
+		child.position = counts[key]
+	}
+	n.indexed = true
+}
+
+// isXPathText 是否为 XPath 数据模型中文本节点的一部分
+func isXPathText(n *xmlNode) bool {
+	return n.kind == xmlText || n.kind == xmlCData
+}
+
+// runText 返回从 n 开始的连续文本和 CDATA 的内容
+func (n *xmlNode) runText() string {
+	n.parent.indexChildren()
+	siblings := n.parent.children
+	var b strings.Builder
+	for i := n.index; i < len(siblings); i++ {
+		if isXPathText(siblings[i]) {
+			b.WriteString(siblings[i].text)
+		} else if navigable(siblings[i]) {
+			break
+		}
+	}
+	return b.String()
+}
+
+// isWhitespace 是否为只包含空白的文本节点
+func (n *xmlNode) isWhitespace() bool {
+	return n.kind == xmlText && strings.TrimLeft(n.text, " \t\r\n") == ""
+}
+
+// root 返回文档的根元素
+func (n *xmlNode) root() *xmlNode {
+	for _, child := range n.children {
+		if child.kind == xmlElement {
+			return child
+		}
+	}
+	return nil
+}
+
+// attr 按命名空间和本地名称查找属性
+func (n *xmlNode) attr(space, local string) *xmlAttr {
+	for _, a := range n.attrs {
+		if a.name.space == space && a.name.local == local && !a.isNamespaceDecl() {
+			return a
+		}
+	}
+	return nil
+}
+
+// lookupNamespace 查找前缀在当前元素作用域内绑定的命名空间，前缀为空时查找默认命名空间
+func (n *xmlNode) lookupNamespace(prefix string) (string, bool) {
+	switch prefix {
+	case "xml":
+		return xmlNamespace, true
+	case "xmlns":
+		return xmlnsNamespace, true
+	}
+	for e := n; e != nil && e.kind == xmlElement; e = e.parent {
+		for _, a := range e.attrs {
+			if prefix == "" && a.name.prefix == "" && a.name.local == "xmlns" {
+				return a.value, true
+			}
+			if prefix != "" && a.name.prefix == "xmlns" && a.name.local == prefix {
+				return a.value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+// preserveSpace 元素是否处于 xml:space="preserve" 的作用域内
+func (n *xmlNode) preserveSpace() bool {
+	for e := n; e != nil && e.kind == xmlElement; e = e.parent {
+		if a := e.attr(xmlNamespace, "space"); a != nil {
+			return a.value == "preserve"
+		}
+	}
+	return false
+}
+
+// textContent 返回节点内所有文本和 CDATA 的拼接结果
+func (n *xmlNode) textContent() string {
+	switch n.kind {
+	case xmlText, xmlCData:
+		return n.text
+	case xmlComment, xmlProcInst, xmlDoctype:
+		return ""
+	}
+	var b strings.Builder
+	var walk func(*xmlNode)
+	walk = func(e *xmlNode) {
+		for _, child := range e.children {
+			switch child.kind {
+			case xmlText, xmlCData:
+				b.WriteString(child.text)
+			case xmlElement:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// xmlEntity DOCTYPE 内部子集中声明的实体
+type xmlEntity struct {
+	value    string // 替换文本，字符引用已展开
+	external bool   // 通过 SYSTEM 或 PUBLIC 引用的外部实体
+}
+
+// xmlMaxEntityDepth 实体嵌套引用的最大层数，超过时视为循环引用
+const xmlMaxEntityDepth = 16
+
+// xmlParser 保留源文本细节的 XML 解析器，检查格式良好性与命名空间
+type xmlParser struct {
+	data     []byte
+	src      string // 与 data 内容相同，用于查找子串时避免复制
+	pos      int
+	entities map[string]*xmlEntity
+	seenRoot bool
+	limits   XMLParseLimits
+	tokens   int // 已读取的标记数
+	expanded int // 自定义实体已展开的字节数
+
+	// 增量计算行列号，要求按偏移量递增的顺序调用 position
+	linePos int
+	line    int
+	column  int
+}
+
+// parseXML 按默认的安全限制解析 XML 文档
+func parseXML(data []byte) (*xmlNode, error) {
+	return parseXMLWithLimits(data, XMLParseLimits{})
+}
+
+// parseXMLWithLimits 解析 XML 文档，超过限制时返回错误
+func parseXMLWithLimits(data []byte, limits XMLParseLimits) (*xmlNode, error) {
+	limits, err := limits.normalize()
+	if err != nil {
+		return nil, err
+	}
+	if exceeds(len(data), limits.MaxSize) {
+		return nil, fmt.Errorf("XML 大小 %d 字节，超过上限 %d 字节", len(data), limits.MaxSize)
+	}
+	p := &xmlParser{data: data, src: string(data), entities: map[string]*xmlEntity{}, line: 1, column: 1, limits: limits}
+	return p.parseDocument()
+}
+
+func (p *xmlParser) fail(offset int, expected, reason string) error {
+	return newSyntaxError(p.data, offset, expected, reason)
+}
+
+func (p *xmlParser) failEOF() error {
+	return p.fail(len(p.data), "", "XML 意外结束")
+}
+
+// position 返回偏移量对应的行号和列号
+func (p *xmlParser) position(offset int) (int, int) {
+	for ; p.linePos < offset; p.linePos++ {
+		switch c := p.data[p.linePos]; {
+		case c == '\n':
+			p.line++
+			p.column = 1
+		case utf8.RuneStart(c):
+			p.column++
+		}
+	}
+	return p.line, p.column
+}
+
+func (p *xmlParser) newNode(kind xmlKind, offset int, parent *xmlNode) *xmlNode {
+	n := &xmlNode{kind: kind, offset: offset, parent: parent}
+	n.line, n.column = p.position(offset)
+	parent.children = append(parent.children, n)
+	return n
+}
+
+func (p *xmlParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func isXMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (p *xmlParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.data) && isXMLSpace(p.data[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func isNameStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == ':'
+}
+
+func isNameChar(r rune) bool {
+	return isNameStart(r) || unicode.IsDigit(r) || r == '-' || r == '.' || r == 0xB7 || unicode.IsMark(r)
+}
+
+// isXMLName 是否为合法的 XML 名称
+func isXMLName(s string) bool {
+	for i, r := range s {
+		if (i == 0 && !isNameStart(r)) || !isNameChar(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// name 读取一个 XML 名称
+func (p *xmlParser) name() string {
+	start := p.pos
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if (p.pos == start && !isNameStart(r)) || !isNameChar(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos]
+}
+
+// qname 读取限定名并拆分前缀
+func (p *xmlParser) qname(what string) (xmlName, error) {
+	start := p.pos
+	name := p.name()
+	if name == "" {
+		if p.pos >= len(p.data) {
+			return xmlName{}, p.failEOF()
+		}
+		return xmlName{}, p.fail(start, what, "无效的"+what)
+	}
+	prefix, local, found := strings.Cut(name, ":")
+	if !found {
+		return xmlName{local: name}, nil
+	}
+	if prefix == "" || local == "" || strings.Contains(local, ":") {
+		return xmlName{}, p.fail(start, "", fmt.Sprintf("无效的限定名 %s", name))
+	}
+	return xmlName{prefix: prefix, local: local}, nil
+}
+
+func (p *xmlParser) parseDocument() (*xmlNode, error) {
+	doc := &xmlNode{kind: xmlDocument, line: 1, column: 1}
+	if p.hasPrefix("\uFEFF") {
+		p.pos += len("\uFEFF")
+	}
+	p.skipSpace()
+	if p.hasPrefix("<?xml") && p.pos+5 < len(p.data) && isXMLSpace(p.data[p.pos+5]) {
+		if err := p.parseProcInst(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var stack []*xmlNode
+	parent := doc
+	for p.pos < len(p.data) {
+		if p.tokens++; exceeds(p.tokens, p.limits.MaxTokens) {
+			return nil, p.fail(p.pos, "", fmt.Sprintf("标记数量超过上限 %d", p.limits.MaxTokens))
+		}
+		if p.data[p.pos] != '<' {
+			if err := p.parseText(parent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var err error
+		switch {
+		case p.hasPrefix("</"):
+			start := p.pos
+			p.pos += 2
+			var name xmlName
+			if name, err = p.qname("元素名"); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return nil, p.failEOF()
+			}
+			if p.data[p.pos] != '>' {
+				return nil, p.fail(p.pos, "'>'", "结束标签缺少 '>'")
+			}
+			p.pos++
+			if len(stack) == 0 {
+				return nil, p.fail(start, "", fmt.Sprintf("多余的结束标签 </%s>", name.qualified()))
+			}
+			open := stack[len(stack)-1]
+			if open.name.prefix != name.prefix || open.name.local != name.local {
+				return nil, p.fail(start, "</"+open.name.qualified()+">",
+					fmt.Sprintf("开始标签 <%s> 与结束标签 </%s> 不匹配", open.name.qualified(), name.qualified()))
+			}
+			stack = stack[:len(stack)-1]
+			parent = open.parent
+		case p.hasPrefix("<?"):
+			err = p.parseProcInst(parent)
+		case p.hasPrefix("<!--"):
+			err = p.parseComment(parent)
+		case p.hasPrefix("<![CDATA["):
+			if parent == doc {
+				return nil, p.fail(p.pos, "", "CDATA 只能出现在元素内")
+			}
+			err = p.parseCData(parent)
+		case p.hasPrefix("<!DOCTYPE"):
+			if parent != doc || p.seenRoot {
+				return nil, p.fail(p.pos, "", "DOCTYPE 只能出现在根元素之前")
+			}
+			err = p.parseDoctype(doc)
+		default:
+			if parent == doc && p.seenRoot {
+				return nil, p.fail(p.pos, "文本结尾", "只能有一个根元素")
+			}
+			var el *xmlNode
+			if el, err = p.parseStartTag(parent); err != nil {
+				return nil, err
+			}
+			p.seenRoot = true
+			if !el.selfClosing {
+				if exceeds(len(stack)+1, p.limits.MaxDepth) {
+					return nil, p.fail(el.offset, "", fmt.Sprintf("元素嵌套层数超过上限 %d", p.limits.MaxDepth))
+				}
+				stack = append(stack, el)
+				parent = el
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+		return nil, p.fail(len(p.data), "</"+open.name.qualified()+">", fmt.Sprintf("XML 意外结束，元素 <%s> 没有闭合", open.name.qualified()))
+	}
+	if doc.root() == nil {
+		return nil, p.fail(len(p.data), "根元素", "缺少根元素")
+	}
+	return doc, nil
+}
+
+// parseText 读取到下一个 '<' 之前的文本，根元素之外只允许空白
+func (p *xmlParser) parseText(parent *xmlNode) error {
+	start := p.pos
+	end := strings.IndexByte(p.src[start:], '<')
+	if end < 0 {
+		end = len(p.data) - start
+	}
+	p.pos = start + end
+	raw := p.src[start:p.pos]
+	if parent.kind == xmlDocument {
+		if i := len(raw) - len(strings.TrimLeft(raw, " \t\r\n")); i < len(raw) {
+			return p.fail(start+i, "", "根元素之外不能有文本")
+		}
+		return nil
+	}
+	if i := strings.Index(raw, "]]>"); i >= 0 {
+		return p.fail(start+i, "", "文本中不能出现 ']]>'")
+	}
+	text, err := p.decode(raw, start, false)
+	if err != nil {
+		return err
+	}
+	n := p.newNode(xmlText, start, parent)
+	n.text, n.raw = text, raw
+	return nil
+}
+
+func (p *xmlParser) parseStartTag(parent *xmlNode) (*xmlNode, error) {
+	start := p.pos
+	p.pos++
+	name, err := p.qname("元素名")
+	if err != nil {
+		return nil, err
+	}
+	el := p.newNode(xmlElement, start, parent)
+	el.name = name
+
+	seen := map[string]bool{}
+	for {
+		sawSpace := p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.failEOF()
+		}
+		if p.data[p.pos] == '>' {
+			p.pos++
+			break
+		}
+		if p.hasPrefix("/>") {
+			p.pos += 2
+			el.selfClosing = true
+			break
+		}
+		if !sawSpace {
+			return nil, p.fail(p.pos, "空白", "属性之前缺少空白")
+		}
+
+		attrStart := p.pos
+		attrName, err := p.qname("属性名")
+		if err != nil {
+			return nil, err
+		}
+		if seen[attrName.qualified()] {
+			return nil, p.fail(attrStart, "", fmt.Sprintf("重复的属性 %s", attrName.qualified()))
+		}
+		seen[attrName.qualified()] = true
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.failEOF()
+		}
+		if p.data[p.pos] != '=' {
+			return nil, p.fail(p.pos, "'='", "属性缺少等号")
+		}
+		p.pos++
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.failEOF()
+		}
+		quote := p.data[p.pos]
+		if quote != '"' && quote != '\'' {
+			return nil, p.fail(p.pos, `'"'`, "属性值缺少引号")
+		}
+		valueStart := p.pos + 1
+		end := strings.IndexByte(p.src[valueStart:], quote)
+		if end < 0 {
+			return nil, p.fail(len(p.data), string(quote), "属性值没有结束")
+		}
+		raw := p.src[valueStart : valueStart+end]
+		if i := strings.IndexByte(raw, '<'); i >= 0 {
+			return nil, p.fail(valueStart+i, "", "属性值中不能包含 '<'")
+		}
+		value, err := p.decode(raw, valueStart, true)
+		if err != nil {
+			return nil, err
+		}
+		p.pos = valueStart + end + 1
+
+		if exceeds(len(el.attrs)+1, p.limits.MaxAttributes) {
+			return nil, p.fail(attrStart, "", fmt.Sprintf("元素 <%s> 的属性数量超过上限 %d", name.qualified(), p.limits.MaxAttributes))
+		}
+		a := &xmlAttr{name: attrName, value: value, raw: raw, quote: quote, offset: attrStart}
+		a.line, a.column = p.position(attrStart)
+		el.attrs = append(el.attrs, a)
+	}
+	return el, p.resolveNamespaces(el)
+}
+
+// resolveNamespaces 解析元素及其属性的命名空间
+func (p *xmlParser) resolveNamespaces(el *xmlNode) error {
+	for _, a := range el.attrs {
+		if !a.isNamespaceDecl() {
+			continue
+		}
+		a.name.space = xmlnsNamespace
+		switch {
+		case a.name.prefix == "xmlns" && a.value == "":
+			return p.fail(a.offset, "", fmt.Sprintf("不能取消前缀 %s 的命名空间绑定", a.name.local))
+		case a.name.local == "xml" && a.value != xmlNamespace, a.name.local != "xml" && a.value == xmlNamespace:
+			return p.fail(a.offset, "", "前缀 xml 只能绑定到 "+xmlNamespace)
+		case a.name.prefix == "xmlns" && a.name.local == "xmlns":
+			return p.fail(a.offset, "", "不能声明前缀 xmlns")
+		}
+	}
+
+	space, ok := el.lookupNamespace(el.name.prefix)
+	if !ok {
+		return p.fail(el.offset+1, "", fmt.Sprintf("未声明的命名空间前缀 %s", el.name.prefix))
+	}
+	el.name.space = space
+
+	expanded := map[string]string{}
+	for _, a := range el.attrs {
+		if a.isNamespaceDecl() {
+			continue
+		}
+		if a.name.prefix != "" {
+			if a.name.space, ok = el.lookupNamespace(a.name.prefix); !ok {
+				return p.fail(a.offset, "", fmt.Sprintf("未声明的命名空间前缀 %s", a.name.prefix))
+			}
+		}
+		key := a.name.space + " " + a.name.local
+		if other, ok := expanded[key]; ok {
+			return p.fail(a.offset, "", fmt.Sprintf("属性 %s 与 %s 的命名空间和名称相同", a.name.qualified(), other))
+		}
+		expanded[key] = a.name.qualified()
+	}
+	return nil
+}
+
+func (p *xmlParser) parseComment(parent *xmlNode) error {
+	start := p.pos
+	end := strings.Index(p.src[start+4:], "--")
+	if end < 0 {
+		return p.fail(len(p.data), "'-->'", "注释没有结束")
+	}
+	end += start + 4
+	if !p.hasPrefixAt(end, "-->") {
+		return p.fail(end, "'-->'", "注释中不能包含 '--'")
+	}
+	n := p.newNode(xmlComment, start, parent)
+	n.text = p.src[start+4 : end]
+	p.pos = end + 3
+	return nil
+}
+
+func (p *xmlParser) hasPrefixAt(offset int, s string) bool {
+	return strings.HasPrefix(p.src[offset:], s)
+}
+
+func (p *xmlParser) parseCData(parent *xmlNode) error {
+	start := p.pos
+	body := start + len("<![CDATA[")
+	end := strings.Index(p.src[body:], "]]>")
+	if end < 0 {
+		return p.fail(len(p.data), "']]>'", "CDATA 没有结束")
+	}
+	n := p.newNode(xmlCData, start, parent)
+	n.text = strings.ReplaceAll(p.src[body:body+end], "\r\n", "\n")
+	p.pos = body + end + 3
+	return nil
+}
+
+// parseProcInst 解析处理指令，XML 声明也作为处理指令保存
+func (p *xmlParser) parseProcInst(parent *xmlNode) error {
+	start := p.pos
+	p.pos += 2
+	target := p.name()
+	if target == "" {
+		return p.fail(p.pos, "处理指令目标", "无效的处理指令")
+	}
+	if strings.EqualFold(target, "xml") && (parent.kind != xmlDocument || len(parent.children) > 0) {
+		return p.fail(start, "", "XML 声明只能出现在文档开头")
+	}
+	end := strings.Index(p.src[p.pos:], "?>")
+	if end < 0 {
+		return p.fail(len(p.data), "'?>'", "处理指令没有结束")
+	}
+	data := p.src[p.pos : p.pos+end]
+	if data != "" && !isXMLSpace(data[0]) {
+		return p.fail(p.pos, "空白", "处理指令的目标之后缺少空白")
+	}
+	n := p.newNode(xmlProcInst, start, parent)
+	n.name.local = target
+	n.text = data
+	p.pos += end + 2
+	return nil
+}
+
+// parseDoctype 解析文档类型声明，记录内部子集中声明的实体
+func (p *xmlParser) parseDoctype(doc *xmlNode) error {
+	start := p.pos
+	p.pos += len("<!DOCTYPE")
+	bodyStart := p.pos
+	var quote byte
+	subsetStart := -1
+	for ; p.pos < len(p.data); p.pos++ {
+		c := p.data[p.pos]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case subsetStart >= 0 && p.hasPrefix("<!--"):
+			end := strings.Index(p.src[p.pos:], "-->")
+			if end < 0 {
+				return p.fail(len(p.data), "'-->'", "注释没有结束")
+			}
+			p.pos += end + 2
+		case c == '[' && subsetStart == -1:
+			subsetStart = p.pos + 1
+		case c == ']' && subsetStart >= 0:
+			if err := p.parseSubset(subsetStart, p.pos); err != nil {
+				return err
+			}
+			subsetStart = -2
+		case c == '>' && subsetStart < 0:
+			n := p.newNode(xmlDoctype, start, doc)
+			n.text = p.src[bodyStart:p.pos]
+			p.pos++
+			return nil
+		}
+	}
+	return p.fail(len(p.data), "'>'", "DOCTYPE 没有结束")
+}
+
+// parseSubset 从内部子集中读取实体声明，其他声明只跳过
+func (p *xmlParser) parseSubset(start, end int) error {
+	subset := p.src[start:end]
+	for i := 0; i < len(subset); {
+		rest := subset[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i += strings.Index(rest, "-->") + 3
+		case strings.HasPrefix(rest, "<?"):
+			e := strings.Index(rest, "?>")
+			if e < 0 {
+				return p.fail(start+i, "'?>'", "处理指令没有结束")
+			}
+			i += e + 2
+		case strings.HasPrefix(rest, "<!"):
+			e := declEnd(rest)
+			if e < 0 {
+				return p.fail(start+i, "'>'", "声明没有结束")
+			}
+			if strings.HasPrefix(rest, "<!ENTITY") {
+				if err := p.entityDecl(rest[len("<!ENTITY"):e], start+i); err != nil {
+					return err
+				}
+			}
+			i += e + 1
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// declEnd 返回声明结尾 '>' 的位置，忽略引号中的内容
+func declEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// entityDecl 解析实体声明，如 <!ENTITY name "value"> 或 <!ENTITY name SYSTEM "file.xml">，参数实体被忽略
+func (p *xmlParser) entityDecl(decl string, offset int) error {
+	fields := declFields(decl)
+	if len(fields) >= 1 && fields[0] == "%" {
+		return nil
+	}
+	if len(fields) < 2 || !isXMLName(fields[0]) {
+		return p.fail(offset, "", "无效的实体声明")
+	}
+	name := fields[0]
+	if p.limits.Entities == XMLEntityForbid {
+		return p.fail(offset, "", fmt.Sprintf("不允许声明实体 %s", name))
+	}
+	if _, ok := p.entities[name]; ok {
+		// 重复声明时以第一次为准
+		return nil
+	}
+	switch fields[1] {
+	case "SYSTEM", "PUBLIC":
+		p.entities[name] = &xmlEntity{external: true}
+		return nil
+	}
+	literal := fields[1]
+	if len(literal) < 2 || (literal[0] != '"' && literal[0] != '\'') {
+		return p.fail(offset, "", fmt.Sprintf("实体 %s 的值缺少引号", name))
+	}
+	// 声明时只展开字符引用，实体引用在使用时展开
+	var b strings.Builder
+	value := literal[1 : len(literal)-1]
+	for i := 0; i < len(value); i++ {
+		if value[i] == '&' && i+1 < len(value) && value[i+1] == '#' {
+			end := strings.IndexByte(value[i:], ';')
+			if r, ok := parseCharRef(value[i+2 : i+max(end, 2)]); end > 0 && ok {
+				b.WriteRune(r)
+				i += end
+				continue
+			}
+			return p.fail(offset, "", fmt.Sprintf("实体 %s 中包含无效的字符引用", name))
+		}
+		b.WriteByte(value[i])
+	}
+	p.entities[name] = &xmlEntity{value: b.String()}
+	return nil
+}
+
+// declFields 按空白拆分声明，引号中的内容作为一个整体
+func declFields(decl string) []string {
+	var fields []string
+	for i := 0; i < len(decl); {
+		switch c := decl[i]; {
+		case isXMLSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(decl[i+1:], c)
+			if end < 0 {
+				return append(fields, decl[i:])
+			}
+			fields = append(fields, decl[i:i+end+2])
+			i += end + 2
+		default:
+			end := strings.IndexFunc(decl[i:], func(r rune) bool { return r < 0x80 && isXMLSpace(byte(r)) })
+			if end < 0 {
+				end = len(decl) - i
+			}
+			fields = append(fields, decl[i:i+end])
+			i += end
+		}
+	}
+	return fields
+}
+
+// parseCharRef 解析字符引用中 &# 与 ; 之间的部分，如 x4E2D 或 20013
+func parseCharRef(ref string) (rune, bool) {
+	var n uint64
+	var err error
+	if strings.HasPrefix(ref, "x") {
+		n, err = strconv.ParseUint(ref[1:], 16, 32)
+	} else {
+		n, err = strconv.ParseUint(ref, 10, 32)
+	}
+	r := rune(n)
+	if err != nil || !isXMLChar(r) {
+		return 0, false
+	}
+	return r, true
+}
+
+// isXMLChar 是否为 XML 1.0 允许的字符
+func isXMLChar(r rune) bool {
+	return r == 0x9 || r == 0xA || r == 0xD || (r >= 0x20 && r <= 0xD7FF) ||
+		(r >= 0xE000 && r <= 0xFFFD) || (r >= 0x10000 && r <= 0x10FFFF)
+}
+
+// decode 解码文本或属性值中的字符引用和实体引用，并统一换行；属性值中的空白字符规范化为空格
+// offset 为 raw 在输入中的起始偏移量，用于定位错误
+func (p *xmlParser) decode(raw string, offset int, attr bool) (string, error) {
+	if !strings.ContainsAny(raw, "&\r") && !(attr && strings.ContainsAny(raw, "\t\n")) {
+		return raw, nil
+	}
+	var b strings.Builder
+	if err := p.expand(&b, raw, offset, attr, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// expand 展开引用，depth 大于 0 时处于实体的替换文本中，错误定位到最外层的实体引用
+func (p *xmlParser) expand(b *strings.Builder, raw string, offset int, attr bool, depth int) error {
+	at := func(i int) int {
+		if depth > 0 {
+			return offset
+		}
+		return offset + i
+	}
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case c == '\r':
+			if i+1 < len(raw) && raw[i+1] == '\n' {
+				i++
+			}
+			if attr {
+				b.WriteByte(' ')
+			} else {
+				b.WriteByte('\n')
+			}
+		case attr && (c == '\t' || c == '\n'):
+			b.WriteByte(' ')
+		case c == '&':
+			end := strings.IndexByte(raw[i:], ';')
+			if end < 0 {
+				return p.fail(at(i), "';'", "实体引用缺少结尾的分号")
+			}
+			if err := p.reference(b, raw[i+1:i+end], at(i), attr, depth); err != nil {
+				return err
+			}
+			i += end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return nil
+}
+
+// reference 展开一个字符引用或实体引用，ref 为 & 与 ; 之间的内容
+func (p *xmlParser) reference(b *strings.Builder, ref string, offset int, attr bool, depth int) error {
+	if strings.HasPrefix(ref, "#") {
+		r, ok := parseCharRef(ref[1:])
+		if !ok {
+			return p.fail(offset, "", "无效的字符实体 &"+ref+";")
+		}
+		b.WriteRune(r)
+		return nil
+	}
+	switch ref {
+	case "lt":
+		b.WriteByte('<')
+		return nil
+	case "gt":
+		b.WriteByte('>')
+		return nil
+	case "amp":
+		b.WriteByte('&')
+		return nil
+	case "apos":
+		b.WriteByte('\'')
+		return nil
+	case "quot":
+		b.WriteByte('"')
+		return nil
+	}
+	if !isXMLName(ref) {
+		return p.fail(offset, "", "无效的实体引用 &"+ref+";")
+	}
+	e, ok := p.entities[ref]
+	switch {
+	case !ok:
+		return p.fail(offset, "", "未定义的实体 &"+ref+";")
+	case p.limits.Entities == XMLEntityKeep:
+		b.WriteString("&" + ref + ";")
+		return nil
+	case e.external:
+		return p.fail(offset, "", "不支持外部实体 &"+ref+";")
+	case depth >= xmlMaxEntityDepth:
+		return p.fail(offset, "", "实体 &"+ref+"; 嵌套过深或存在循环引用")
+	case strings.ContainsRune(e.value, '<'):
+		return p.fail(offset, "", "实体 &"+ref+"; 的替换文本中包含标记，暂不支持")
+	}
+	if p.expanded += len(e.value); exceeds(p.expanded, p.limits.MaxEntityExpansion) {
+		return p.fail(offset, "", fmt.Sprintf("实体展开的总大小超过上限 %d 字节", p.limits.MaxEntityExpansion))
+	}
+	return p.expand(b, e.value, offset, attr, depth+1)
+}
@@ -0,0 +1,402 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+// XPath 查询结果的类型
+const (
+	XPathNodeSet = "nodeset"
+	XPathString  = "string"
+	XPathNumber  = "number"
+	XPathBoolean = "boolean"
+)
+
+// XPathMatch XPath 命中的节点
+type XPathMatch struct {
+	Type   string `json:"type"`   // 节点类型：element、attribute、text、comment、document
+	Path   string `json:"path"`   // 位置路径，如 /soap:Envelope[1]/soap:Body[1]/m:Item[2]/@id
+	Line   int    `json:"line"`   // 节点在源文本中的行号，从 1 开始
+	Column int    `json:"column"` // 节点在源文本中的列号，从 1 开始
+	Value  string `json:"value"`  // 元素为格式化的 XML 片段，其他节点为其文本值
+}
+
+// XPathResult XPath 查询结果
+type XPathResult struct {
+	Type    string       `json:"type"`    // 结果类型：nodeset、string、number、boolean
+	Value   string       `json:"value"`   // 非节点集结果的值
+	Matches []XPathMatch `json:"matches"` // 节点集结果中的节点，按文档顺序排列
+}
+
+// QueryXPath 对 XML 执行 XPath 1.0 查询
+// namespaces 为表达式中使用的前缀到命名空间 URI 的映射；未映射的前缀按源文本中的前缀匹配
+func (x *XMLProcessor) QueryXPath(input string, expression string, namespaces map[string]string) (result *XPathResult, err error) {
+	doc, err := parseXML([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+	expr, err := xpath.CompileWithNS(expression, namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("XPath 语法错误: %v", err)
+	}
+	// xpath 包在函数参数类型错误等情况下会 panic
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("XPath 执行失败: %v", r)
+		}
+	}()
+
+	switch v := expr.Evaluate(newXMLNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		result = &XPathResult{Type: XPathNodeSet, Matches: []XPathMatch{}}
+		// 并集等表达式的结果不一定按文档顺序返回，按节点在源文本中的位置排序
+		var navs []*xmlNavigator
+		for v.MoveNext() {
+			navs = append(navs, v.Current().Copy().(*xmlNavigator))
+		}
+		sort.SliceStable(navs, func(i, k int) bool {
+			return navs[i].before(navs[k])
+		})
+		for _, nav := range navs {
+			result.Matches = append(result.Matches, nav.match())
+		}
+	case string:
+		result = &XPathResult{Type: XPathString, Value: v, Matches: []XPathMatch{}}
+	case float64:
+		result = &XPathResult{Type: XPathNumber, Value: formatXPathNumber(v), Matches: []XPathMatch{}}
+	case bool:
+		result = &XPathResult{Type: XPathBoolean, Value: strconv.FormatBool(v), Matches: []XPathMatch{}}
+	default:
+		return nil, fmt.Errorf("不支持的 XPath 结果类型 %T", v)
+	}
+	return result, nil
+}
+
+// formatXPathNumber 按 XPath 的 string() 规则输出数字
+func formatXPathNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// xmlNavigator 在语法树上实现 xpath.NodeNavigator，处理指令和 DOCTYPE 不参与查询，命名空间声明不视为属性
+type xmlNavigator struct {
+	root *xmlNode
+	curr *xmlNode
+	attr int // 当前属性的下标，不在属性上时为 -1
+}
+
+func newXMLNavigator(doc *xmlNode) *xmlNavigator {
+	return &xmlNavigator{root: doc, curr: doc, attr: -1}
+}
+
+// navigable 节点是否出现在 XPath 数据模型中
+func navigable(n *xmlNode) bool {
+	return n.kind != xmlProcInst && n.kind != xmlDoctype
+}
+
+func (n *xmlNavigator) NodeType() xpath.NodeType {
+	if n.attr >= 0 {
+		return xpath.AttributeNode
+	}
+	switch n.curr.kind {
+	case xmlDocument:
+		return xpath.RootNode
+	case xmlElement:
+		return xpath.ElementNode
+	case xmlComment:
+		return xpath.CommentNode
+	}
+	return xpath.TextNode
+}
+
+func (n *xmlNavigator) name() xmlName {
+	if n.attr >= 0 {
+		return n.curr.attrs[n.attr].name
+	}
+	return n.curr.name
+}
+
+func (n *xmlNavigator) LocalName() string {
+	if n.curr.kind != xmlElement {
+		return ""
+	}
+	return n.name().local
+}
+
+func (n *xmlNavigator) Prefix() string {
+	if n.curr.kind != xmlElement {
+		return ""
+	}
+	return n.name().prefix
+}
+
+// NamespaceURL 返回节点的命名空间 URI，xpath 包据此匹配映射过的前缀
+func (n *xmlNavigator) NamespaceURL() string {
+	if n.curr.kind != xmlElement {
+		return ""
+	}
+	return n.name().space
+}
+
+func (n *xmlNavigator) Value() string {
+	if n.attr >= 0 {
+		return n.curr.attrs[n.attr].value
+	}
+	if isXPathText(n.curr) {
+		return n.curr.runText()
+	}
+	return n.curr.textContent()
+}
+
+func (n *xmlNavigator) Copy() xpath.NodeNavigator {
+	c := *n
+	return &c
+}
+
+func (n *xmlNavigator) MoveToRoot() {
+	n.curr, n.attr = n.root, -1
+}
+
+func (n *xmlNavigator) MoveToParent() bool {
+	if n.attr >= 0 {
+		n.attr = -1
+		return true
+	}
+	if n.curr.parent == nil {
+		return false
+	}
+	n.curr = n.curr.parent
+	return true
+}
+
+func (n *xmlNavigator) MoveToNextAttribute() bool {
+	if n.curr.kind != xmlElement {
+		return false
+	}
+	for i := n.attr + 1; i < len(n.curr.attrs); i++ {
+		if !n.curr.attrs[i].isNamespaceDecl() {
+			n.attr = i
+			return true
+		}
+	}
+	return false
+}
+
+func (n *xmlNavigator) MoveToChild() bool {
+	if n.attr >= 0 {
+		return false
+	}
+	for _, child := range n.curr.children {
+		if navigable(child) {
+			n.curr = child
+			return true
+		}
+	}
+	return false
+}
+
+func (n *xmlNavigator) MoveToFirst() bool {
+	if n.attr >= 0 || n.curr.parent == nil {
+		return false
+	}
+	for _, sibling := range n.curr.parent.children {
+		if navigable(sibling) {
+			n.curr = sibling
+			return true
+		}
+	}
+	return false
+}
+
+// siblingIndex 返回当前节点在父节点中的下标
+func (n *xmlNavigator) siblingIndex() int {
+	n.curr.parent.indexChildren()
+	return n.curr.index
+}
+
+func (n *xmlNavigator) MoveToNext() bool {
+	if n.attr >= 0 || n.curr.parent == nil {
+		return false
+	}
+	// 跳过当前连续文本的其余部分
+	inText := isXPathText(n.curr)
+	siblings := n.curr.parent.children
+	for i := n.siblingIndex() + 1; i < len(siblings); i++ {
+		if navigable(siblings[i]) && !(inText && isXPathText(siblings[i])) {
+			n.curr = siblings[i]
+			return true
+		}
+	}
+	return false
+}
+
+func (n *xmlNavigator) MoveToPrevious() bool {
+	if n.attr >= 0 || n.curr.parent == nil {
+		return false
+	}
+	siblings := n.curr.parent.children
+	for i := n.siblingIndex() - 1; i >= 0; i-- {
+		if navigable(siblings[i]) {
+			n.curr = siblings[i]
+			if isXPathText(n.curr) {
+				n.curr = n.curr.textRun
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (n *xmlNavigator) MoveTo(other xpath.NodeNavigator) bool {
+	o, ok := other.(*xmlNavigator)
+	if !ok || o.root != n.root {
+		return false
+	}
+	n.curr, n.attr = o.curr, o.attr
+	return true
+}
+
+// before 当前节点在文档顺序中是否位于 other 之前，属性位于所属元素之后、子节点之前
+func (n *xmlNavigator) before(other *xmlNavigator) bool {
+	offset := func(nav *xmlNavigator) int {
+		if nav.curr.kind == xmlDocument {
+			return -1
+		}
+		return nav.curr.offset
+	}
+	if a, b := offset(n), offset(other); a != b {
+		return a < b
+	}
+	return n.attr < other.attr
+}
+
+// match 将当前节点转换为查询结果
+func (n *xmlNavigator) match() XPathMatch {
+	if n.attr >= 0 {
+		a := n.curr.attrs[n.attr]
+		return XPathMatch{
+			Type:   "attribute",
+			Path:   xmlLocationPath(n.curr) + "/@" + a.name.qualified(),
+			Line:   a.line,
+			Column: a.column,
+			Value:  a.value,
+		}
+	}
+	m := XPathMatch{Path: xmlLocationPath(n.curr), Line: n.curr.line, Column: n.curr.column}
+	switch n.curr.kind {
+	case xmlDocument:
+		m.Type, m.Value = "document", n.curr.textContent()
+	case xmlElement:
+		m.Type, m.Value = "element", serializeXMLFragment(n.curr)
+	case xmlComment:
+		m.Type, m.Value = "comment", n.curr.text
+	default:
+		m.Type, m.Value = "text", n.curr.runText()
+	}
+	return m
+}
+
+// xmlLocationPath 返回节点的位置路径，同名兄弟节点按出现顺序编号
+func xmlLocationPath(n *xmlNode) string {
+	if n.kind == xmlDocument {
+		return "/"
+	}
+	var steps []string
+	for ; n.parent != nil; n = n.parent {
+		n.parent.indexChildren()
+		step := ""
+		switch n.kind {
+		case xmlElement:
+			step = n.name.qualified()
+		case xmlComment:
+			step = "comment()"
+		default:
+			step = "text()"
+		}
+		steps = append(steps, step+"["+strconv.Itoa(n.position)+"]")
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return "/" + strings.Join(steps, "/")
+}
+
+// serializeXMLFragment 将元素格式化为独立的 XML 片段，补上在祖先元素中声明且片段内用到的命名空间
+func serializeXMLFragment(el *xmlNode) string {
+	used := map[string]bool{}
+	var collect func(*xmlNode)
+	collect = func(e *xmlNode) {
+		used[e.name.prefix] = true
+		for _, a := range e.attrs {
+			if a.name.prefix != "" && a.name.prefix != "xmlns" {
+				used[a.name.prefix] = true
+			}
+		}
+		for _, child := range e.children {
+			if child.kind == xmlElement {
+				collect(child)
+			}
+		}
+	}
+	collect(el)
+
+	declared := map[string]bool{}
+	for _, a := range el.attrs {
+		if a.isNamespaceDecl() {
+			declared[a.name.local] = true
+			if a.name.prefix == "" {
+				declared[""] = true
+			}
+		}
+	}
+	var prefixes []string
+	for prefix := range used {
+		if !declared[prefix] && prefix != "xml" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+
+	fragment := *el
+	fragment.attrs = append([]*xmlAttr(nil), el.attrs...)
+	for _, prefix := range prefixes {
+		space, _ := el.parent.lookupNamespace(prefix)
+		if prefix == "" {
+			if space != "" {
+				fragment.attrs = append(fragment.attrs, newXMLAttr(xmlName{local: "xmlns", space: xmlnsNamespace}, space))
+			}
+			continue
+		}
+		fragment.attrs = append(fragment.attrs, newXMLAttr(xmlName{prefix: "xmlns", local: prefix, space: xmlnsNamespace}, space))
+	}
+
+	w := &xmlWriter{indent: "  ", selfClosing: XMLSelfClosingPreserve}
+	w.write(&fragment, 0, false)
+	return w.b.String()
+}
+
+// newXMLAttr 创建属性，值按需转义
+func newXMLAttr(name xmlName, value string) *xmlAttr {
+	return &xmlAttr{name: name, value: value, raw: escapeXMLAttr(value), quote: '"'}
+}
+
+// escapeXMLAttr 转义双引号包围的属性值
+func escapeXMLAttr(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#9;", "\n", "&#10;", "\r", "&#13;").Replace(s)
+}
@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryXPathManySiblings(t *testing.T) {
+	const n = 5000
+	var b strings.Builder
+	b.WriteString("<r>")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "<item id=\"%d\"/>", i)
+	}
+	b.WriteString("</r>")
+	input := b.String()
+
+	x := NewXMLProcessor()
+	tests := []struct {
+		expression string
+		count      int
+		lastPath   string
+	}{
+		{"/r/item[last()]", 1, fmt.Sprintf("/r[1]/item[%d]", n)},
+		{"//item", n, fmt.Sprintf("/r[1]/item[%d]", n)},
+		{"/r/item[last()]/preceding-sibling::item[1]", 1, fmt.Sprintf("/r[1]/item[%d]", n-1)},
+		{"/r/item[1]/following-sibling::item", n - 1, fmt.Sprintf("/r[1]/item[%d]", n)},
+		{"//item/@id", n, fmt.Sprintf("/r[1]/item[%d]/@id", n)},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		result, err := x.QueryXPath(input, tt.expression, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expression, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: took %v", tt.expression, elapsed)
+		}
+		if len(result.Matches) != tt.count {
+			t.Fatalf("%s: got %d matches, want %d", tt.expression, len(result.Matches), tt.count)
+		}
+		if got := result.Matches[len(result.Matches)-1].Path; got != tt.lastPath {
+			t.Errorf("%s: last path %s, want %s", tt.expression, got, tt.lastPath)
+		}
+	}
+}
+
+func TestXMLLocationPathMixedContent(t *testing.T) {
+	x := NewXMLProcessor()
+	result, err := x.QueryXPath(`<r>a<![CDATA[b]]><!--c--><x/>d<x/><y/><x/></r>`, "/r/node()", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, m := range result.Matches {
+		paths = append(paths, m.Path)
+	}
+	want := "/r[1]/text()[1] /r[1]/comment()[1] /r[1]/x[1] /r[1]/text()[2] /r[1]/x[2] /r[1]/y[1] /r[1]/x[3]"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("paths\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// XPath 数据模型中相邻的文本和 CDATA 是同一个文本节点
+func TestQueryXPathAdjacentText(t *testing.T) {
+	const input = `<r>a<![CDATA[b]]>c<?pi x?>d<!--e-->f<x/><![CDATA[g]]>h</r>`
+	tests := []struct {
+		expression string
+		want       string // 节点集为 路径=值，其他结果为值
+	}{
+		{"/r/text()[1]", "/r[1]/text()[1]=abcd"},
+		{"count(/r/text())", "3"},
+		{"/r/text()", "/r[1]/text()[1]=abcd /r[1]/text()[2]=f /r[1]/text()[3]=gh"},
+		{"string(/r/text()[3])", "gh"},
+		{"/r/x/preceding-sibling::text()[1]", "/r[1]/text()[2]=f"},
+		{"/r/x/following-sibling::node()", "/r[1]/text()[3]=gh"},
+		{"/r/comment()/preceding-sibling::node()[1]", "/r[1]/text()[1]=abcd"},
+		{"count(/r/node())", "5"},
+		{"/r/text()[.='abcd']", "/r[1]/text()[1]=abcd"},
+	}
+	x := NewXMLProcessor()
+	for _, tt := range tests {
+		result, err := x.QueryXPath(input, tt.expression, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expression, err)
+		}
+		got := result.Value
+		if result.Type == XPathNodeSet {
+			var matches []string
+			for _, m := range result.Matches {
+				matches = append(matches, m.Path+"="+m.Value)
+			}
+			got = strings.Join(matches, " ")
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestQueryXPathLineColumn(t *testing.T) {
+	x := NewXMLProcessor()
+	result, err := x.QueryXPath("<r>\n  <a>中文</a><b k=\"v\"/>\n<b/></r>", "//b | //@k | /", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range result.Matches {
+		got = append(got, fmt.Sprintf("%s %d:%d", m.Path, m.Line, m.Column))
+	}
+	want := "/ 1:1, /r[1]/b[1] 2:12, /r[1]/b[1]/@k 2:15, /r[1]/b[2] 3:1"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("got %s, want %s", strings.Join(got, ", "), want)
+	}
+}
@@ -0,0 +1,1377 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XML Schema 相关的命名空间
+const (
+	xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// XSDViolation XSD 校验发现的问题
+type XSDViolation struct {
+	Line    int    `json:"line"`    // 行号，从 1 开始
+	Column  int    `json:"column"`  // 列号，从 1 开始
+	Path    string `json:"path"`    // 出错元素的位置路径，如 /order[1]/item[2]
+	Message string `json:"message"` // 问题描述
+}
+
+// ValidateXSD 使用一个或多个 XSD 文件校验 XML，模式中的 include、import、redefine 和 override 从本地文件系统相对加载
+// 返回所有违反约束之处；模式本身有错误时返回 error
+func (x *XMLProcessor) ValidateXSD(input string, schemaFiles []string) ([]XSDViolation, error) {
+	if len(schemaFiles) == 0 {
+		return nil, fmt.Errorf("请选择 XSD 文件")
+	}
+	doc, err := parseXML([]byte(input))
+	if err != nil {
+		return nil, err
+	}
+	s := newXSDSchema()
+	for _, file := range schemaFiles {
+		if err := s.load(file, nil); err != nil {
+			return nil, err
+		}
+	}
+	s.linkSubstitutions()
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	v := &xsdValidator{schema: s, ids: map[string]bool{}, violations: []XSDViolation{}}
+	root := doc.root()
+	if decl := s.globalElement(xsdQName{root.name.space, root.name.local}); decl != nil {
+		v.element(root, decl)
+	} else {
+		v.report(root, root.line, root.column, "根元素 <%s> 没有在模式中声明", root.name.qualified())
+	}
+	for _, ref := range v.idrefs {
+		if !v.ids[ref.value] {
+			v.report(ref.node, ref.line, ref.column, "IDREF 引用的 ID %q 不存在", ref.value)
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		a, b := v.violations[i], v.violations[j]
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+	return v.violations, nil
+}
+
+// ==================== 模式加载 ====================
+
+// xsdQName 命名空间限定的名称
+type xsdQName struct {
+	space string
+	local string
+}
+
+func (q xsdQName) String() string {
+	if q.space == "" {
+		return q.local
+	}
+	return "{" + q.space + "}" + q.local
+}
+
+// xsdDoc 一个模式文件
+type xsdDoc struct {
+	path               string
+	targetNamespace    string
+	chameleon          bool // 被没有目标命名空间的模式 include，采用包含方的目标命名空间
+	elementQualified   bool
+	attributeQualified bool
+}
+
+// xsdSchema 由多个模式文件组成的模式集合，组件在首次使用时编译
+type xsdSchema struct {
+	loaded          map[string]bool
+	docs            map[*xmlNode]*xsdDoc // 模式文件的 schema 元素 → 模式文件
+	elements        map[xsdQName]*xmlNode
+	types           map[xsdQName]*xmlNode
+	attributes      map[xsdQName]*xmlNode
+	groups          map[xsdQName]*xmlNode
+	attributeGroups map[xsdQName]*xmlNode
+	substitutions   map[xsdQName][]xsdQName // 替换组的头元素 → 直接成员
+	redefined       map[*xmlNode]*xmlNode   // redefine 中的定义 → 被它替换的原始定义
+
+	elementCache map[*xmlNode]*xsdElement
+	typeCache    map[*xmlNode]*xsdType
+	builtinCache map[string]*xsdType
+	patternCache map[string]*regexp.Regexp
+	err          error // 模式本身的错误，只记录第一个
+}
+
+func newXSDSchema() *xsdSchema {
+	return &xsdSchema{
+		loaded:          map[string]bool{},
+		docs:            map[*xmlNode]*xsdDoc{},
+		elements:        map[xsdQName]*xmlNode{},
+		types:           map[xsdQName]*xmlNode{},
+		attributes:      map[xsdQName]*xmlNode{},
+		groups:          map[xsdQName]*xmlNode{},
+		attributeGroups: map[xsdQName]*xmlNode{},
+		substitutions:   map[xsdQName][]xsdQName{},
+		redefined:       map[*xmlNode]*xmlNode{},
+		elementCache:    map[*xmlNode]*xsdElement{},
+		typeCache:       map[*xmlNode]*xsdType{},
+		builtinCache:    map[string]*xsdType{},
+		patternCache:    map[string]*regexp.Regexp{},
+	}
+}
+
+// fail 记录模式中的错误
+func (s *xsdSchema) fail(n *xmlNode, format string, args ...interface{}) {
+	if s.err != nil {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if doc := s.docFor(n); doc != nil {
+		msg = fmt.Sprintf("模式 %s 第 %d 行: %s", filepath.Base(doc.path), n.line, msg)
+	}
+	s.err = fmt.Errorf("%s", msg)
+}
+
+// load 加载模式文件，chameleon 不为 nil 时表示被 include 且包含方的目标命名空间为 *chameleon
+func (s *xsdSchema) load(path string, chameleon *string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("无效的模式路径 %s: %v", path, err)
+	}
+	key := abs
+	if chameleon != nil {
+		key += "#" + *chameleon
+	}
+	if s.loaded[key] {
+		return nil
+	}
+	s.loaded[key] = true
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return fmt.Errorf("读取模式文件失败: %v", err)
+	}
+	xml, err := parseXML(data)
+	if err != nil {
+		return fmt.Errorf("模式文件 %s 解析失败: %v", filepath.Base(abs), err)
+	}
+	root := xml.root()
+	if root.name.space != xsdNamespace || root.name.local != "schema" {
+		return fmt.Errorf("%s 不是 XML Schema 文件", filepath.Base(abs))
+	}
+
+	doc := &xsdDoc{path: abs}
+	if a := root.attr("", "targetNamespace"); a != nil {
+		doc.targetNamespace = a.value
+	}
+	if chameleon != nil && doc.targetNamespace == "" && *chameleon != "" {
+		doc.targetNamespace, doc.chameleon = *chameleon, true
+	}
+	if a := root.attr("", "elementFormDefault"); a != nil {
+		doc.elementQualified = a.value == "qualified"
+	}
+	if a := root.attr("", "attributeFormDefault"); a != nil {
+		doc.attributeQualified = a.value == "qualified"
+	}
+	s.docs[root] = doc
+
+	for _, child := range xsdChildren(root) {
+		name := xsdQName{doc.targetNamespace, attrValue(child, "name")}
+		switch child.name.local {
+		case "include", "redefine", "override":
+			location := attrValue(child, "schemaLocation")
+			if location == "" || isRemoteLocation(location) {
+				continue
+			}
+			tns := doc.targetNamespace
+			if err := s.load(filepath.Join(filepath.Dir(abs), location), &tns); err != nil {
+				return err
+			}
+			if child.name.local != "include" {
+				s.redefine(child, doc)
+			}
+		case "import":
+			location := attrValue(child, "schemaLocation")
+			if location == "" || isRemoteLocation(location) {
+				// 无法从网络加载，用到其中的组件时会报告未定义
+				continue
+			}
+			if err := s.load(filepath.Join(filepath.Dir(abs), location), nil); err != nil {
+				return err
+			}
+		case "element":
+			s.register(s.elements, name, child)
+		case "simpleType", "complexType":
+			s.register(s.types, name, child)
+		case "attribute":
+			s.register(s.attributes, name, child)
+		case "group":
+			s.register(s.groups, name, child)
+		case "attributeGroup":
+			s.register(s.attributeGroups, name, child)
+		}
+	}
+	return nil
+}
+
+func isRemoteLocation(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// register 登记全局组件，同名组件以第一次出现的为准
+func (s *xsdSchema) register(components map[xsdQName]*xmlNode, name xsdQName, n *xmlNode) {
+	if _, ok := components[name]; !ok {
+		components[name] = n
+	}
+}
+
+// redefine 用 redefine 或 override 中的定义替换被包含模式中的同名组件
+// redefine 只能重新定义类型、模型组和属性组，且原始定义必须存在；override 中的定义直接替换或补充
+func (s *xsdSchema) redefine(n *xmlNode, doc *xsdDoc) {
+	override := n.name.local == "override"
+	for _, child := range xsdChildren(n) {
+		var components map[xsdQName]*xmlNode
+		switch child.name.local {
+		case "simpleType", "complexType":
+			components = s.types
+		case "group":
+			components = s.groups
+		case "attributeGroup":
+			components = s.attributeGroups
+		case "element", "attribute":
+			if !override {
+				s.fail(child, "redefine 中不能定义 %s", child.name.local)
+				return
+			}
+			components = s.elements
+			if child.name.local == "attribute" {
+				components = s.attributes
+			}
+		default:
+			continue
+		}
+		name := xsdQName{doc.targetNamespace, attrValue(child, "name")}
+		original, ok := components[name]
+		if !override {
+			if !ok {
+				s.fail(child, "redefine 重新定义的 %s 在模式 %s 中不存在", name, attrValue(n, "schemaLocation"))
+				return
+			}
+			s.redefined[child] = original
+		}
+		components[name] = child
+	}
+}
+
+// component 按名称查找全局组件，n 为引用所在的模式节点
+// redefine 中的定义通过自身名称引用的是被它替换的原始定义
+func (s *xsdSchema) component(components map[xsdQName]*xmlNode, n *xmlNode, q xsdQName) (*xmlNode, bool) {
+	def, ok := components[q]
+	for ok {
+		original, redefined := s.redefined[def]
+		if !redefined || !isAncestorOrSelf(def, n) {
+			break
+		}
+		def = original
+	}
+	return def, ok
+}
+
+func isAncestorOrSelf(ancestor, n *xmlNode) bool {
+	for ; n != nil; n = n.parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// linkSubstitutions 建立替换组的成员关系
+func (s *xsdSchema) linkSubstitutions() {
+	for name, n := range s.elements {
+		value := attrValue(n, "substitutionGroup")
+		if value == "" {
+			continue
+		}
+		// XSD 1.1 允许多个头元素
+		for _, head := range strings.Fields(value) {
+			if q, ok := s.resolveQName(n, head); ok {
+				s.substitutions[q] = append(s.substitutions[q], name)
+			}
+		}
+	}
+	for head := range s.substitutions {
+		sort.Slice(s.substitutions[head], func(i, j int) bool {
+			return s.substitutions[head][i].String() < s.substitutions[head][j].String()
+		})
+	}
+}
+
+// docFor 返回模式节点所在的模式文件
+func (s *xsdSchema) docFor(n *xmlNode) *xsdDoc {
+	for ; n != nil && n.parent != nil; n = n.parent {
+		if n.parent.kind == xmlDocument {
+			return s.docs[n]
+		}
+	}
+	return nil
+}
+
+// resolveQName 按模式节点的命名空间作用域解析 QName 形式的属性值
+func (s *xsdSchema) resolveQName(n *xmlNode, value string) (xsdQName, bool) {
+	prefix, local, found := strings.Cut(strings.TrimSpace(value), ":")
+	if !found {
+		prefix, local = "", prefix
+	}
+	space, ok := n.lookupNamespace(prefix)
+	if !ok {
+		s.fail(n, "未声明的命名空间前缀 %s", prefix)
+		return xsdQName{}, false
+	}
+	if doc := s.docFor(n); doc != nil && doc.chameleon && prefix == "" && space == "" {
+		space = doc.targetNamespace
+	}
+	return xsdQName{space, local}, true
+}
+
+// attrValue 返回无命名空间属性的值
+func attrValue(n *xmlNode, local string) string {
+	if a := n.attr("", local); a != nil {
+		return a.value
+	}
+	return ""
+}
+
+// xsdChildren 返回 XSD 命名空间中的子元素，忽略注解
+func xsdChildren(n *xmlNode) []*xmlNode {
+	var children []*xmlNode
+	for _, child := range n.children {
+		if child.kind == xmlElement && child.name.space == xsdNamespace && child.name.local != "annotation" {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// xsdChild 返回第一个指定名称的 XSD 子元素
+func xsdChild(n *xmlNode, names ...string) *xmlNode {
+	for _, child := range xsdChildren(n) {
+		for _, name := range names {
+			if child.name.local == name {
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+// ==================== 模式组件 ====================
+
+// xsdElement 元素声明
+type xsdElement struct {
+	name     xsdQName
+	typ      *xsdType
+	nillable bool
+	abstract bool
+	fixed    *string
+}
+
+// xsdType 简单类型或复杂类型
+type xsdType struct {
+	name    xsdQName
+	simple  *xsdSimpleType
+	complex *xsdComplexType
+}
+
+// display 返回用于错误信息的类型名
+func (t *xsdType) display() string {
+	switch {
+	case t.name.space == xsdNamespace:
+		return "xs:" + t.name.local
+	case t.name.local != "":
+		return t.name.local
+	}
+	return "匿名类型"
+}
+
+// xsdComplexType 复杂类型
+type xsdComplexType struct {
+	anyType      bool // xs:anyType，接受任意内容
+	mixed        bool
+	particle     *xsdParticle // 元素内容模型，nil 表示不允许子元素
+	simple       *xsdType     // 简单内容的值类型
+	attributes   []*xsdAttrUse
+	anyAttribute *xsdWildcard
+	abstract     bool
+}
+
+// xsdAttrUse 属性使用
+type xsdAttrUse struct {
+	name       xsdQName
+	typ        *xsdType
+	required   bool
+	prohibited bool
+	fixed      *string
+}
+
+// 内容模型粒子的种类
+const (
+	particleElement = iota
+	particleSequence
+	particleChoice
+	particleAll
+	particleAny
+)
+
+// xsdParticle 内容模型中的粒子
+type xsdParticle struct {
+	kind     int
+	min      int
+	max      int // 小于 0 表示 unbounded
+	element  *xsdElement
+	children []*xsdParticle
+	wildcard *xsdWildcard
+}
+
+// xsdWildcard any 或 anyAttribute 通配符
+type xsdWildcard struct {
+	any        bool     // ##any
+	other      bool     // ##other，除目标命名空间和无命名空间之外的任意命名空间
+	namespaces []string // 允许的命名空间列表，空字符串表示无命名空间
+	tns        string
+	process    string // strict、lax、skip
+}
+
+func (w *xsdWildcard) allows(space string) bool {
+	switch {
+	case w.any:
+		return true
+	case w.other:
+		return space != w.tns && space != ""
+	}
+	for _, ns := range w.namespaces {
+		if ns == space {
+			return true
+		}
+	}
+	return false
+}
+
+// globalElement 返回全局元素声明
+func (s *xsdSchema) globalElement(name xsdQName) *xsdElement {
+	if n, ok := s.elements[name]; ok {
+		return s.element(n)
+	}
+	return nil
+}
+
+// element 编译元素声明或元素引用
+func (s *xsdSchema) element(n *xmlNode) *xsdElement {
+	if e, ok := s.elementCache[n]; ok {
+		return e
+	}
+	if ref := attrValue(n, "ref"); ref != "" {
+		q, ok := s.resolveQName(n, ref)
+		if !ok {
+			return nil
+		}
+		e := s.globalElement(q)
+		if e == nil {
+			s.fail(n, "引用的元素 %s 未定义", q)
+		}
+		s.elementCache[n] = e
+		return e
+	}
+
+	doc := s.docFor(n)
+	e := &xsdElement{name: xsdQName{local: attrValue(n, "name")}}
+	s.elementCache[n] = e
+	global := n.parent != nil && n.parent.name.local == "schema"
+	form := attrValue(n, "form")
+	if global || form == "qualified" || (form == "" && doc != nil && doc.elementQualified) {
+		e.name.space = doc.targetNamespace
+	}
+	e.nillable = attrValue(n, "nillable") == "true"
+	e.abstract = attrValue(n, "abstract") == "true"
+	if a := n.attr("", "fixed"); a != nil {
+		e.fixed = &a.value
+	}
+
+	switch typeName := attrValue(n, "type"); {
+	case typeName != "":
+		if q, ok := s.resolveQName(n, typeName); ok {
+			e.typ = s.typeByName(n, q)
+		}
+	case xsdChild(n, "simpleType", "complexType") != nil:
+		e.typ = s.typeDef(xsdChild(n, "simpleType", "complexType"))
+	case attrValue(n, "substitutionGroup") != "":
+		// 未指定类型时使用头元素的类型
+		if q, ok := s.resolveQName(n, strings.Fields(attrValue(n, "substitutionGroup"))[0]); ok {
+			if head := s.globalElement(q); head != nil {
+				e.typ = head.typ
+			}
+		}
+	}
+	if e.typ == nil {
+		e.typ = s.builtin("anyType")
+	}
+	return e
+}
+
+// typeByName 按名称查找类型，n 为引用所在的模式节点
+func (s *xsdSchema) typeByName(n *xmlNode, q xsdQName) *xsdType {
+	if q.space == xsdNamespace {
+		if t := s.builtin(q.local); t != nil {
+			return t
+		}
+		s.fail(n, "未知的内置类型 xs:%s", q.local)
+		return nil
+	}
+	def, ok := s.component(s.types, n, q)
+	if !ok {
+		s.fail(n, "引用的类型 %s 未定义", q)
+		return nil
+	}
+	return s.typeDef(def)
+}
+
+// typeDef 编译 simpleType 或 complexType 定义
+func (s *xsdSchema) typeDef(n *xmlNode) *xsdType {
+	if t, ok := s.typeCache[n]; ok {
+		return t
+	}
+	t := &xsdType{}
+	if name := attrValue(n, "name"); name != "" {
+		t.name = xsdQName{s.docFor(n).targetNamespace, name}
+	}
+	// 先放入缓存，以支持递归引用的类型
+	s.typeCache[n] = t
+	if n.name.local == "simpleType" {
+		t.simple = s.simpleType(n)
+	} else {
+		t.complex = s.complexType(n)
+	}
+	return t
+}
+
+// builtin 返回内置类型
+func (s *xsdSchema) builtin(name string) *xsdType {
+	if t, ok := s.builtinCache[name]; ok {
+		return t
+	}
+	t := &xsdType{name: xsdQName{xsdNamespace, name}}
+	switch {
+	case name == "anyType":
+		t.complex = &xsdComplexType{anyType: true, mixed: true}
+	case xsdListBuiltins[name] != "":
+		t.simple = &xsdSimpleType{variety: xsdList, item: s.builtin(xsdListBuiltins[name]), builtin: name}
+		t.simple.facets.minLength = intPtr(1)
+	case isXSDBuiltin(name):
+		t.simple = &xsdSimpleType{builtin: name}
+	default:
+		return nil
+	}
+	s.builtinCache[name] = t
+	return t
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// complexType 编译复杂类型
+func (s *xsdSchema) complexType(n *xmlNode) *xsdComplexType {
+	ct := &xsdComplexType{
+		mixed:    attrValue(n, "mixed") == "true",
+		abstract: attrValue(n, "abstract") == "true",
+	}
+
+	if content := xsdChild(n, "simpleContent"); content != nil {
+		derivation := xsdChild(content, "extension", "restriction")
+		if derivation == nil {
+			s.fail(content, "simpleContent 中缺少 extension 或 restriction")
+			return ct
+		}
+		base := s.derivationBase(derivation)
+		if base != nil {
+			switch {
+			case base.simple != nil:
+				ct.simple = base
+			case base.complex != nil && base.complex.simple != nil:
+				ct.simple = base.complex.simple
+				ct.attributes = base.complex.attributes
+				ct.anyAttribute = base.complex.anyAttribute
+			default:
+				s.fail(derivation, "simpleContent 的基类型 %s 不是简单类型或简单内容", base.display())
+			}
+		}
+		if derivation.name.local == "restriction" && ct.simple != nil {
+			restricted := &xsdType{simple: &xsdSimpleType{base: ct.simple, builtin: ct.simple.simple.builtin}}
+			s.facets(derivation, restricted.simple)
+			ct.simple = restricted
+		}
+		s.attributeUses(derivation, ct, derivation.name.local == "restriction")
+		return ct
+	}
+
+	if content := xsdChild(n, "complexContent"); content != nil {
+		if mixed := content.attr("", "mixed"); mixed != nil {
+			ct.mixed = mixed.value == "true"
+		}
+		derivation := xsdChild(content, "extension", "restriction")
+		if derivation == nil {
+			s.fail(content, "complexContent 中缺少 extension 或 restriction")
+			return ct
+		}
+		own := s.modelGroup(derivation)
+		base := s.derivationBase(derivation)
+		if base != nil && base.complex != nil {
+			if base.complex.anyType {
+				// 派生自 anyType 时只使用自身的内容模型
+				base = nil
+			} else {
+				ct.attributes = base.complex.attributes
+				ct.anyAttribute = base.complex.anyAttribute
+			}
+		}
+		ct.particle = own
+		if derivation.name.local == "extension" && base != nil && base.complex != nil {
+			switch {
+			case base.complex.particle == nil:
+			case own == nil:
+				ct.particle = base.complex.particle
+			default:
+				ct.particle = &xsdParticle{kind: particleSequence, min: 1, max: 1, children: []*xsdParticle{base.complex.particle, own}}
+			}
+		}
+		s.attributeUses(derivation, ct, derivation.name.local == "restriction")
+		return ct
+	}
+
+	ct.particle = s.modelGroup(n)
+	s.attributeUses(n, ct, false)
+	return ct
+}
+
+// derivationBase 返回 extension 或 restriction 的基类型
+func (s *xsdSchema) derivationBase(derivation *xmlNode) *xsdType {
+	if base := attrValue(derivation, "base"); base != "" {
+		if q, ok := s.resolveQName(derivation, base); ok {
+			return s.typeByName(derivation, q)
+		}
+		return nil
+	}
+	if inline := xsdChild(derivation, "simpleType"); inline != nil {
+		return s.typeDef(inline)
+	}
+	s.fail(derivation, "缺少 base 属性")
+	return nil
+}
+
+// modelGroup 编译节点下的 sequence、choice、all 或 group 引用
+func (s *xsdSchema) modelGroup(n *xmlNode) *xsdParticle {
+	if child := xsdChild(n, "sequence", "choice", "all", "group"); child != nil {
+		return s.particle(child)
+	}
+	return nil
+}
+
+// occurs 读取 minOccurs 和 maxOccurs
+func occurs(n *xmlNode) (int, int) {
+	min, max := 1, 1
+	if v := attrValue(n, "minOccurs"); v != "" {
+		min, _ = strconv.Atoi(v)
+	}
+	if v := attrValue(n, "maxOccurs"); v == "unbounded" {
+		max = -1
+	} else if v != "" {
+		max, _ = strconv.Atoi(v)
+	}
+	return min, max
+}
+
+// particle 编译内容模型中的粒子
+func (s *xsdSchema) particle(n *xmlNode) *xsdParticle {
+	min, max := occurs(n)
+	p := &xsdParticle{min: min, max: max}
+	switch n.name.local {
+	case "element":
+		p.kind = particleElement
+		if p.element = s.element(n); p.element == nil {
+			return nil
+		}
+	case "any":
+		p.kind = particleAny
+		p.wildcard = s.wildcard(n)
+	case "group":
+		ref := attrValue(n, "ref")
+		q, ok := s.resolveQName(n, ref)
+		if !ok {
+			return nil
+		}
+		def, ok := s.component(s.groups, n, q)
+		if !ok {
+			s.fail(n, "引用的模型组 %s 未定义", q)
+			return nil
+		}
+		inner := s.modelGroup(def)
+		if inner == nil {
+			return nil
+		}
+		// 模型组引用的出现次数作用于组内的 sequence、choice 或 all
+		group := *inner
+		group.min, group.max = min, max
+		return &group
+	case "sequence", "choice", "all":
+		p.kind = map[string]int{"sequence": particleSequence, "choice": particleChoice, "all": particleAll}[n.name.local]
+		for _, child := range xsdChildren(n) {
+			switch child.name.local {
+			case "element", "any", "group", "sequence", "choice":
+				if c := s.particle(child); c != nil {
+					p.children = append(p.children, c)
+				}
+			}
+		}
+	}
+	return p
+}
+
+// wildcard 编译 any 或 anyAttribute
+func (s *xsdSchema) wildcard(n *xmlNode) *xsdWildcard {
+	w := &xsdWildcard{tns: s.docFor(n).targetNamespace, process: attrValue(n, "processContents")}
+	if w.process == "" {
+		w.process = "strict"
+	}
+	switch namespace := strings.TrimSpace(attrValue(n, "namespace")); namespace {
+	case "", "##any":
+		w.any = true
+	case "##other":
+		w.other = true
+	default:
+		for _, ns := range strings.Fields(namespace) {
+			switch ns {
+			case "##targetNamespace":
+				ns = w.tns
+			case "##local":
+				ns = ""
+			}
+			w.namespaces = append(w.namespaces, ns)
+		}
+	}
+	return w
+}
+
+// attributeUses 读取节点下的属性声明、属性组引用和 anyAttribute，覆盖从基类型继承的同名属性
+func (s *xsdSchema) attributeUses(n *xmlNode, ct *xsdComplexType, restriction bool) {
+	uses := append([]*xsdAttrUse(nil), ct.attributes...)
+	var own []*xsdAttrUse
+	var wildcard *xsdWildcard
+	s.collectAttributes(n, &own, &wildcard, map[*xmlNode]bool{})
+	for _, use := range own {
+		replaced := false
+		for i, existing := range uses {
+			if existing.name == use.name {
+				uses[i], replaced = use, true
+			}
+		}
+		if !replaced {
+			uses = append(uses, use)
+		}
+	}
+	ct.attributes = uses
+	if wildcard != nil || restriction {
+		ct.anyAttribute = wildcard
+	}
+}
+
+func (s *xsdSchema) collectAttributes(n *xmlNode, uses *[]*xsdAttrUse, wildcard **xsdWildcard, visiting map[*xmlNode]bool) {
+	for _, child := range xsdChildren(n) {
+		switch child.name.local {
+		case "attribute":
+			if use := s.attributeUse(child); use != nil {
+				*uses = append(*uses, use)
+			}
+		case "attributeGroup":
+			q, ok := s.resolveQName(child, attrValue(child, "ref"))
+			if !ok {
+				continue
+			}
+			def, ok := s.component(s.attributeGroups, child, q)
+			if !ok {
+				s.fail(child, "引用的属性组 %s 未定义", q)
+				continue
+			}
+			if !visiting[def] {
+				visiting[def] = true
+				s.collectAttributes(def, uses, wildcard, visiting)
+			}
+		case "anyAttribute":
+			*wildcard = s.wildcard(child)
+		}
+	}
+}
+
+// attributeUse 编译属性声明或属性引用
+func (s *xsdSchema) attributeUse(n *xmlNode) *xsdAttrUse {
+	use := &xsdAttrUse{}
+	switch attrValue(n, "use") {
+	case "required":
+		use.required = true
+	case "prohibited":
+		use.prohibited = true
+	}
+	decl := n
+	if ref := attrValue(n, "ref"); ref != "" {
+		q, ok := s.resolveQName(n, ref)
+		if !ok {
+			return nil
+		}
+		use.name = q
+		if q.space == xmlNamespace {
+			// xml:lang 等属性无需导入 xml.xsd
+			use.typ = s.builtin("string")
+			return use
+		}
+		global, ok := s.attributes[q]
+		if !ok {
+			s.fail(n, "引用的属性 %s 未定义", q)
+			return nil
+		}
+		decl = global
+	} else {
+		use.name = xsdQName{local: attrValue(n, "name")}
+		doc := s.docFor(n)
+		form := attrValue(n, "form")
+		global := n.parent != nil && n.parent.name.local == "schema"
+		if global || form == "qualified" || (form == "" && doc.attributeQualified) {
+			use.name.space = doc.targetNamespace
+		}
+	}
+
+	if a := n.attr("", "fixed"); a != nil {
+		use.fixed = &a.value
+	} else if a := decl.attr("", "fixed"); a != nil {
+		use.fixed = &a.value
+	}
+	switch typeName := attrValue(decl, "type"); {
+	case typeName != "":
+		if q, ok := s.resolveQName(decl, typeName); ok {
+			use.typ = s.typeByName(decl, q)
+		}
+	case xsdChild(decl, "simpleType") != nil:
+		use.typ = s.typeDef(xsdChild(decl, "simpleType"))
+	}
+	if use.typ == nil {
+		use.typ = s.builtin("anySimpleType")
+	}
+	return use
+}
+
+// ==================== 实例校验 ====================
+
+// xsdIDRef 待检查的 IDREF
+type xsdIDRef struct {
+	value  string
+	node   *xmlNode
+	line   int
+	column int
+}
+
+// xsdValidator 按模式校验实例文档
+type xsdValidator struct {
+	schema     *xsdSchema
+	violations []XSDViolation
+	ids        map[string]bool
+	idrefs     []xsdIDRef
+}
+
+// report 记录问题，n 为所在的元素
+func (v *xsdValidator) report(n *xmlNode, line, column int, format string, args ...interface{}) {
+	for n.kind != xmlElement && n.parent != nil {
+		n = n.parent
+	}
+	v.violations = append(v.violations, XSDViolation{
+		Line:    line,
+		Column:  column,
+		Path:    xmlLocationPath(n),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// element 按元素声明校验元素
+func (v *xsdValidator) element(el *xmlNode, decl *xsdElement) {
+	if decl.abstract {
+		v.report(el, el.line, el.column, "元素 <%s> 是抽象元素，不能直接使用", el.name.qualified())
+	}
+	typ := decl.typ
+	if a := el.attr(xsiNamespace, "type"); a != nil {
+		prefix, local, found := strings.Cut(strings.TrimSpace(a.value), ":")
+		if !found {
+			prefix, local = "", prefix
+		}
+		space, ok := el.lookupNamespace(prefix)
+		var override *xsdType
+		if ok {
+			q := xsdQName{space, local}
+			if def, exists := v.schema.types[q]; exists {
+				override = v.schema.typeDef(def)
+			} else if space == xsdNamespace {
+				override = v.schema.builtin(local)
+			}
+		}
+		if override == nil {
+			v.report(el, a.line, a.column, "xsi:type 引用的类型 %s 未定义", a.value)
+		} else {
+			typ = override
+		}
+	}
+	if typ == nil {
+		return
+	}
+
+	if a := el.attr(xsiNamespace, "nil"); a != nil && (a.value == "true" || a.value == "1") {
+		if !decl.nillable {
+			v.report(el, a.line, a.column, "元素 <%s> 不允许为 nil", el.name.qualified())
+		}
+		if typ.complex != nil {
+			v.attributes(el, typ.complex)
+		}
+		for _, child := range el.children {
+			if child.kind == xmlElement || child.kind == xmlCData || (child.kind == xmlText && !child.isWhitespace()) {
+				v.report(el, child.line, child.column, "xsi:nil 为 true 的元素 <%s> 必须为空", el.name.qualified())
+				break
+			}
+		}
+		return
+	}
+
+	if typ.complex == nil {
+		v.noAttributes(el)
+		if !v.noChildElements(el, "元素 <%s> 是简单类型 %s，不能包含子元素", el.name.qualified(), typ.display()) {
+			return
+		}
+		v.elementValue(el, decl, typ)
+		return
+	}
+
+	ct := typ.complex
+	if ct.abstract && el.attr(xsiNamespace, "type") == nil {
+		v.report(el, el.line, el.column, "元素 <%s> 的类型 %s 是抽象类型，需要通过 xsi:type 指定具体类型", el.name.qualified(), typ.display())
+	}
+	if ct.anyType {
+		v.lax(el)
+		return
+	}
+	v.attributes(el, ct)
+
+	if ct.simple != nil {
+		if v.noChildElements(el, "元素 <%s> 只能包含文本，不能包含子元素", el.name.qualified()) {
+			v.elementValue(el, decl, ct.simple)
+		}
+		return
+	}
+
+	var elements []*xmlNode
+	textReported := false
+	for _, child := range el.children {
+		switch {
+		case child.kind == xmlElement:
+			elements = append(elements, child)
+		case !ct.mixed && !textReported && (child.kind == xmlCData || (child.kind == xmlText && !child.isWhitespace())):
+			v.report(el, child.line, child.column, "元素 <%s> 不允许包含文本内容", el.name.qualified())
+			textReported = true
+		}
+	}
+	if decl.fixed != nil && ct.mixed && len(elements) == 0 && el.textContent() != *decl.fixed {
+		v.report(el, el.line, el.column, "元素 <%s> 的值必须为固定值 %q", el.name.qualified(), *decl.fixed)
+	}
+
+	if ct.particle == nil {
+		if len(elements) > 0 {
+			v.report(el, elements[0].line, elements[0].column, "元素 <%s> 不允许包含子元素 <%s>", el.name.qualified(), elements[0].name.qualified())
+		}
+		return
+	}
+	v.content(el, ct.particle, elements)
+}
+
+// lax 宽松校验 anyType 或 lax 通配符匹配的内容：只校验在模式中声明过的子元素
+func (v *xsdValidator) lax(el *xmlNode) {
+	for _, child := range el.children {
+		if child.kind != xmlElement {
+			continue
+		}
+		if decl := v.schema.globalElement(xsdQName{child.name.space, child.name.local}); decl != nil {
+			v.element(child, decl)
+		} else {
+			v.lax(child)
+		}
+	}
+}
+
+// noAttributes 简单类型的元素只允许命名空间声明和 xsi、xml 属性
+func (v *xsdValidator) noAttributes(el *xmlNode) {
+	for _, a := range el.attrs {
+		if !a.isNamespaceDecl() && a.name.space != xsiNamespace && a.name.space != xmlNamespace {
+			v.report(el, a.line, a.column, "元素 <%s> 不允许有属性 %s", el.name.qualified(), a.name.qualified())
+		}
+	}
+}
+
+// noChildElements 检查元素不包含子元素，包含时报告问题并返回 false
+func (v *xsdValidator) noChildElements(el *xmlNode, format string, args ...interface{}) bool {
+	for _, child := range el.children {
+		if child.kind == xmlElement {
+			v.report(el, child.line, child.column, format, args...)
+			return false
+		}
+	}
+	return true
+}
+
+// elementValue 校验元素的文本值
+func (v *xsdValidator) elementValue(el *xmlNode, decl *xsdElement, typ *xsdType) {
+	value := el.textContent()
+	if decl.fixed != nil {
+		if value == "" {
+			return
+		}
+		if v.schema.normalize(typ, value) != v.schema.normalize(typ, *decl.fixed) {
+			v.report(el, el.line, el.column, "元素 <%s> 的值必须为固定值 %q", el.name.qualified(), *decl.fixed)
+			return
+		}
+	}
+	if msg := v.schema.checkSimple(typ, value); msg != "" {
+		v.report(el, el.line, el.column, "元素 <%s> 的值无效: %s", el.name.qualified(), msg)
+		return
+	}
+	v.identity(el, typ, value, el.line, el.column)
+}
+
+// identity 记录 ID 并收集 IDREF，供全部校验完成后检查
+func (v *xsdValidator) identity(el *xmlNode, typ *xsdType, value string, line, column int) {
+	switch v.schema.builtinOf(typ) {
+	case "ID":
+		value = strings.TrimSpace(value)
+		if v.ids[value] {
+			v.report(el, line, column, "ID 值 %q 重复", value)
+		}
+		v.ids[value] = true
+	case "IDREF":
+		v.idrefs = append(v.idrefs, xsdIDRef{strings.TrimSpace(value), el, line, column})
+	case "IDREFS":
+		for _, ref := range strings.Fields(value) {
+			v.idrefs = append(v.idrefs, xsdIDRef{ref, el, line, column})
+		}
+	}
+}
+
+// attributes 校验元素的属性
+func (v *xsdValidator) attributes(el *xmlNode, ct *xsdComplexType) {
+	present := map[xsdQName]bool{}
+	for _, a := range el.attrs {
+		if a.isNamespaceDecl() || a.name.space == xsiNamespace {
+			continue
+		}
+		name := xsdQName{a.name.space, a.name.local}
+		present[name] = true
+		var use *xsdAttrUse
+		for _, u := range ct.attributes {
+			if u.name == name {
+				use = u
+				break
+			}
+		}
+		if use == nil || use.prohibited {
+			switch {
+			case ct.anyAttribute != nil && ct.anyAttribute.allows(name.space):
+				v.wildcardAttribute(el, a, ct.anyAttribute)
+			case a.name.space == xmlNamespace && use == nil:
+				// 未声明的 xml:lang、xml:space 等属性不视为错误
+			default:
+				v.report(el, a.line, a.column, "元素 <%s> 不允许有属性 %s", el.name.qualified(), a.name.qualified())
+			}
+			continue
+		}
+		v.attributeValue(el, a, use.typ, use.fixed)
+	}
+	for _, use := range ct.attributes {
+		if use.required && !present[use.name] {
+			v.report(el, el.line, el.column, "元素 <%s> 缺少必需的属性 %s", el.name.qualified(), use.name.local)
+		}
+	}
+}
+
+// wildcardAttribute 按 anyAttribute 的 processContents 校验属性
+func (v *xsdValidator) wildcardAttribute(el *xmlNode, a *xmlAttr, w *xsdWildcard) {
+	if w.process == "skip" {
+		return
+	}
+	def, ok := v.schema.attributes[xsdQName{a.name.space, a.name.local}]
+	if !ok {
+		if w.process == "strict" {
+			v.report(el, a.line, a.column, "属性 %s 没有在模式中声明", a.name.qualified())
+		}
+		return
+	}
+	if use := v.schema.attributeUse(def); use != nil {
+		v.attributeValue(el, a, use.typ, use.fixed)
+	}
+}
+
+func (v *xsdValidator) attributeValue(el *xmlNode, a *xmlAttr, typ *xsdType, fixed *string) {
+	if typ == nil {
+		return
+	}
+	if fixed != nil && v.schema.normalize(typ, a.value) != v.schema.normalize(typ, *fixed) {
+		v.report(el, a.line, a.column, "属性 %s 的值必须为固定值 %q", a.name.qualified(), *fixed)
+		return
+	}
+	if msg := v.schema.checkSimple(typ, a.value); msg != "" {
+		v.report(el, a.line, a.column, "属性 %s 的值无效: %s", a.name.qualified(), msg)
+		return
+	}
+	v.identity(el, typ, a.value, a.line, a.column)
+}
+
+// content 按内容模型校验子元素的顺序和出现次数，再逐个校验子元素
+func (v *xsdValidator) content(el *xmlNode, particle *xsdParticle, elements []*xmlNode) {
+	m := &xsdMatcher{schema: v.schema, elements: elements, furthest: -1}
+	ends := m.particle(particle, map[int]bool{0: true})
+	if !ends[len(elements)] {
+		v.contentError(el, m)
+	}
+	for _, child := range elements {
+		decl, wildcard := m.declFor(particle, child)
+		switch {
+		case decl != nil:
+			v.element(child, decl)
+		case wildcard != nil && wildcard.process != "skip":
+			if global := v.schema.globalElement(xsdQName{child.name.space, child.name.local}); global != nil {
+				v.element(child, global)
+			} else if wildcard.process == "strict" {
+				v.report(child, child.line, child.column, "元素 <%s> 没有在模式中声明", child.name.qualified())
+			} else {
+				v.lax(child)
+			}
+		}
+	}
+}
+
+// contentError 根据匹配过程中走得最远的位置报告内容模型错误
+func (v *xsdValidator) contentError(el *xmlNode, m *xsdMatcher) {
+	expected := strings.Join(m.expected, "、")
+	if m.reached > m.furthest {
+		child := m.elements[m.reached]
+		v.report(child, child.line, child.column, "元素 <%s> 中不允许在此处出现 <%s>", el.name.qualified(), child.name.qualified())
+		return
+	}
+	if m.furthest >= len(m.elements) {
+		v.report(el, el.line, el.column, "元素 <%s> 的内容不完整，缺少 %s", el.name.qualified(), expected)
+		return
+	}
+	child := m.elements[m.furthest]
+	v.report(child, child.line, child.column, "元素 <%s> 中出现了意外的 <%s>，期望 %s", el.name.qualified(), child.name.qualified(), expected)
+}
+
+// xsdMatcher 用位置集合匹配内容模型，可以处理可选项和选择分支，无需回溯
+type xsdMatcher struct {
+	schema   *xsdSchema
+	elements []*xmlNode
+	furthest int      // 匹配失败的最远位置
+	expected []string // 在最远失败位置上期望的元素
+	reached  int      // 成功匹配到的最远位置
+}
+
+func (m *xsdMatcher) expect(pos int, what string) {
+	switch {
+	case pos > m.furthest:
+		m.furthest, m.expected = pos, []string{what}
+	case pos == m.furthest:
+		for _, e := range m.expected {
+			if e == what {
+				return
+			}
+		}
+		m.expected = append(m.expected, what)
+	}
+}
+
+func (m *xsdMatcher) advance(result map[int]bool, pos int) {
+	result[pos] = true
+	m.reached = max(m.reached, pos)
+}
+
+// particle 返回从 starts 中各位置开始匹配粒子（包括出现次数）后可能到达的位置
+func (m *xsdMatcher) particle(p *xsdParticle, starts map[int]bool) map[int]bool {
+	result := map[int]bool{}
+	if p.min == 0 {
+		for pos := range starts {
+			result[pos] = true
+		}
+	}
+	seen := map[int]bool{}
+	current := starts
+	for count := 1; p.max < 0 || count <= p.max; count++ {
+		next := m.term(p, current)
+		progressed := false
+		for pos := range next {
+			if !seen[pos] {
+				seen[pos], progressed = true, true
+			}
+		}
+		if count >= p.min || !progressed {
+			// 没有进展时剩余的次数只能匹配空内容
+			for pos := range next {
+				result[pos] = true
+			}
+		}
+		if !progressed {
+			break
+		}
+		current = next
+	}
+	return result
+}
+
+// term 匹配粒子一次
+func (m *xsdMatcher) term(p *xsdParticle, starts map[int]bool) map[int]bool {
+	result := map[int]bool{}
+	switch p.kind {
+	case particleElement, particleAny:
+		for pos := range starts {
+			if pos < len(m.elements) && m.accepts(p, m.elements[pos]) {
+				m.advance(result, pos+1)
+			} else {
+				m.expect(pos, m.describe(p))
+			}
+		}
+	case particleSequence:
+		current := starts
+		for _, child := range p.children {
+			if current = m.particle(child, current); len(current) == 0 {
+				break
+			}
+		}
+		for pos := range current {
+			result[pos] = true
+		}
+	case particleChoice:
+		for _, child := range p.children {
+			for pos := range m.particle(child, starts) {
+				result[pos] = true
+			}
+		}
+	case particleAll:
+		for start := range starts {
+			used := make([]bool, len(p.children))
+			pos := start
+			for pos < len(m.elements) {
+				matched := false
+				for i, child := range p.children {
+					if !used[i] && m.accepts(child, m.elements[pos]) {
+						used[i], matched = true, true
+						break
+					}
+				}
+				if !matched {
+					break
+				}
+				pos++
+			}
+			complete := true
+			for i, child := range p.children {
+				if !used[i] && child.min > 0 {
+					m.expect(pos, m.describe(child))
+					complete = false
+				}
+			}
+			if complete {
+				m.advance(result, pos)
+			}
+		}
+	}
+	return result
+}
+
+// accepts 元素粒子或通配符是否接受该元素
+func (m *xsdMatcher) accepts(p *xsdParticle, el *xmlNode) bool {
+	name := xsdQName{el.name.space, el.name.local}
+	switch p.kind {
+	case particleElement:
+		return m.schema.substitutable(p.element.name, name)
+	case particleAny:
+		return p.wildcard.allows(name.space)
+	}
+	return false
+}
+
+// describe 返回粒子期望的元素，用于错误信息
+func (m *xsdMatcher) describe(p *xsdParticle) string {
+	if p.kind == particleAny {
+		return "任意元素"
+	}
+	return "<" + p.element.name.local + ">"
+}
+
+// declFor 查找内容模型中与子元素对应的元素声明或通配符
+func (m *xsdMatcher) declFor(p *xsdParticle, el *xmlNode) (*xsdElement, *xsdWildcard) {
+	switch p.kind {
+	case particleElement:
+		name := xsdQName{el.name.space, el.name.local}
+		if p.element.name == name {
+			return p.element, nil
+		}
+		if m.schema.substitutable(p.element.name, name) {
+			return m.schema.globalElement(name), nil
+		}
+	case particleAny:
+		if p.wildcard.allows(el.name.space) {
+			return nil, p.wildcard
+		}
+	default:
+		var wildcard *xsdWildcard
+		for _, child := range p.children {
+			decl, w := m.declFor(child, el)
+			if decl != nil {
+				return decl, nil
+			}
+			if wildcard == nil {
+				wildcard = w
+			}
+		}
+		return nil, wildcard
+	}
+	return nil, nil
+}
+
+// substitutable name 是否为 head 本身或 head 替换组（包括间接成员）中的元素
+func (s *xsdSchema) substitutable(head, name xsdQName) bool {
+	if head == name {
+		return true
+	}
+	visited := map[xsdQName]bool{head: true}
+	queue := []xsdQName{head}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, member := range s.substitutions[current] {
+			if member == name {
+				return true
+			}
+			if !visited[member] {
+				visited[member] = true
+				queue = append(queue, member)
+			}
+		}
+	}
+	return false
+}
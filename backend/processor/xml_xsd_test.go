@@ -0,0 +1,295 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSchemas 将模式文件写入临时目录，返回第一个文件的路径
+func writeSchemas(t *testing.T, files ...[2]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f[0]), []byte(f[1]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, files[0][0])
+}
+
+const xsdBase = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="person">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="code">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:group name="extra">
+    <xs:sequence>
+      <xs:element name="note" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:attributeGroup name="common">
+    <xs:attribute name="id" type="xs:string"/>
+  </xs:attributeGroup>
+</xs:schema>`
+
+func violationMessages(violations []XSDViolation) string {
+	var messages []string
+	for _, v := range violations {
+		messages = append(messages, v.Path+": "+v.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+func TestValidateXSDRedefine(t *testing.T) {
+	main := writeSchemas(t, [2]string{"main.xsd", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:redefine schemaLocation="base.xsd">
+    <xs:complexType name="person">
+      <xs:complexContent>
+        <xs:extension base="person">
+          <xs:sequence>
+            <xs:element name="age" type="xs:int"/>
+          </xs:sequence>
+        </xs:extension>
+      </xs:complexContent>
+    </xs:complexType>
+    <xs:simpleType name="code">
+      <xs:restriction base="code">
+        <xs:pattern value="[A-Z]+"/>
+      </xs:restriction>
+    </xs:simpleType>
+    <xs:group name="extra">
+      <xs:sequence>
+        <xs:group ref="extra"/>
+        <xs:element name="tag" type="xs:string" minOccurs="0"/>
+      </xs:sequence>
+    </xs:group>
+    <xs:attributeGroup name="common">
+      <xs:attributeGroup ref="common"/>
+      <xs:attribute name="lang" type="xs:string" use="required"/>
+    </xs:attributeGroup>
+  </xs:redefine>
+  <xs:element name="person">
+    <xs:complexType>
+      <xs:complexContent>
+        <xs:extension base="person">
+          <xs:sequence>
+            <xs:element name="code" type="code"/>
+            <xs:group ref="extra"/>
+          </xs:sequence>
+          <xs:attributeGroup ref="common"/>
+        </xs:extension>
+      </xs:complexContent>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`}, [2]string{"base.xsd", xsdBase})
+
+	x := NewXMLProcessor()
+	tests := []struct {
+		name  string
+		input string
+		want  []string // 期望的违规信息片段，为空表示有效
+	}{
+		{"valid", `<person id="1" lang="en"><name>a</name><age>3</age><code>AB</code><note>n</note><tag>t</tag></person>`, nil},
+		{"missing redefined element", `<person lang="en"><name>a</name><code>AB</code></person>`, []string{"age"}},
+		{"redefined simple type keeps original facet", `<person lang="en"><name>a</name><age>3</age><code>ABCDEF</code></person>`, []string{"code"}},
+		{"redefined simple type adds facet", `<person lang="en"><name>a</name><age>3</age><code>ab</code></person>`, []string{"code"}},
+		{"redefined attribute group", `<person><name>a</name><age>3</age><code>AB</code></person>`, []string{"lang"}},
+	}
+	for _, tt := range tests {
+		violations, err := x.ValidateXSD(tt.input, []string{main})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := violationMessages(violations)
+		if len(tt.want) == 0 && got != "" {
+			t.Errorf("%s: unexpected violations:\n%s", tt.name, got)
+		}
+		if len(tt.want) > 0 && len(violations) == 0 {
+			t.Errorf("%s: expected violations", tt.name)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: violations do not mention %q:\n%s", tt.name, want, got)
+			}
+		}
+	}
+}
+
+func TestValidateXSDOverride(t *testing.T) {
+	main := writeSchemas(t, [2]string{"main.xsd", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:override schemaLocation="base.xsd">
+    <xs:simpleType name="code">
+      <xs:restriction base="xs:int"/>
+    </xs:simpleType>
+  </xs:override>
+  <xs:element name="code" type="code"/>
+</xs:schema>`}, [2]string{"base.xsd", xsdBase})
+
+	x := NewXMLProcessor()
+	if violations, err := x.ValidateXSD(`<code>12345678</code>`, []string{main}); err != nil || len(violations) != 0 {
+		t.Errorf("override not applied: %v %s", err, violationMessages(violations))
+	}
+	if violations, err := x.ValidateXSD(`<code>AB</code>`, []string{main}); err != nil || len(violations) == 0 {
+		t.Errorf("expected a violation for a non-integer code: %v", err)
+	}
+}
+
+func TestValidateXSDRedefineErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"unknown component", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:redefine schemaLocation="base.xsd">
+    <xs:simpleType name="missing"><xs:restriction base="missing"/></xs:simpleType>
+  </xs:redefine>
+</xs:schema>`, "不存在"},
+		{"element in redefine", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:redefine schemaLocation="base.xsd">
+    <xs:element name="x"/>
+  </xs:redefine>
+</xs:schema>`, "不能定义"},
+	}
+	for _, tt := range tests {
+		main := writeSchemas(t, [2]string{"main.xsd", tt.schema}, [2]string{"base.xsd", xsdBase})
+		_, err := NewXMLProcessor().ValidateXSD(`<x/>`, []string{main})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// 订单模式通过 import 引用另一个目标命名空间中的地址元素
+const xsdOrder = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"
+    xmlns:a="urn:address" targetNamespace="urn:order" xmlns="urn:order" elementFormDefault="qualified">
+  <xs:import namespace="urn:address" schemaLocation="address.xsd"/>
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="id" type="orderId"/>
+        <xs:element name="qty" type="quantity" maxOccurs="3"/>
+        <xs:element ref="a:address" minOccurs="0"/>
+      </xs:sequence>
+      <xs:attribute name="status" type="status" use="required"/>
+    </xs:complexType>
+  </xs:element>
+  <xs:simpleType name="orderId">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{2}-[0-9]+"/>
+      <xs:maxLength value="8"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="quantity">
+    <xs:restriction base="xs:int">
+      <xs:minInclusive value="1"/>
+      <xs:maxExclusive value="100"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="status">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="open"/>
+      <xs:enumeration value="closed"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+
+const xsdAddress = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"
+    targetNamespace="urn:address" xmlns="urn:address" elementFormDefault="qualified">
+  <xs:element name="address">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="city" type="xs:string"/>
+        <xs:element name="zip">
+          <xs:simpleType>
+            <xs:restriction base="xs:string">
+              <xs:length value="5"/>
+            </xs:restriction>
+          </xs:simpleType>
+        </xs:element>
+      </xs:sequence>
+      <xs:attribute name="country" type="xs:string" use="required"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+
+func TestValidateXSD(t *testing.T) {
+	main := writeSchemas(t, [2]string{"order.xsd", xsdOrder}, [2]string{"address.xsd", xsdAddress})
+
+	type violation struct {
+		line, column int
+		path         string
+		message      string // 期望的违规信息片段
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []violation // 为空表示有效
+	}{
+		{"valid", `<order xmlns="urn:order" status="open"><id>AB-12</id><qty>1</qty><qty>99</qty><address xmlns="urn:address" country="DE"><city>Berlin</city><zip>10115</zip></address></order>`, nil},
+
+		// 序列
+		{"sequence out of order", `<order xmlns="urn:order" status="open"><qty>1</qty><id>AB-12</id></order>`,
+			[]violation{{1, 40, "/order[1]/qty[1]", "意外的 <qty>，期望 <id>"}}},
+		{"sequence exceeds maxOccurs", `<order xmlns="urn:order" status="open"><id>AB-12</id><qty>1</qty><qty>1</qty><qty>1</qty><qty>1</qty></order>`,
+			[]violation{{1, 90, "/order[1]/qty[4]", "意外的 <qty>"}}},
+		{"sequence incomplete", `<order xmlns="urn:order" status="open"><id>AB-12</id></order>`,
+			[]violation{{1, 1, "/order[1]", "内容不完整，缺少 <qty>"}}},
+
+		// 简单类型的约束
+		{"pattern", `<order xmlns="urn:order" status="open"><id>ab-12</id><qty>1</qty></order>`,
+			[]violation{{1, 40, "/order[1]/id[1]", "不匹配模式"}}},
+		{"maxLength", `<order xmlns="urn:order" status="open"><id>AB-123456</id><qty>1</qty></order>`,
+			[]violation{{1, 40, "/order[1]/id[1]", "要求不超过 8"}}},
+		{"minInclusive", `<order xmlns="urn:order" status="open"><id>AB-1</id><qty>0</qty></order>`,
+			[]violation{{1, 53, "/order[1]/qty[1]", "不能小于 1"}}},
+		{"maxExclusive", `<order xmlns="urn:order" status="open"><id>AB-1</id><qty>100</qty></order>`,
+			[]violation{{1, 53, "/order[1]/qty[1]", "必须小于 100"}}},
+		{"base type", `<order xmlns="urn:order" status="open"><id>AB-1</id><qty>x</qty></order>`,
+			[]violation{{1, 53, "/order[1]/qty[1]", "不是有效的 xs:int"}}},
+		{"enumeration", `<order xmlns="urn:order" status="pending"><id>AB-1</id><qty>1</qty></order>`,
+			[]violation{{1, 26, "/order[1]", "属性 status 的值无效"}}},
+
+		// 必需的属性
+		{"required attribute", `<order xmlns="urn:order"><id>AB-1</id><qty>1</qty></order>`,
+			[]violation{{1, 1, "/order[1]", "缺少必需的属性 status"}}},
+
+		// 导入的命名空间
+		{"imported element", `<order xmlns="urn:order" status="open"><id>AB-1</id><qty>1</qty><a:address xmlns:a="urn:address"><a:city>X</a:city><a:zip>1234</a:zip></a:address></order>`,
+			[]violation{
+				{1, 65, "/order[1]/a:address[1]", "缺少必需的属性 country"},
+				{1, 116, "/order[1]/a:address[1]/a:zip[1]", "要求等于 5"},
+			}},
+		{"imported element in wrong namespace", `<order xmlns="urn:order" status="open"><id>AB-1</id><qty>1</qty><address country="DE"><city>X</city><zip>12345</zip></address></order>`,
+			[]violation{{1, 65, "/order[1]/address[1]", "意外的 <address>"}}},
+
+		// 多行输入中的位置
+		{"multi-line location", "<order xmlns=\"urn:order\" status=\"open\">\n  <id>AB-1</id>\n  <qty>1</qty>\n  <qty>1000</qty>\n</order>",
+			[]violation{{4, 3, "/order[1]/qty[2]", "必须小于 100"}}},
+	}
+	x := NewXMLProcessor()
+	for _, tt := range tests {
+		violations, err := x.ValidateXSD(tt.input, []string{main})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(violations) != len(tt.want) {
+			t.Errorf("%s: got %d violations, want %d:\n%s", tt.name, len(violations), len(tt.want), violationMessages(violations))
+			continue
+		}
+		for i, want := range tt.want {
+			got := violations[i]
+			if got.Line != want.line || got.Column != want.column || got.Path != want.path || !strings.Contains(got.Message, want.message) {
+				t.Errorf("%s: got %d:%d %s %q, want %d:%d %s containing %q", tt.name, got.Line, got.Column, got.Path, got.Message, want.line, want.column, want.path, want.message)
+			}
+		}
+	}
+}
@@ -0,0 +1,815 @@
+package processor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 简单类型的种类
+const (
+	xsdAtomic = iota
+	xsdList
+	xsdUnion
+)
+
+// xsdSimpleType 简单类型，restriction 派生的类型先按基类型校验，再检查自身的约束面
+type xsdSimpleType struct {
+	variety int
+	base    *xsdType   // restriction 的基类型，内置类型和 list、union 定义为 nil
+	builtin string     // 最终派生自的内置类型
+	item    *xsdType   // list 的项类型
+	members []*xsdType // union 的成员类型
+	facets  xsdFacets
+}
+
+// xsdPattern pattern 约束面
+type xsdPattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
+// xsdFacets 一步派生中声明的约束面
+type xsdFacets struct {
+	enumeration    []string
+	patterns       []xsdPattern // 同一步派生中的多个 pattern 满足其一即可
+	length         *int
+	minLength      *int
+	maxLength      *int
+	minInclusive   *string
+	maxInclusive   *string
+	minExclusive   *string
+	maxExclusive   *string
+	totalDigits    *int
+	fractionDigits *int
+	whiteSpace     string
+}
+
+// xsdBuiltinBase 派生内置类型的基类型
+var xsdBuiltinBase = map[string]string{
+	"normalizedString":   "string",
+	"token":              "normalizedString",
+	"language":           "token",
+	"Name":               "token",
+	"NMTOKEN":            "token",
+	"NCName":             "Name",
+	"ID":                 "NCName",
+	"IDREF":              "NCName",
+	"ENTITY":             "NCName",
+	"integer":            "decimal",
+	"nonPositiveInteger": "integer",
+	"negativeInteger":    "nonPositiveInteger",
+	"long":               "integer",
+	"int":                "long",
+	"short":              "int",
+	"byte":               "short",
+	"nonNegativeInteger": "integer",
+	"unsignedLong":       "nonNegativeInteger",
+	"unsignedInt":        "unsignedLong",
+	"unsignedShort":      "unsignedInt",
+	"unsignedByte":       "unsignedShort",
+	"positiveInteger":    "nonNegativeInteger",
+}
+
+// xsdPrimitives 原始内置类型
+var xsdPrimitives = map[string]bool{
+	"anySimpleType": true, "string": true, "boolean": true, "decimal": true, "float": true, "double": true,
+	"duration": true, "dateTime": true, "time": true, "date": true, "gYearMonth": true, "gYear": true,
+	"gMonthDay": true, "gDay": true, "gMonth": true, "hexBinary": true, "base64Binary": true,
+	"anyURI": true, "QName": true, "NOTATION": true,
+}
+
+// xsdListBuiltins 内置列表类型及其项类型
+var xsdListBuiltins = map[string]string{
+	"NMTOKENS": "NMTOKEN",
+	"IDREFS":   "IDREF",
+	"ENTITIES": "ENTITY",
+}
+
+// xsdIntegerRanges 整数类型的取值范围，空字符串表示无界
+var xsdIntegerRanges = map[string][2]string{
+	"long":               {"-9223372036854775808", "9223372036854775807"},
+	"int":                {"-2147483648", "2147483647"},
+	"short":              {"-32768", "32767"},
+	"byte":               {"-128", "127"},
+	"unsignedLong":       {"0", "18446744073709551615"},
+	"unsignedInt":        {"0", "4294967295"},
+	"unsignedShort":      {"0", "65535"},
+	"unsignedByte":       {"0", "255"},
+	"nonNegativeInteger": {"0", ""},
+	"positiveInteger":    {"1", ""},
+	"nonPositiveInteger": {"", "0"},
+	"negativeInteger":    {"", "-1"},
+}
+
+// isNCName 是否为不含冒号的 XML 名称
+func isNCName(s string) bool {
+	return isXMLName(s) && !strings.Contains(s, ":")
+}
+
+func isXSDBuiltin(name string) bool {
+	return xsdPrimitives[name] || xsdBuiltinBase[name] != ""
+}
+
+// primitiveOf 返回内置类型派生自的原始类型
+func primitiveOf(name string) string {
+	for !xsdPrimitives[name] {
+		base, ok := xsdBuiltinBase[name]
+		if !ok {
+			return "anySimpleType"
+		}
+		name = base
+	}
+	return name
+}
+
+// derivesFrom 内置类型 name 是否为 ancestor 或派生自 ancestor
+func derivesFrom(name, ancestor string) bool {
+	for ; name != ""; name = xsdBuiltinBase[name] {
+		if name == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	xsdLanguagePattern = regexp.MustCompile(`^[a-zA-Z]{1,8}(-[a-zA-Z0-9]{1,8})*$`)
+	xsdDecimalPattern  = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	xsdIntegerPattern  = regexp.MustCompile(`^[+-]?\d+$`)
+	xsdFloatPattern    = regexp.MustCompile(`^([+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?|[+-]?INF|NaN)$`)
+	xsdDurationPattern = regexp.MustCompile(`^-?P(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?$`)
+	xsdHexPattern      = regexp.MustCompile(`^([0-9a-fA-F]{2})*$`)
+	xsdEndOfDayPattern = regexp.MustCompile(`^24:00:00(\.0+)?(Z|[+-]\d{2}:\d{2})?$`)
+	xsdZonePattern     = regexp.MustCompile(`(Z|[+-]\d{2}:\d{2})$`)
+
+	// 日期时间类型的格式，时区可选；各分组依次为年、月、日、时、分、秒中出现的部分
+	xsdDatePatterns = map[string]*regexp.Regexp{
+		"dateTime":   regexp.MustCompile(`^(-?\d{4,})-(\d{2})-(\d{2})T(\d{2}):(\d{2}):(\d{2}(?:\.\d+)?)(Z|[+-]\d{2}:\d{2})?$`),
+		"date":       regexp.MustCompile(`^(-?\d{4,})-(\d{2})-(\d{2})(Z|[+-]\d{2}:\d{2})?$`),
+		"time":       regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2}(?:\.\d+)?)(Z|[+-]\d{2}:\d{2})?$`),
+		"gYearMonth": regexp.MustCompile(`^(-?\d{4,})-(\d{2})(Z|[+-]\d{2}:\d{2})?$`),
+		"gYear":      regexp.MustCompile(`^(-?\d{4,})(Z|[+-]\d{2}:\d{2})?$`),
+		"gMonthDay":  regexp.MustCompile(`^--(\d{2})-(\d{2})(Z|[+-]\d{2}:\d{2})?$`),
+		"gMonth":     regexp.MustCompile(`^--(\d{2})(Z|[+-]\d{2}:\d{2})?$`),
+		"gDay":       regexp.MustCompile(`^---(\d{2})(Z|[+-]\d{2}:\d{2})?$`),
+	}
+	// xsdDateFields 日期时间类型的各分组对应的字段
+	xsdDateFields = map[string]string{
+		"dateTime":   "YMDhms",
+		"date":       "YMD",
+		"time":       "hms",
+		"gYearMonth": "YM",
+		"gYear":      "Y",
+		"gMonthDay":  "MD",
+		"gMonth":     "M",
+		"gDay":       "D",
+	}
+)
+
+// simpleType 编译简单类型定义
+func (s *xsdSchema) simpleType(n *xmlNode) *xsdSimpleType {
+	st := &xsdSimpleType{builtin: "anySimpleType"}
+	derivation := xsdChild(n, "restriction", "list", "union")
+	if derivation == nil {
+		s.fail(n, "simpleType 中缺少 restriction、list 或 union")
+		return st
+	}
+	switch derivation.name.local {
+	case "restriction":
+		base := s.derivationBase(derivation)
+		if base == nil {
+			return st
+		}
+		if base.simple == nil {
+			s.fail(derivation, "简单类型的基类型 %s 不是简单类型", base.display())
+			return st
+		}
+		st.base = base
+		st.variety, st.builtin = base.simple.variety, base.simple.builtin
+		st.item, st.members = base.simple.item, base.simple.members
+		s.facets(derivation, st)
+	case "list":
+		st.variety, st.builtin = xsdList, ""
+		if itemType := attrValue(derivation, "itemType"); itemType != "" {
+			if q, ok := s.resolveQName(derivation, itemType); ok {
+				st.item = s.typeByName(derivation, q)
+			}
+		} else if inline := xsdChild(derivation, "simpleType"); inline != nil {
+			st.item = s.typeDef(inline)
+		}
+		if st.item == nil {
+			st.item = s.builtin("anySimpleType")
+		}
+	case "union":
+		st.variety, st.builtin = xsdUnion, ""
+		for _, member := range strings.Fields(attrValue(derivation, "memberTypes")) {
+			if q, ok := s.resolveQName(derivation, member); ok {
+				if t := s.typeByName(derivation, q); t != nil {
+					st.members = append(st.members, t)
+				}
+			}
+		}
+		for _, inline := range xsdChildren(derivation) {
+			if inline.name.local == "simpleType" {
+				st.members = append(st.members, s.typeDef(inline))
+			}
+		}
+	}
+	return st
+}
+
+// facets 读取 restriction 中的约束面
+func (s *xsdSchema) facets(n *xmlNode, st *xsdSimpleType) {
+	for _, child := range xsdChildren(n) {
+		value := attrValue(child, "value")
+		number := func() *int {
+			i, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || i < 0 {
+				s.fail(child, "%s 的值 %q 不是非负整数", child.name.local, value)
+				return nil
+			}
+			return &i
+		}
+		f := &st.facets
+		switch child.name.local {
+		case "enumeration":
+			f.enumeration = append(f.enumeration, s.normalizeWith(s.whitespaceOf(st), value))
+		case "pattern":
+			if re := s.pattern(child, value); re != nil {
+				f.patterns = append(f.patterns, xsdPattern{value, re})
+			}
+		case "length":
+			f.length = number()
+		case "minLength":
+			f.minLength = number()
+		case "maxLength":
+			f.maxLength = number()
+		case "totalDigits":
+			f.totalDigits = number()
+		case "fractionDigits":
+			f.fractionDigits = number()
+		case "minInclusive":
+			f.minInclusive = &value
+		case "maxInclusive":
+			f.maxInclusive = &value
+		case "minExclusive":
+			f.minExclusive = &value
+		case "maxExclusive":
+			f.maxExclusive = &value
+		case "whiteSpace":
+			f.whiteSpace = value
+		}
+	}
+}
+
+// pattern 编译 XSD 正则表达式
+func (s *xsdSchema) pattern(n *xmlNode, source string) *regexp.Regexp {
+	if re, ok := s.patternCache[source]; ok {
+		return re
+	}
+	translated, err := translateXSDPattern(source)
+	var re *regexp.Regexp
+	if err == nil {
+		re, err = regexp.Compile(`^(?:` + translated + `)$`)
+	}
+	if err != nil {
+		s.fail(n, "无效的 pattern %q: %v", source, err)
+		return nil
+	}
+	s.patternCache[source] = re
+	return re
+}
+
+// builtinOf 返回简单类型最终派生自的内置类型
+func (s *xsdSchema) builtinOf(t *xsdType) string {
+	if t == nil || t.simple == nil {
+		return ""
+	}
+	return t.simple.builtin
+}
+
+// whitespaceOf 返回简单类型的空白处理方式：preserve、replace 或 collapse
+func (s *xsdSchema) whitespaceOf(st *xsdSimpleType) string {
+	for st != nil {
+		switch {
+		case st.facets.whiteSpace != "":
+			return st.facets.whiteSpace
+		case st.variety == xsdList:
+			return "collapse"
+		case st.base == nil && st.variety == xsdUnion:
+			return "preserve"
+		case st.base == nil:
+			switch {
+			case st.builtin == "anySimpleType" || st.builtin == "string":
+				return "preserve"
+			case st.builtin == "normalizedString":
+				return "replace"
+			}
+			return "collapse"
+		}
+		st = st.base.simple
+	}
+	return "preserve"
+}
+
+// normalize 按简单类型的空白处理方式规范化值
+func (s *xsdSchema) normalize(t *xsdType, value string) string {
+	if t == nil || t.simple == nil {
+		return value
+	}
+	return s.normalizeWith(s.whitespaceOf(t.simple), value)
+}
+
+func (s *xsdSchema) normalizeWith(whitespace, value string) string {
+	switch whitespace {
+	case "replace":
+		return strings.Map(func(r rune) rune {
+			if r == '\t' || r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, value)
+	case "collapse":
+		return strings.Join(strings.Fields(value), " ")
+	}
+	return value
+}
+
+// checkSimple 校验值是否符合简单类型，返回问题描述，合法时返回空字符串
+func (s *xsdSchema) checkSimple(t *xsdType, value string) string {
+	if t == nil || t.simple == nil {
+		return ""
+	}
+	st := t.simple
+	switch {
+	case st.base != nil:
+		if msg := s.checkSimple(st.base, value); msg != "" {
+			return msg
+		}
+	case st.variety == xsdList:
+		for _, item := range strings.Fields(value) {
+			if msg := s.checkSimple(st.item, item); msg != "" {
+				return msg
+			}
+		}
+	case st.variety == xsdUnion:
+		valid := false
+		for _, member := range st.members {
+			if s.checkSimple(member, value) == "" {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Sprintf("值 %q 不符合 %s 的任何成员类型", value, t.display())
+		}
+	default:
+		if msg := checkBuiltin(st.builtin, s.normalize(t, value)); msg != "" {
+			return msg
+		}
+	}
+	return s.checkFacets(st, s.normalize(t, value))
+}
+
+// checkBuiltin 校验内置原子类型的词法形式和取值范围
+func checkBuiltin(name, value string) string {
+	invalid := fmt.Sprintf("值 %q 不是有效的 xs:%s", value, name)
+	switch primitiveOf(name) {
+	case "string":
+		switch {
+		case derivesFrom(name, "normalizedString") && strings.ContainsAny(value, "\t\n\r"):
+			return invalid
+		case derivesFrom(name, "token") && (strings.HasPrefix(value, " ") || strings.HasSuffix(value, " ") || strings.Contains(value, "  ")):
+			return invalid
+		case name == "language" && !xsdLanguagePattern.MatchString(value):
+			return invalid
+		case derivesFrom(name, "NCName") && !isNCName(value):
+			return invalid
+		case name == "Name" && !isXMLName(value):
+			return invalid
+		case name == "NMTOKEN" && (value == "" || strings.IndexFunc(value, func(r rune) bool { return !isNameChar(r) }) >= 0):
+			return invalid
+		}
+	case "boolean":
+		if value != "true" && value != "false" && value != "1" && value != "0" {
+			return invalid
+		}
+	case "decimal":
+		if name == "decimal" {
+			if !xsdDecimalPattern.MatchString(value) {
+				return invalid
+			}
+			return ""
+		}
+		if !xsdIntegerPattern.MatchString(value) {
+			return invalid
+		}
+		if r, ok := xsdIntegerRanges[name]; ok {
+			n, _ := new(big.Int).SetString(strings.TrimPrefix(value, "+"), 10)
+			if min, _ := new(big.Int).SetString(r[0], 10); min != nil && n.Cmp(min) < 0 {
+				return fmt.Sprintf("值 %s 超出 xs:%s 的取值范围", value, name)
+			}
+			if max, _ := new(big.Int).SetString(r[1], 10); max != nil && n.Cmp(max) > 0 {
+				return fmt.Sprintf("值 %s 超出 xs:%s 的取值范围", value, name)
+			}
+		}
+	case "float", "double":
+		if !xsdFloatPattern.MatchString(value) {
+			return invalid
+		}
+	case "duration":
+		if !xsdDurationPattern.MatchString(value) || strings.HasSuffix(value, "P") || strings.HasSuffix(value, "T") {
+			return invalid
+		}
+	case "dateTime", "date", "time", "gYearMonth", "gYear", "gMonthDay", "gMonth", "gDay":
+		if !validXSDDate(primitiveOf(name), value) {
+			return invalid
+		}
+	case "hexBinary":
+		if !xsdHexPattern.MatchString(value) {
+			return invalid
+		}
+	case "base64Binary":
+		if _, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+			return invalid
+		}
+	case "QName", "NOTATION":
+		prefix, local, found := strings.Cut(value, ":")
+		if !found {
+			prefix, local = "", prefix
+		}
+		if (found && !isNCName(prefix)) || !isNCName(local) {
+			return invalid
+		}
+	}
+	return ""
+}
+
+// validXSDDate 校验日期时间类型的格式和各字段的范围
+func validXSDDate(name, value string) bool {
+	m := xsdDatePatterns[name].FindStringSubmatch(value)
+	if m == nil {
+		return false
+	}
+	fields := xsdDateFields[name]
+	year, month := 2000, 1
+	for i, field := range fields {
+		text := m[i+1]
+		if field == 's' {
+			sec, _ := strconv.ParseFloat(text, 64)
+			if sec >= 60 {
+				return false
+			}
+			continue
+		}
+		n, _ := strconv.Atoi(text)
+		switch field {
+		case 'Y':
+			year = n
+			if n == 0 {
+				return false
+			}
+		case 'M':
+			month = n
+			if n < 1 || n > 12 {
+				return false
+			}
+		case 'D':
+			// 没有年份时按闰年计算，允许 --02-29
+			days := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			if !strings.Contains(fields, "Y") {
+				days = time.Date(2000, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			}
+			if n < 1 || n > days {
+				return false
+			}
+		case 'h':
+			if n > 24 {
+				return false
+			}
+		case 'm':
+			if n > 59 {
+				return false
+			}
+		}
+	}
+	if strings.Contains(fields, "h") {
+		// 24:00:00 表示一天的结束，不允许其他 24 点的时间
+		clock := value
+		if i := strings.Index(value, "T"); i >= 0 {
+			clock = value[i+1:]
+		}
+		if strings.HasPrefix(clock, "24:") && !xsdEndOfDayPattern.MatchString(clock) {
+			return false
+		}
+	}
+	if zone := m[len(fields)+1]; len(zone) == 6 {
+		hour, _ := strconv.Atoi(zone[1:3])
+		minute, _ := strconv.Atoi(zone[4:6])
+		if hour > 14 || minute > 59 || (hour == 14 && minute != 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkFacets 检查一步派生中声明的约束面
+func (s *xsdSchema) checkFacets(st *xsdSimpleType, value string) string {
+	f := &st.facets
+	primitive := primitiveOf(st.builtin)
+
+	if f.length != nil || f.minLength != nil || f.maxLength != nil {
+		length, measurable := xsdLength(st, primitive, value)
+		switch {
+		case !measurable:
+		case f.length != nil && length != *f.length:
+			return fmt.Sprintf("值 %q 的长度为 %d，要求等于 %d", value, length, *f.length)
+		case f.minLength != nil && length < *f.minLength:
+			return fmt.Sprintf("值 %q 的长度为 %d，要求至少为 %d", value, length, *f.minLength)
+		case f.maxLength != nil && length > *f.maxLength:
+			return fmt.Sprintf("值 %q 的长度为 %d，要求不超过 %d", value, length, *f.maxLength)
+		}
+	}
+
+	if len(f.patterns) > 0 {
+		matched := false
+		for _, p := range f.patterns {
+			if p.re.MatchString(value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sources := make([]string, len(f.patterns))
+			for i, p := range f.patterns {
+				sources[i] = p.source
+			}
+			return fmt.Sprintf("值 %q 不匹配模式 %s", value, strings.Join(sources, " | "))
+		}
+	}
+
+	if len(f.enumeration) > 0 {
+		allowed := false
+		for _, e := range f.enumeration {
+			if st.variety == xsdAtomic {
+				if c, ok := compareXSDValues(primitive, value, e); ok {
+					allowed = c == 0
+				} else {
+					allowed = value == e
+				}
+			} else {
+				allowed = value == e
+			}
+			if allowed {
+				break
+			}
+		}
+		if !allowed {
+			values := f.enumeration
+			suffix := ""
+			if len(values) > 10 {
+				values, suffix = values[:10], " 等"
+			}
+			quoted := make([]string, len(values))
+			for i, e := range values {
+				quoted[i] = strconv.Quote(e)
+			}
+			return fmt.Sprintf("值 %q 不是允许的取值之一（%s%s）", value, strings.Join(quoted, "、"), suffix)
+		}
+	}
+
+	if st.variety == xsdAtomic {
+		bounds := []struct {
+			limit *string
+			fails func(int) bool
+			text  string
+		}{
+			{f.minInclusive, func(c int) bool { return c < 0 }, "不能小于"},
+			{f.minExclusive, func(c int) bool { return c <= 0 }, "必须大于"},
+			{f.maxInclusive, func(c int) bool { return c > 0 }, "不能大于"},
+			{f.maxExclusive, func(c int) bool { return c >= 0 }, "必须小于"},
+		}
+		for _, b := range bounds {
+			if b.limit == nil {
+				continue
+			}
+			if c, ok := compareXSDValues(primitive, value, strings.TrimSpace(*b.limit)); ok && b.fails(c) {
+				return fmt.Sprintf("值 %s %s %s", value, b.text, strings.TrimSpace(*b.limit))
+			}
+		}
+	}
+
+	if primitive == "decimal" && (f.totalDigits != nil || f.fractionDigits != nil) {
+		total, fraction := decimalDigits(value)
+		if f.totalDigits != nil && total > *f.totalDigits {
+			return fmt.Sprintf("值 %s 的总位数为 %d，不能超过 %d", value, total, *f.totalDigits)
+		}
+		if f.fractionDigits != nil && fraction > *f.fractionDigits {
+			return fmt.Sprintf("值 %s 的小数位数为 %d，不能超过 %d", value, fraction, *f.fractionDigits)
+		}
+	}
+	return ""
+}
+
+// xsdLength 按类型计算 length 约束面所度量的长度
+func xsdLength(st *xsdSimpleType, primitive, value string) (int, bool) {
+	switch {
+	case st.variety == xsdList:
+		return len(strings.Fields(value)), true
+	case st.variety == xsdUnion:
+		return 0, false
+	}
+	switch primitive {
+	case "hexBinary":
+		return len(value) / 2, true
+	case "base64Binary":
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+		return len(data), err == nil
+	case "QName", "NOTATION":
+		return 0, false
+	}
+	return utf8.RuneCountInString(value), true
+}
+
+// decimalDigits 返回十进制数的有效总位数和小数位数
+func decimalDigits(value string) (int, int) {
+	value = strings.TrimLeft(value, "+-")
+	intPart, fracPart, _ := strings.Cut(value, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	fracPart = strings.TrimRight(fracPart, "0")
+	total := len(intPart) + len(fracPart)
+	if total == 0 {
+		total = 1
+	}
+	return total, len(fracPart)
+}
+
+// compareXSDValues 按原始类型比较两个值，类型不支持比较或值无法解析时 ok 为 false
+func compareXSDValues(primitive, a, b string) (int, bool) {
+	switch primitive {
+	case "decimal":
+		x, okX := parseXSDDecimal(a)
+		y, okY := parseXSDDecimal(b)
+		if !okX || !okY {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case "float", "double":
+		x, errX := strconv.ParseFloat(a, 64)
+		y, errY := strconv.ParseFloat(b, 64)
+		if errX != nil || errY != nil || x != x || y != y {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case "dateTime", "date", "time":
+		x, okX := parseXSDTime(primitive, a)
+		y, okY := parseXSDTime(primitive, b)
+		if !okX || !okY {
+			return 0, false
+		}
+		return x.Compare(y), true
+	}
+	return 0, false
+}
+
+func parseXSDDecimal(s string) (*big.Rat, bool) {
+	s = strings.TrimPrefix(s, "+")
+	s = strings.TrimSuffix(s, ".")
+	if strings.HasPrefix(s, ".") {
+		s = "0" + s
+	} else if strings.HasPrefix(s, "-.") {
+		s = "-0" + s[1:]
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// parseXSDTime 解析日期时间用于比较，没有时区的值按 UTC 处理
+func parseXSDTime(primitive, s string) (time.Time, bool) {
+	layouts := map[string]string{
+		"dateTime": "2006-01-02T15:04:05Z07:00",
+		"date":     "2006-01-02Z07:00",
+		"time":     "15:04:05Z07:00",
+	}
+	if !xsdZonePattern.MatchString(s) {
+		s += "Z"
+	}
+	t, err := time.Parse(layouts[primitive], s)
+	return t, err == nil
+}
+
+// Unicode 区块名到码点范围，RE2 不支持 \p{IsXxx}
+var xsdUnicodeBlocks = map[string]string{
+	"BasicLatin":           `\x{0}-\x{7F}`,
+	"Latin-1Supplement":    `\x{80}-\x{FF}`,
+	"LatinExtended-A":      `\x{100}-\x{17F}`,
+	"LatinExtended-B":      `\x{180}-\x{24F}`,
+	"Greek":                `\x{370}-\x{3FF}`,
+	"Cyrillic":             `\x{400}-\x{4FF}`,
+	"Hebrew":               `\x{590}-\x{5FF}`,
+	"Arabic":               `\x{600}-\x{6FF}`,
+	"GeneralPunctuation":   `\x{2000}-\x{206F}`,
+	"Hiragana":             `\x{3040}-\x{309F}`,
+	"Katakana":             `\x{30A0}-\x{30FF}`,
+	"CJKUnifiedIdeographs": `\x{4E00}-\x{9FFF}`,
+	"HangulSyllables":      `\x{AC00}-\x{D7AF}`,
+}
+
+// xsdClassEscapes XSD 多字符转义对应的字符类内容
+var xsdClassEscapes = map[rune]string{
+	'i': `\p{L}_:`,
+	'c': `\p{L}\p{N}\p{Mn}\p{Mc}._:\-\x{B7}`,
+	'd': `\p{Nd}`,
+	'w': `\p{L}\p{M}\p{N}\p{S}`,
+	'W': `\p{P}\p{Z}\p{C}`,
+	's': ` \t\n\r`,
+}
+
+// translateXSDPattern 将 XSD 正则表达式转换为 Go 正则表达式
+// XSD 中 ^ 和 $ 是普通字符，整个表达式隐式锚定
+func translateXSDPattern(pattern string) (string, error) {
+	var b strings.Builder
+	runes := []rune(pattern)
+	inClass := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			e := runes[i]
+			switch e {
+			case 'i', 'c', 'd', 'w', 'W', 's':
+				if inClass {
+					b.WriteString(xsdClassEscapes[e])
+				} else {
+					b.WriteString("[" + xsdClassEscapes[e] + "]")
+				}
+			case 'I', 'C', 'D', 'S':
+				if inClass {
+					return "", fmt.Errorf("不支持在字符类中使用 \\%c", e)
+				}
+				b.WriteString("[^" + xsdClassEscapes[e+'a'-'A'] + "]")
+			case 'p', 'P':
+				end := i + 1
+				for end < len(runes) && runes[end] != '}' {
+					end++
+				}
+				if i+1 >= len(runes) || runes[i+1] != '{' || end >= len(runes) {
+					return "", fmt.Errorf("\\%c 后缺少 {名称}", e)
+				}
+				name := string(runes[i+2 : end])
+				i = end
+				if block, ok := strings.CutPrefix(name, "Is"); ok {
+					ranges, known := xsdUnicodeBlocks[block]
+					if !known {
+						return "", fmt.Errorf("不支持的 Unicode 区块 %s", block)
+					}
+					switch {
+					case inClass && e == 'P':
+						return "", fmt.Errorf("不支持在字符类中使用 \\P{%s}", name)
+					case inClass:
+						b.WriteString(ranges)
+					case e == 'P':
+						b.WriteString("[^" + ranges + "]")
+					default:
+						b.WriteString("[" + ranges + "]")
+					}
+				} else {
+					b.WriteString(`\` + string(e) + "{" + name + "}")
+				}
+			default:
+				b.WriteString(`\` + string(e))
+			}
+		case r == '[' && inClass:
+			return "", fmt.Errorf("不支持字符类减法")
+		case r == '[':
+			inClass = true
+			b.WriteRune(r)
+			if i+1 < len(runes) && runes[i+1] == '^' {
+				b.WriteRune('^')
+				i++
+			}
+		case r == ']' && inClass:
+			inClass = false
+			b.WriteRune(r)
+		case !inClass && (r == '^' || r == '$'):
+			b.WriteString(`\` + string(r))
+		case inClass && r == '-' && i+1 < len(runes) && runes[i+1] == '[':
+			return "", fmt.Errorf("不支持字符类减法")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
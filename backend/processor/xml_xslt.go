@@ -0,0 +1,843 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+const (
+	xslNamespace = "http://www.w3.org/1999/XSL/Transform"
+
+	// xsltMaxDepth 模板嵌套调用的最大层数，避免递归的模板无限展开
+	xsltMaxDepth = 1000
+)
+
+// TransformXML 使用 XSLT 1.0 的子集转换 XML
+// 支持 template（match、name、mode、priority）、apply-templates、call-template、for-each、sort、value-of、
+// if、choose、copy、copy-of、text、element、attribute、comment、output（method 为 xml 或 text，indent）、
+// strip-space、字面结果元素和属性值模板；不支持变量与参数、key、import/include 和扩展函数，
+// 表达式中的 position() 和 last() 按 XPath 求值而不是按当前节点列表，输出不包含 XML 声明
+func (x *XMLProcessor) TransformXML(input string, stylesheet string) (result string, err error) {
+	doc, err := parseXML([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	sheetDoc, err := parseXML([]byte(stylesheet))
+	if err != nil {
+		return "", fmt.Errorf("样式表无效: %w", err)
+	}
+	t, err := newXSLTransformer(sheetDoc.root())
+	if err != nil {
+		return "", err
+	}
+	// xpath 包在函数参数类型错误等情况下会 panic
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = "", fmt.Errorf("XSLT 执行失败: %v", r)
+		}
+	}()
+
+	out := &xmlNode{kind: xmlDocument}
+	if err := t.applyTemplates([]*xmlNavigator{newXMLNavigator(doc)}, "", out); err != nil {
+		return "", err
+	}
+	if t.method == "text" {
+		return out.textContent(), nil
+	}
+	for _, child := range out.children {
+		declareResultNamespaces(child, map[string]string{})
+	}
+	w := &xmlWriter{selfClosing: XMLSelfClosingAlways}
+	if t.indent {
+		w.indent = "  "
+	}
+	w.writeDocument(out)
+	return w.b.String(), nil
+}
+
+// xsltRule 模板的一个匹配规则，match 中用 | 分隔的每个模式各是一条规则
+type xsltRule struct {
+	template *xmlNode
+	pattern  *xpath.Expr
+	priority float64
+	mode     string
+	order    int                  // 在样式表中的顺序，优先级相同时后出现的规则生效
+	nodes    map[xsltNodeKey]bool // 文档中匹配模式的节点，首次使用时计算
+}
+
+// xsltNodeKey 标识导航器所在的节点，attr 为属性下标
+type xsltNodeKey struct {
+	node *xmlNode
+	attr int
+}
+
+type xsltTransformer struct {
+	rules  []*xsltRule
+	named  map[string]*xmlNode
+	strip  []string // strip-space 列出的元素名，* 表示所有元素
+	method string
+	indent bool
+	exprs  map[*xmlAttr]*xpath.Expr
+	avts   map[*xmlAttr][]xsltAVTPart
+	depth  int
+}
+
+// xsltAVTPart 属性值模板的一段，expr 为空时是字面文本
+type xsltAVTPart struct {
+	text string
+	expr *xpath.Expr
+}
+
+func isXSL(n *xmlNode, local string) bool {
+	return n.kind == xmlElement && n.name.space == xslNamespace && (local == "" || n.name.local == local)
+}
+
+// newXSLTransformer 读取样式表的顶层声明
+func newXSLTransformer(sheet *xmlNode) (*xsltTransformer, error) {
+	if sheet == nil || !isXSL(sheet, "stylesheet") && !isXSL(sheet, "transform") {
+		return nil, fmt.Errorf("样式表的根元素必须是 xsl:stylesheet 或 xsl:transform")
+	}
+	t := &xsltTransformer{named: map[string]*xmlNode{}, method: "xml", exprs: map[*xmlAttr]*xpath.Expr{}, avts: map[*xmlAttr][]xsltAVTPart{}}
+	for _, child := range sheet.children {
+		if child.kind != xmlElement {
+			continue
+		}
+		if !isXSL(child, "") {
+			continue // 其他命名空间的顶层元素按规范忽略
+		}
+		switch child.name.local {
+		case "template":
+			if err := t.addTemplate(child); err != nil {
+				return nil, err
+			}
+		case "output":
+			if a := child.attr("", "method"); a != nil {
+				if a.value != "xml" && a.value != "text" {
+					return nil, fmt.Errorf("第 %d 行: 不支持的输出方式 %s", child.line, a.value)
+				}
+				t.method = a.value
+			}
+			if a := child.attr("", "indent"); a != nil {
+				t.indent = a.value == "yes"
+			}
+		case "strip-space":
+			if a := child.attr("", "elements"); a != nil {
+				t.strip = append(t.strip, strings.Fields(a.value)...)
+			}
+		case "preserve-space":
+		default:
+			return nil, fmt.Errorf("第 %d 行: 不支持的 XSLT 声明 xsl:%s", child.line, child.name.local)
+		}
+	}
+	return t, nil
+}
+
+func (t *xsltTransformer) addTemplate(el *xmlNode) error {
+	if a := el.attr("", "name"); a != nil {
+		t.named[a.value] = el
+	}
+	match := el.attr("", "match")
+	if match == nil {
+		if el.attr("", "name") == nil {
+			return fmt.Errorf("第 %d 行: xsl:template 缺少 match 或 name 属性", el.line)
+		}
+		return nil
+	}
+	mode := ""
+	if a := el.attr("", "mode"); a != nil {
+		mode = a.value
+	}
+	for _, pattern := range splitXSLTPattern(match.value) {
+		// 节点匹配模式，当且仅当它在以某个祖先为上下文对模式求值的结果中
+		expression := pattern
+		if !strings.HasPrefix(pattern, "/") {
+			expression = "//" + pattern
+		}
+		expr, err := xpath.CompileWithNS(expression, xpathNamespaces(el))
+		if err != nil {
+			return fmt.Errorf("第 %d 行: 模式 %s 语法错误: %v", el.line, pattern, err)
+		}
+		rule := &xsltRule{template: el, pattern: expr, priority: defaultXSLTPriority(pattern), mode: mode, order: len(t.rules)}
+		if a := el.attr("", "priority"); a != nil {
+			if rule.priority, err = strconv.ParseFloat(strings.TrimSpace(a.value), 64); err != nil {
+				return fmt.Errorf("第 %d 行: 无效的优先级 %s", el.line, a.value)
+			}
+		}
+		t.rules = append(t.rules, rule)
+	}
+	return nil
+}
+
+// splitXSLTPattern 按顶层的 | 拆分模式，忽略括号和字符串中的 |
+func splitXSLTPattern(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// defaultXSLTPriority 按 XSLT 1.0 第 5.5 节计算模式的默认优先级
+func defaultXSLTPriority(pattern string) float64 {
+	step := strings.TrimPrefix(strings.TrimPrefix(pattern, "child::"), "attribute::")
+	step = strings.TrimPrefix(step, "@")
+	switch {
+	case strings.ContainsAny(pattern, "/[") || pattern == "":
+		return 0.5
+	case step == "*" || step == "node()" || step == "text()" || step == "comment()" || step == "processing-instruction()":
+		return -0.5
+	case strings.HasSuffix(step, ":*"):
+		return -0.25
+	case isXMLName(step):
+		return 0
+	}
+	return 0.5
+}
+
+// xpathNamespaces 返回样式表元素作用域内的前缀映射，供编译其中的表达式；XPath 1.0 中无前缀的名称不属于任何命名空间
+func xpathNamespaces(el *xmlNode) map[string]string {
+	namespaces := inScopeNamespaces(el)
+	delete(namespaces, "")
+	return namespaces
+}
+
+// expr 编译指令属性中的表达式
+func (t *xsltTransformer) expr(el *xmlNode, name string) (*xpath.Expr, error) {
+	a := el.attr("", name)
+	if a == nil {
+		return nil, fmt.Errorf("第 %d 行: xsl:%s 缺少 %s 属性", el.line, el.name.local, name)
+	}
+	if expr, ok := t.exprs[a]; ok {
+		return expr, nil
+	}
+	expr, err := xpath.CompileWithNS(a.value, xpathNamespaces(el))
+	if err != nil {
+		return nil, fmt.Errorf("第 %d 行: 表达式 %s 语法错误: %v", a.line, a.value, err)
+	}
+	t.exprs[a] = expr
+	return expr, nil
+}
+
+// avt 对属性值模板求值，{} 中为表达式，{{ 和 }} 表示字面的大括号
+func (t *xsltTransformer) avt(el *xmlNode, a *xmlAttr, ctx *xmlNavigator) (string, error) {
+	parts, ok := t.avts[a]
+	if !ok {
+		s := a.value
+		var text strings.Builder
+		for i := 0; i < len(s); i++ {
+			switch {
+			case strings.HasPrefix(s[i:], "{{") || strings.HasPrefix(s[i:], "}}"):
+				text.WriteByte(s[i])
+				i++
+			case s[i] == '{':
+				end := -1
+				var quote byte
+				for k := i + 1; k < len(s) && end < 0; k++ {
+					switch {
+					case quote != 0:
+						if s[k] == quote {
+							quote = 0
+						}
+					case s[k] == '"' || s[k] == '\'':
+						quote = s[k]
+					case s[k] == '}':
+						end = k
+					}
+				}
+				if end < 0 {
+					return "", fmt.Errorf("第 %d 行: 属性值模板 %s 缺少 }", a.line, s)
+				}
+				expr, err := xpath.CompileWithNS(s[i+1:end], xpathNamespaces(el))
+				if err != nil {
+					return "", fmt.Errorf("第 %d 行: 表达式 %s 语法错误: %v", a.line, s[i+1:end], err)
+				}
+				parts = append(parts, xsltAVTPart{text: text.String()}, xsltAVTPart{expr: expr})
+				text.Reset()
+				i = end
+			case s[i] == '}':
+				return "", fmt.Errorf("第 %d 行: 属性值模板 %s 中的 } 需要写成 }}", a.line, s)
+			default:
+				text.WriteByte(s[i])
+			}
+		}
+		parts = append(parts, xsltAVTPart{text: text.String()})
+		t.avts[a] = parts
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		if part.expr == nil {
+			b.WriteString(part.text)
+			continue
+		}
+		b.WriteString(xsltString(part.expr.Evaluate(ctx.Copy())))
+	}
+	return b.String(), nil
+}
+
+// selectNodes 求值节点集表达式，结果按文档顺序排列
+func (t *xsltTransformer) selectNodes(el *xmlNode, ctx *xmlNavigator) ([]*xmlNavigator, error) {
+	expr, err := t.expr(el, "select")
+	if err != nil {
+		return nil, err
+	}
+	iter, ok := expr.Evaluate(ctx.Copy()).(*xpath.NodeIterator)
+	if !ok {
+		return nil, fmt.Errorf("第 %d 行: xsl:%s 的 select 不是节点集", el.line, el.name.local)
+	}
+	var navs []*xmlNavigator
+	for iter.MoveNext() {
+		if nav := iter.Current().Copy().(*xmlNavigator); !t.stripped(nav) {
+			navs = append(navs, nav)
+		}
+	}
+	sort.SliceStable(navs, func(i, k int) bool {
+		return navs[i].before(navs[k])
+	})
+	return navs, nil
+}
+
+// children 返回上下文节点的子节点
+func (t *xsltTransformer) children(ctx *xmlNavigator) []*xmlNavigator {
+	var navs []*xmlNavigator
+	nav := ctx.Copy().(*xmlNavigator)
+	for ok := nav.MoveToChild(); ok; ok = nav.MoveToNext() {
+		if !t.stripped(nav) {
+			navs = append(navs, nav.Copy().(*xmlNavigator))
+		}
+	}
+	return navs
+}
+
+// stripped 空白文本是否被 strip-space 去除
+func (t *xsltTransformer) stripped(nav *xmlNavigator) bool {
+	if nav.attr >= 0 || !isXPathText(nav.curr) || !isXMLWhitespace(nav.curr.runText()) {
+		return false
+	}
+	parent := nav.curr.parent
+	for _, name := range t.strip {
+		if name == "*" || name == parent.name.qualified() {
+			return !parent.preserveSpace()
+		}
+	}
+	return false
+}
+
+// sortNodes 按 instruction 下的 xsl:sort 排序节点
+func (t *xsltTransformer) sortNodes(instruction *xmlNode, navs []*xmlNavigator) error {
+	type sortKey struct {
+		expr       *xpath.Expr
+		descending bool
+		numeric    bool
+	}
+	var keys []sortKey
+	for _, child := range instruction.children {
+		if !isXSL(child, "sort") {
+			continue
+		}
+		key := sortKey{}
+		if child.attr("", "select") == nil {
+			key.expr, _ = xpath.Compile(".")
+		} else {
+			var err error
+			if key.expr, err = t.expr(child, "select"); err != nil {
+				return err
+			}
+		}
+		if a := child.attr("", "order"); a != nil {
+			key.descending = a.value == "descending"
+		}
+		if a := child.attr("", "data-type"); a != nil {
+			key.numeric = a.value == "number"
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	values := make([][]string, len(navs))
+	for i, nav := range navs {
+		for _, key := range keys {
+			values[i] = append(values[i], xsltString(key.expr.Evaluate(nav.Copy())))
+		}
+	}
+	index := make([]int, len(navs))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, k int) bool {
+		a, b := values[index[i]], values[index[k]]
+		for j, key := range keys {
+			c := strings.Compare(a[j], b[j])
+			if key.numeric {
+				c = compareXSLTNumbers(a[j], b[j])
+			}
+			if key.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	sorted := make([]*xmlNavigator, len(navs))
+	for i, k := range index {
+		sorted[i] = navs[k]
+	}
+	copy(navs, sorted)
+	return nil
+}
+
+// compareXSLTNumbers 按数值比较排序键，非数字排在最前
+func compareXSLTNumbers(a, b string) int {
+	x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	switch {
+	case errA != nil || errB != nil:
+		return boolToInt(errB != nil) - boolToInt(errA != nil)
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// findRule 返回匹配节点的规则，没有时返回 nil
+func (t *xsltTransformer) findRule(nav *xmlNavigator, mode string) *xsltRule {
+	key := xsltNodeKey{nav.curr, nav.attr}
+	var best *xsltRule
+	for _, rule := range t.rules {
+		if rule.mode != mode || (best != nil && rule.priority < best.priority) {
+			continue
+		}
+		if rule.nodes == nil {
+			rule.nodes = map[xsltNodeKey]bool{}
+			iter := rule.pattern.Select(newXMLNavigator(nav.root))
+			for iter.MoveNext() {
+				n := iter.Current().(*xmlNavigator)
+				rule.nodes[xsltNodeKey{n.curr, n.attr}] = true
+			}
+		}
+		if rule.nodes[key] {
+			best = rule
+		}
+	}
+	return best
+}
+
+// applyTemplates 对每个节点应用匹配的模板，没有匹配时使用内置规则
+func (t *xsltTransformer) applyTemplates(navs []*xmlNavigator, mode string, out *xmlNode) error {
+	for _, nav := range navs {
+		if rule := t.findRule(nav, mode); rule != nil {
+			if err := t.instantiate(rule.template, nav, out); err != nil {
+				return err
+			}
+			continue
+		}
+		switch nav.NodeType() {
+		case xpath.RootNode, xpath.ElementNode:
+			if err := t.applyTemplates(t.children(nav), mode, out); err != nil {
+				return err
+			}
+		case xpath.TextNode, xpath.AttributeNode:
+			appendResultText(out, nav.Value())
+		}
+	}
+	return nil
+}
+
+// instantiate 以 ctx 为当前节点实例化模板或指令的内容，结果追加到 out
+func (t *xsltTransformer) instantiate(body *xmlNode, ctx *xmlNavigator, out *xmlNode) error {
+	t.depth++
+	defer func() { t.depth-- }()
+	if t.depth > xsltMaxDepth {
+		return fmt.Errorf("第 %d 行: 模板嵌套超过 %d 层", body.line, xsltMaxDepth)
+	}
+	for _, child := range body.children {
+		switch child.kind {
+		case xmlText, xmlCData:
+			// 样式表中只有空白的文本不输出
+			if !child.isWhitespace() {
+				appendResultText(out, child.text)
+			}
+		case xmlElement:
+			var err error
+			if isXSL(child, "") {
+				err = t.instruction(child, ctx, out)
+			} else {
+				err = t.literal(child, ctx, out)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// instruction 执行一条 XSLT 指令
+func (t *xsltTransformer) instruction(el *xmlNode, ctx *xmlNavigator, out *xmlNode) error {
+	switch el.name.local {
+	case "apply-templates":
+		navs := t.children(ctx)
+		if el.attr("", "select") != nil {
+			var err error
+			if navs, err = t.selectNodes(el, ctx); err != nil {
+				return err
+			}
+		}
+		if err := t.sortNodes(el, navs); err != nil {
+			return err
+		}
+		mode := ""
+		if a := el.attr("", "mode"); a != nil {
+			mode = a.value
+		}
+		return t.applyTemplates(navs, mode, out)
+	case "call-template":
+		a := el.attr("", "name")
+		if a == nil {
+			return fmt.Errorf("第 %d 行: xsl:call-template 缺少 name 属性", el.line)
+		}
+		template, ok := t.named[a.value]
+		if !ok {
+			return fmt.Errorf("第 %d 行: 模板 %s 不存在", el.line, a.value)
+		}
+		return t.instantiate(template, ctx, out)
+	case "for-each":
+		navs, err := t.selectNodes(el, ctx)
+		if err != nil {
+			return err
+		}
+		if err := t.sortNodes(el, navs); err != nil {
+			return err
+		}
+		for _, nav := range navs {
+			if err := t.instantiate(el, nav, out); err != nil {
+				return err
+			}
+		}
+	case "sort":
+		// 由 for-each 和 apply-templates 处理
+	case "value-of":
+		expr, err := t.expr(el, "select")
+		if err != nil {
+			return err
+		}
+		appendResultText(out, xsltString(expr.Evaluate(ctx.Copy())))
+	case "if":
+		expr, err := t.expr(el, "test")
+		if err != nil {
+			return err
+		}
+		if xsltBoolean(expr.Evaluate(ctx.Copy())) {
+			return t.instantiate(el, ctx, out)
+		}
+	case "choose":
+		for _, branch := range el.children {
+			if isXSL(branch, "otherwise") {
+				return t.instantiate(branch, ctx, out)
+			}
+			if !isXSL(branch, "when") {
+				continue
+			}
+			expr, err := t.expr(branch, "test")
+			if err != nil {
+				return err
+			}
+			if xsltBoolean(expr.Evaluate(ctx.Copy())) {
+				return t.instantiate(branch, ctx, out)
+			}
+		}
+	case "copy":
+		switch ctx.NodeType() {
+		case xpath.RootNode:
+			return t.instantiate(el, ctx, out)
+		case xpath.ElementNode:
+			copied := &xmlNode{kind: xmlElement, name: ctx.curr.name}
+			appendResultNode(out, copied)
+			return t.instantiate(el, ctx, copied)
+		case xpath.AttributeNode:
+			return setResultAttr(out, ctx.curr.attrs[ctx.attr].name, ctx.Value(), el)
+		case xpath.TextNode:
+			appendResultText(out, ctx.Value())
+		case xpath.CommentNode:
+			appendResultNode(out, &xmlNode{kind: xmlComment, text: ctx.Value()})
+		}
+	case "copy-of":
+		expr, err := t.expr(el, "select")
+		if err != nil {
+			return err
+		}
+		iter, ok := expr.Evaluate(ctx.Copy()).(*xpath.NodeIterator)
+		if !ok {
+			appendResultText(out, xsltString(expr.Evaluate(ctx.Copy())))
+			return nil
+		}
+		var navs []*xmlNavigator
+		for iter.MoveNext() {
+			navs = append(navs, iter.Current().Copy().(*xmlNavigator))
+		}
+		sort.SliceStable(navs, func(i, k int) bool {
+			return navs[i].before(navs[k])
+		})
+		for _, nav := range navs {
+			switch nav.NodeType() {
+			case xpath.RootNode:
+				for _, child := range nav.curr.children {
+					if navigable(child) {
+						appendResultNode(out, cloneXMLNode(child))
+					}
+				}
+			case xpath.AttributeNode:
+				if err := setResultAttr(out, nav.curr.attrs[nav.attr].name, nav.Value(), el); err != nil {
+					return err
+				}
+			case xpath.TextNode:
+				appendResultText(out, nav.Value())
+			default:
+				appendResultNode(out, cloneXMLNode(nav.curr))
+			}
+		}
+	case "text":
+		appendResultText(out, el.textContent())
+	case "element":
+		name, err := t.resultName(el, ctx)
+		if err != nil {
+			return err
+		}
+		created := &xmlNode{kind: xmlElement, name: name}
+		appendResultNode(out, created)
+		return t.instantiate(el, ctx, created)
+	case "attribute":
+		name, err := t.resultName(el, ctx)
+		if err != nil {
+			return err
+		}
+		value := &xmlNode{kind: xmlDocument}
+		if err := t.instantiate(el, ctx, value); err != nil {
+			return err
+		}
+		return setResultAttr(out, name, value.textContent(), el)
+	case "comment":
+		value := &xmlNode{kind: xmlDocument}
+		if err := t.instantiate(el, ctx, value); err != nil {
+			return err
+		}
+		appendResultNode(out, &xmlNode{kind: xmlComment, text: value.textContent()})
+	default:
+		return fmt.Errorf("第 %d 行: 不支持的 XSLT 指令 xsl:%s", el.line, el.name.local)
+	}
+	return nil
+}
+
+// resultName 计算 xsl:element 和 xsl:attribute 的 name 与 namespace 属性
+func (t *xsltTransformer) resultName(el *xmlNode, ctx *xmlNavigator) (xmlName, error) {
+	a := el.attr("", "name")
+	if a == nil {
+		return xmlName{}, fmt.Errorf("第 %d 行: xsl:%s 缺少 name 属性", el.line, el.name.local)
+	}
+	qname, err := t.avt(el, a, ctx)
+	if err != nil {
+		return xmlName{}, err
+	}
+	if !isXMLName(qname) {
+		return xmlName{}, fmt.Errorf("第 %d 行: 无效的名称 %q", el.line, qname)
+	}
+	name := xmlName{local: qname}
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		name.prefix, name.local = qname[:i], qname[i+1:]
+	}
+	if ns := el.attr("", "namespace"); ns != nil {
+		name.space, err = t.avt(el, ns, ctx)
+		return name, err
+	}
+	// 无前缀的属性不属于任何命名空间
+	if name.prefix == "" && el.name.local == "attribute" {
+		return name, nil
+	}
+	space, ok := el.lookupNamespace(name.prefix)
+	if !ok {
+		return xmlName{}, fmt.Errorf("第 %d 行: 未声明的命名空间前缀 %s", el.line, name.prefix)
+	}
+	name.space = space
+	return name, nil
+}
+
+// literal 复制字面结果元素，XSLT 命名空间的声明和属性不输出
+func (t *xsltTransformer) literal(el *xmlNode, ctx *xmlNavigator, out *xmlNode) error {
+	copied := &xmlNode{kind: xmlElement, name: el.name}
+	for _, a := range el.attrs {
+		switch {
+		case a.isNamespaceDecl():
+			if a.value != xslNamespace {
+				copied.attrs = append(copied.attrs, newXMLAttr(a.name, a.value))
+			}
+		case a.name.space != xslNamespace:
+			value, err := t.avt(el, a, ctx)
+			if err != nil {
+				return err
+			}
+			copied.attrs = append(copied.attrs, newXMLAttr(a.name, value))
+		}
+	}
+	appendResultNode(out, copied)
+	return t.instantiate(el, ctx, copied)
+}
+
+// xsltString 按 XPath 的 string() 规则转换表达式的结果，节点集取文档顺序中第一个节点的值
+func xsltString(v interface{}) string {
+	switch v := v.(type) {
+	case *xpath.NodeIterator:
+		var first *xmlNavigator
+		for v.MoveNext() {
+			if nav := v.Current().(*xmlNavigator); first == nil || nav.before(first) {
+				first = nav.Copy().(*xmlNavigator)
+			}
+		}
+		if first == nil {
+			return ""
+		}
+		return first.Value()
+	case float64:
+		return formatXPathNumber(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// xsltBoolean 按 XPath 的 boolean() 规则转换表达式的结果
+func xsltBoolean(v interface{}) bool {
+	switch v := v.(type) {
+	case *xpath.NodeIterator:
+		return v.MoveNext()
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case bool:
+		return v
+	}
+	return false
+}
+
+// appendResultNode 将节点追加到结果树
+func appendResultNode(out, n *xmlNode) {
+	n.parent = out
+	out.children = append(out.children, n)
+}
+
+// appendResultText 向结果树追加文本，与前面的文本合并
+func appendResultText(out *xmlNode, s string) {
+	if s == "" {
+		return
+	}
+	if last := len(out.children) - 1; last >= 0 && out.children[last].kind == xmlText {
+		n := out.children[last]
+		n.text += s
+		n.raw = escapeXMLText(n.text)
+		return
+	}
+	appendResultNode(out, &xmlNode{kind: xmlText, text: s, raw: escapeXMLText(s)})
+}
+
+// setResultAttr 设置结果元素的属性，属性必须在元素的子节点之前生成
+func setResultAttr(out *xmlNode, name xmlName, value string, el *xmlNode) error {
+	if out.kind != xmlElement {
+		return nil // 规范允许忽略不在元素中的属性
+	}
+	if len(out.children) > 0 {
+		return fmt.Errorf("第 %d 行: 属性 %s 必须在元素 <%s> 的子节点之前生成", el.line, name.qualified(), out.name.qualified())
+	}
+	attr := newXMLAttr(name, value)
+	for i, a := range out.attrs {
+		if !a.isNamespaceDecl() && a.name.space == name.space && a.name.local == name.local {
+			out.attrs[i] = attr
+			return nil
+		}
+	}
+	out.attrs = append(out.attrs, attr)
+	return nil
+}
+
+// cloneXMLNode 深拷贝源文档中的节点
+func cloneXMLNode(n *xmlNode) *xmlNode {
+	c := &xmlNode{kind: n.kind, name: n.name, text: n.text, raw: n.raw, selfClosing: n.selfClosing}
+	for _, a := range n.attrs {
+		copied := *a
+		c.attrs = append(c.attrs, &copied)
+	}
+	for _, child := range n.children {
+		if navigable(child) {
+			appendResultNode(c, cloneXMLNode(child))
+		}
+	}
+	return c
+}
+
+// declareResultNamespaces 为结果树中用到但未在作用域内声明的前缀补上命名空间声明
+func declareResultNamespaces(el *xmlNode, scope map[string]string) {
+	if el.kind != xmlElement {
+		return
+	}
+	local := make(map[string]string, len(scope))
+	for prefix, space := range scope {
+		local[prefix] = space
+	}
+	declareNamespaces(el, local)
+	var decls []*xmlAttr
+	need := func(prefix, space string) {
+		if prefix == "xml" || local[prefix] == space {
+			return
+		}
+		local[prefix] = space
+		if prefix == "" {
+			decls = append(decls, newXMLAttr(xmlName{local: "xmlns", space: xmlnsNamespace}, space))
+		} else {
+			decls = append(decls, newXMLAttr(xmlName{prefix: "xmlns", local: prefix, space: xmlnsNamespace}, space))
+		}
+	}
+	need(el.name.prefix, el.name.space)
+	for _, a := range el.attrs {
+		if !a.isNamespaceDecl() && a.name.prefix != "" {
+			need(a.name.prefix, a.name.space)
+		}
+	}
+	el.attrs = append(decls, el.attrs...)
+	for _, child := range el.children {
+		declareResultNamespaces(child, local)
+	}
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+const xsltOrders = `<orders xmlns:p="urn:products">
+  <order id="2" total="15.5"><p:item>pen</p:item><p:item>ink</p:item></order>
+  <order id="1" total="120"><p:item>book</p:item></order>
+  <note>keep &amp; escape</note>
+</orders>`
+
+// wrapXSLT 生成包含给定模板的样式表
+func wrapXSLT(body string) string {
+	return `<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform" xmlns:p="urn:products">` + body + `</xsl:stylesheet>`
+}
+
+func TestTransformXML(t *testing.T) {
+	tests := []struct {
+		name       string
+		stylesheet string
+		want       string
+	}{
+		{
+			"built-in templates copy text",
+			wrapXSLT(`<xsl:strip-space elements="*"/>`),
+			"peninkbookkeep &amp; escape",
+		},
+		{
+			"for-each with sort and attribute value templates",
+			wrapXSLT(`<xsl:template match="/"><list><xsl:for-each select="orders/order"><xsl:sort select="@id" data-type="number"/><o n="{@id}" items="{count(p:item)}"/></xsl:for-each></list></xsl:template>`),
+			`<list><o n="1" items="1"/><o n="2" items="2"/></list>`,
+		},
+		{
+			"apply-templates with modes and priorities",
+			wrapXSLT(`<xsl:template match="/"><r><xsl:apply-templates select="//p:item" mode="m"/></r></xsl:template>
+				<xsl:template match="p:item" mode="m"><i><xsl:value-of select="."/></i></xsl:template>
+				<xsl:template match="p:item[. = 'ink']" mode="m"><special/></xsl:template>
+				<xsl:template match="p:item"><wrong/></xsl:template>`),
+			`<r><i>pen</i><special/><i>book</i></r>`,
+		},
+		{
+			"choose, if and call-template",
+			wrapXSLT(`<xsl:template match="/"><r><xsl:apply-templates select="orders/order"/></r></xsl:template>
+				<xsl:template match="order"><xsl:choose><xsl:when test="@total &gt; 100"><big/></xsl:when><xsl:otherwise><xsl:call-template name="small"/></xsl:otherwise></xsl:choose><xsl:if test="count(p:item) = 2"><two/></xsl:if></xsl:template>
+				<xsl:template name="small"><small id="{@id}"/></xsl:template>`),
+			`<r><small id="2"/><two/><big/></r>`,
+		},
+		{
+			"identity transform keeps namespaces",
+			wrapXSLT(`<xsl:template match="@*|node()"><xsl:copy><xsl:apply-templates select="@*|node()"/></xsl:copy></xsl:template>
+				<xsl:template match="order[@id = '2']"/>`),
+			`<orders><order id="1" total="120"><p:item xmlns:p="urn:products">book</p:item></order><note>keep &amp; escape</note></orders>`,
+		},
+		{
+			"element, attribute, text and copy-of",
+			wrapXSLT(`<xsl:template match="/"><xsl:element name="out"><xsl:attribute name="n"><xsl:value-of select="count(//order)"/></xsl:attribute><xsl:text>a &lt; b</xsl:text><xsl:copy-of select="//order[@id = '1']"/></xsl:element></xsl:template>`),
+			`<out n="2">a &lt; b<order id="1" total="120"><p:item xmlns:p="urn:products">book</p:item></order></out>`,
+		},
+		{
+			"indented output",
+			wrapXSLT(`<xsl:output indent="yes"/><xsl:template match="/"><r><xsl:for-each select="//order"><o><xsl:value-of select="@id"/></o></xsl:for-each></r></xsl:template>`),
+			"<r>\n  <o>2</o>\n  <o>1</o>\n</r>",
+		},
+		{
+			"text output",
+			wrapXSLT(`<xsl:output method="text"/><xsl:template match="/"><xsl:for-each select="//order"><xsl:sort select="@total" data-type="number" order="descending"/><xsl:value-of select="@id"/>,</xsl:for-each><xsl:value-of select="//note"/></xsl:template>`),
+			"1,2,keep & escape",
+		},
+	}
+	x := NewXMLProcessor()
+	for _, tt := range tests {
+		got, err := x.TransformXML(xsltOrders, tt.stylesheet)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTransformXMLErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		stylesheet string
+		want       string
+	}{
+		{"not a stylesheet", `<a/>`, "xsl:stylesheet"},
+		{"unsupported declaration", wrapXSLT(`<xsl:key name="k" match="a" use="."/>`), "xsl:key"},
+		{"unsupported instruction", wrapXSLT(`<xsl:template match="/"><xsl:number/></xsl:template>`), "xsl:number"},
+		{"missing named template", wrapXSLT(`<xsl:template match="/"><xsl:call-template name="x"/></xsl:template>`), "模板 x 不存在"},
+		{"infinite recursion", wrapXSLT(`<xsl:template match="/"><xsl:apply-templates select="."/></xsl:template>`), "嵌套超过"},
+		{"attribute after children", wrapXSLT(`<xsl:template match="/"><a><b/><xsl:attribute name="x">1</xsl:attribute></a></xsl:template>`), "子节点之前"},
+	}
+	for _, tt := range tests {
+		_, err := NewXMLProcessor().TransformXML(xsltOrders, tt.stylesheet)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -76,6 +76,33 @@ export namespace file {
 
 }
 
+export namespace processor {
+	
+	export class GoStructOptions {
+	    typeName: string;
+	    packageName: string;
+	    flatten: boolean;
+	    allOmitempty: boolean;
+	    gofmt: boolean;
+	    arrayAsSamples: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GoStructOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.typeName = source["typeName"];
+	        this.packageName = source["packageName"];
+	        this.flatten = source["flatten"];
+	        this.allOmitempty = source["allOmitempty"];
+	        this.gofmt = source["gofmt"];
+	        this.arrayAsSamples = source["arrayAsSamples"];
+	    }
+	}
+
+}
+
 export namespace updater {
 	
 	export class UpdateInfo {
@@ -1,6 +1,9 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {processor} from '../models';
 
 export function CompressJson(arg1:string,arg2:boolean):Promise<string>;
 
 export function FormatJson(arg1:string,arg2:boolean):Promise<string>;
+
+export function JsonToGo(arg1:Array<string>,arg2:processor.GoStructOptions):Promise<string>;
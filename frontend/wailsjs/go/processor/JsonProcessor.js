@@ -9,3 +9,7 @@ export function CompressJson(arg1, arg2) {
 export function FormatJson(arg1, arg2) {
   return window['go']['processor']['JsonProcessor']['FormatJson'](arg1, arg2);
 }
+
+export function JsonToGo(arg1, arg2) {
+  return window['go']['processor']['JsonProcessor']['JsonToGo'](arg1, arg2);
+}
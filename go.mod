@@ -3,8 +3,12 @@ module go-tools
 go 1.24
 
 require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/antchfx/xpath v1.3.5
 	github.com/gopherjs/gopherjs v1.17.2
 	github.com/wailsapp/wails/v2 v2.9.3
+	golang.org/x/net v0.35.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -31,7 +35,6 @@ require (
 	github.com/wailsapp/go-webview2 v1.0.19 // indirect
 	github.com/wailsapp/mimetype v1.4.1 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
-	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 )
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"runtime"
 
 	"github.com/wailsapp/wails/v2"
@@ -29,6 +30,9 @@ func main() {
 	// Create processor instances
 	jsonProcessor := processor.NewJsonProcessor()
 	xmlProcessor := processor.NewXMLProcessor()
+	htmlProcessor := processor.NewHTMLProcessor()
+	convertProcessor := processor.NewConvertProcessor()
+	csvProcessor := processor.NewCSVProcessor()
 	charlesGenerator := processor.NewCharlesGenerator()
 
 	// 检测操作系统
@@ -50,8 +54,19 @@ func main() {
 			application,
 			jsonProcessor,
 			xmlProcessor,
+			htmlProcessor,
+			convertProcessor,
+			csvProcessor,
 			charlesGenerator,
 		},
+		// 语法错误以结构化对象返回前端，便于编辑器标记出错位置
+		ErrorFormatter: func(err error) any {
+			var syntaxErr *processor.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return syntaxErr
+			}
+			return err.Error()
+		},
 		Frameless: !isMacOS, // macOS使用有边框窗口，其他平台无边框
 		Mac: &mac.Options{
 			TitleBar:             mac.TitleBarHiddenInset(),